@@ -21,6 +21,7 @@ import (
 	_ "github.com/ncw/rclone/backend/pcloud"
 	_ "github.com/ncw/rclone/backend/qingstor"
 	_ "github.com/ncw/rclone/backend/s3"
+	_ "github.com/ncw/rclone/backend/seafile"
 	_ "github.com/ncw/rclone/backend/sftp"
 	_ "github.com/ncw/rclone/backend/swift"
 	_ "github.com/ncw/rclone/backend/webdav"
@@ -171,7 +171,7 @@ func (f *Fs) shouldRetry(resp *http.Response, err error) (bool, error) {
 			return true, err
 		}
 	}
-	return fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), err
+	return fserrors.RetryAfterHTTP(fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
 }
 
 // If query parameters contain X-Amz-Algorithm remove Authorization header
@@ -211,7 +211,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:         name,
 		root:         root,
 		c:            c,
-		pacer:        pacer.New().SetMinSleep(minSleep).SetPacer(pacer.AmazonCloudDrivePacer),
+		pacer:        pacer.New().SetMinSleep(minSleep).SetPacer(pacer.AmazonCloudDrivePacer).SetName(name),
 		noAuthClient: fshttp.NewClient(fs.Config),
 	}
 	f.features = (&fs.Features{
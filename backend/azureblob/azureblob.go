@@ -3,15 +3,21 @@ package azureblob
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,6 +46,7 @@ const (
 	timeFormatIn  = time.RFC3339
 	timeFormatOut = "2006-01-02T15:04:05.000000000Z07:00"
 	maxTotalParts = 50000 // in multipart upload
+	tierHeader    = "x-ms-access-tier"
 	// maxUncommittedSize = 9 << 30 // can't upload bigger than this
 )
 
@@ -63,6 +70,10 @@ func init() {
 		}, {
 			Name: "key",
 			Help: "Storage Account Key",
+		}, {
+			Name:     "sas_url",
+			Help:     "SAS URL for container level access only\n(leave blank if using account/key or connection string)",
+			Optional: true,
 		}, {
 			Name: "endpoint",
 			Help: "Endpoint for the service - leave blank normally.",
@@ -81,6 +92,8 @@ type Fs struct {
 	account          string       // account name
 	key              []byte       // auth key
 	endpoint         string       // name of the starting api endpoint
+	sasURL           *url.URL     // SAS URL if authenticating with one
+	srv              *http.Client // client for the calls the SDK doesn't support
 	bc               *storage.BlobStorageClient
 	cc               *storage.Container
 	container        string                // the container we are working on
@@ -100,6 +113,7 @@ type Object struct {
 	size     int64             // Size of the object
 	mimeType string            // Content-Type of the object
 	meta     map[string]string // blob metadata
+	tier     string            // access tier of the blob if known
 }
 
 // ------------------------------------------------------------
@@ -157,6 +171,9 @@ var retryErrorCodes = []int{
 
 // shouldRetry returns a boolean as to whether this resp and err
 // deserve to be retried.  It returns the err as a convenience
+//
+// The SDK doesn't pass on the response headers in its errors so any
+// Retry-After header can't be honoured.
 func (f *Fs) shouldRetry(err error) (bool, error) {
 	// FIXME interpret special errors - more to do here
 	if storageErr, ok := err.(storage.AzureStorageServiceError); ok {
@@ -183,26 +200,65 @@ func NewFs(name, root string) (fs.Fs, error) {
 		return nil, err
 	}
 	account := config.FileGet(name, "account")
-	if account == "" {
-		return nil, errors.New("account not found")
-	}
 	key := config.FileGet(name, "key")
-	if key == "" {
-		return nil, errors.New("key not found")
-	}
-	keyBytes, err := base64.StdEncoding.DecodeString(key)
-	if err != nil {
-		return nil, errors.Errorf("malformed storage account key: %v", err)
-	}
-
+	sasURL := config.FileGet(name, "sas_url")
 	endpoint := config.FileGet(name, "endpoint", storage.DefaultBaseURL)
 
-	client, err := storage.NewClient(account, key, endpoint, apiVersion, true)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to make azure storage client")
+	var (
+		keyBytes []byte
+		sasu     *url.URL
+		bc       *storage.BlobStorageClient
+		cc       *storage.Container
+	)
+	switch {
+	case sasURL != "":
+		u, err := url.Parse(sasURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse SAS URL")
+		}
+		sasu = u
+		if strings.Trim(u.Path, "/") == "" {
+			// An account level SAS URL which can access all the containers
+			client, err := storage.NewAccountSASClientFromEndpointToken(u.Scheme+"://"+u.Host, u.RawQuery)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to make azure storage client from SAS URL")
+			}
+			client.HTTPClient = fshttp.NewClient(fs.Config)
+			blobService := client.GetBlobService()
+			bc = &blobService
+			cc = bc.GetContainerReference(container)
+		} else {
+			// A container level SAS URL which can only access that container
+			cc, err = storage.GetContainerReferenceFromSASURI(*u)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to make azure storage client from SAS URL")
+			}
+			cc.Client().HTTPClient = fshttp.NewClient(fs.Config)
+			if container == "" {
+				return nil, errors.Errorf("container name must be supplied with a container level SAS URL - use %s:%s", name, cc.Name)
+			}
+			if container != cc.Name {
+				return nil, errors.Errorf("container name in SAS URL (%q) and container provided (%q) do not match", cc.Name, container)
+			}
+		}
+	case account == "":
+		return nil, errors.New("account not found")
+	case key == "":
+		return nil, errors.New("key not found")
+	default:
+		keyBytes, err = base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, errors.Errorf("malformed storage account key: %v", err)
+		}
+		client, err := storage.NewClient(account, key, endpoint, apiVersion, true)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make azure storage client")
+		}
+		client.HTTPClient = fshttp.NewClient(fs.Config)
+		blobService := client.GetBlobService()
+		bc = &blobService
+		cc = bc.GetContainerReference(container)
 	}
-	client.HTTPClient = fshttp.NewClient(fs.Config)
-	bc := client.GetBlobService()
 
 	f := &Fs{
 		name:        name,
@@ -211,15 +267,19 @@ func NewFs(name, root string) (fs.Fs, error) {
 		account:     account,
 		key:         keyBytes,
 		endpoint:    endpoint,
-		bc:          &bc,
-		cc:          bc.GetContainerReference(container),
-		pacer:       pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		sasURL:      sasu,
+		srv:         fshttp.NewClient(fs.Config),
+		bc:          bc,
+		cc:          cc,
+		pacer:       pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant).SetName(name),
 		uploadToken: pacer.NewTokenDispenser(fs.Config.Transfers),
 	}
 	f.features = (&fs.Features{
 		ReadMimeType:  true,
 		WriteMimeType: true,
 		BucketBased:   true,
+		SetTier:       true,
+		GetTier:       true,
 	}).Fill(f)
 	if f.root != "" {
 		f.root += "/"
@@ -508,6 +568,9 @@ type listContainerFn func(*storage.Container) error
 
 // listContainersToFn lists the containers to the function supplied
 func (f *Fs) listContainersToFn(fn listContainerFn) error {
+	if f.bc == nil {
+		return errors.New("can't list containers with a container level SAS URL")
+	}
 	// FIXME page the containers if necessary?
 	params := storage.ListContainersParameters{}
 	var response *storage.ContainerListResponse
@@ -739,6 +802,7 @@ func (o *Object) decodeMetaData(info *storage.Blob) (err error) {
 	o.mimeType = info.Properties.ContentType
 	o.size = info.Properties.ContentLength
 	o.modTime = time.Time(info.Properties.LastModified)
+	o.tier = "" // not in the properties the SDK reads
 	if len(info.Metadata) > 0 {
 		o.meta = info.Metadata
 		if modTime, ok := info.Metadata[modTimeKey]; ok {
@@ -1108,6 +1172,163 @@ func (o *Object) MimeType() string {
 	return o.mimeType
 }
 
+// sharedKey returns the Authorization header which signs req with
+// the account key
+//
+// See https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (f *Fs) sharedKey(req *http.Request) string {
+	var msHeaders []string
+	for k := range req.Header {
+		if k = strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			msHeaders = append(msHeaders, k)
+		}
+	}
+	sort.Strings(msHeaders)
+	for i, k := range msHeaders {
+		msHeaders[i] = k + ":" + strings.TrimSpace(req.Header.Get(k))
+	}
+	resource := "/" + f.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(k) + ":" + strings.Join(values, ",")
+	}
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		req.Header.Get("Content-Length"),
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date - x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		resource,
+	}, "\n")
+	mac := hmac.New(sha256.New, f.key)
+	_, _ = mac.Write([]byte(stringToSign))
+	return "SharedKey " + f.account + ":" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// blobCall makes a bodiless request to the blob at remote with the
+// params and headers given.  This is for the calls the vendored SDK
+// is too old to support.
+//
+// The request is authorized with the SAS URL if there is one or by
+// signing it with the account key.  Errors from the service are
+// returned as storage.AzureStorageServiceError so shouldRetry can
+// deal with them.
+func (f *Fs) blobCall(method, remote string, params url.Values, headers map[string]string) (resp *http.Response, err error) {
+	var u *url.URL
+	if f.sasURL != nil {
+		newURL := *f.sasURL
+		u = &newURL
+		query := u.Query()
+		for k, v := range params {
+			query[k] = v
+		}
+		u.RawQuery = query.Encode()
+		u.Path = "/" + f.container + "/" + f.root + remote
+	} else {
+		u, err = url.Parse(f.getBlobReference(remote).GetURL())
+		if err != nil {
+			return nil, err
+		}
+		u.RawQuery = params.Encode()
+	}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if f.sasURL == nil {
+		req.Header.Set("Authorization", f.sharedKey(req))
+	}
+	resp, err = f.srv.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(resp.Body, &err)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		serviceErr := storage.AzureStorageServiceError{}
+		body, _ := ioutil.ReadAll(resp.Body)
+		_ = xml.Unmarshal(body, &serviceErr)
+		if serviceErr.Message == "" {
+			serviceErr.Message = resp.Status
+		}
+		serviceErr.StatusCode = resp.StatusCode
+		serviceErr.RequestID = resp.Header.Get("x-ms-request-id")
+		return resp, serviceErr
+	}
+	return resp, nil
+}
+
+// parseTier returns the canonical form of tier or an error if it isn't
+// one azure supports
+func parseTier(tier string) (string, error) {
+	for _, t := range []string{"Hot", "Cool", "Archive"} {
+		if strings.EqualFold(tier, t) {
+			return t, nil
+		}
+	}
+	return "", errors.Errorf("azure: tier %q not supported - use Hot, Cool or Archive", tier)
+}
+
+// GetTier returns the access tier of the blob
+//
+// The listings the SDK makes don't include the tier so it is read
+// from the blob the first time it is needed.
+func (o *Object) GetTier() string {
+	if o.tier != "" {
+		return o.tier
+	}
+	var resp *http.Response
+	err := o.fs.pacer.Call(func() (bool, error) {
+		var err error
+		resp, err = o.fs.blobCall("HEAD", o.remote, nil, nil)
+		return o.fs.shouldRetry(err)
+	})
+	if err != nil {
+		fs.Debugf(o, "Failed to read access tier: %v", err)
+		return ""
+	}
+	o.tier = resp.Header.Get(tierHeader)
+	return o.tier
+}
+
+// SetTier changes the access tier of the blob to Hot, Cool or Archive
+func (o *Object) SetTier(tier string) error {
+	tier, err := parseTier(tier)
+	if err != nil {
+		return err
+	}
+	params := url.Values{"comp": {"tier"}}
+	headers := map[string]string{tierHeader: tier}
+	err = o.fs.pacer.Call(func() (bool, error) {
+		_, err := o.fs.blobCall("PUT", o.remote, params, headers)
+		return o.fs.shouldRetry(err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to set access tier")
+	}
+	o.tier = tier
+	return nil
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs        = &Fs{}
@@ -1116,4 +1337,6 @@ var (
 	_ fs.ListRer   = &Fs{}
 	_ fs.Object    = &Object{}
 	_ fs.MimeTyper = &Object{}
+	_ fs.SetTierer = &Object{}
+	_ fs.GetTierer = &Object{}
 )
@@ -0,0 +1,157 @@
+package azureblob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/lib/pacer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends all requests to the test server at host
+type redirectTransport string
+
+func (host redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = "http"
+	req.URL.Host = string(host)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// tierServer is a test server which keeps the access tier of the
+// blob container/dir/file
+type tierServer struct {
+	*httptest.Server
+	tier     string
+	requests []*http.Request
+}
+
+func newTierServer(t *testing.T) *tierServer {
+	ts := &tierServer{tier: "Hot"}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.requests = append(ts.requests, r)
+		assert.Equal(t, apiVersion, r.Header.Get("x-ms-version"))
+		switch {
+		case r.URL.Path != "/container/dir/file":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><Error><Code>BlobNotFound</Code><Message>The specified blob does not exist.</Message></Error>`))
+		case r.Method == "HEAD":
+			w.Header().Set(tierHeader, ts.tier)
+		case r.Method == "PUT" && r.URL.Query().Get("comp") == "tier":
+			ts.tier = r.Header.Get(tierHeader)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	return ts
+}
+
+// newTestFs makes an Fs for container/dir/ which talks to ts
+func newTestFs(ts *tierServer) *Fs {
+	return &Fs{
+		container: "container",
+		root:      "dir/",
+		srv:       &http.Client{Transport: redirectTransport(ts.Listener.Addr().String())},
+		pacer:     pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+	}
+}
+
+func TestParseTier(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"Hot", "Hot", false},
+		{"cool", "Cool", false},
+		{"ARCHIVE", "Archive", false},
+		{"STANDARD_IA", "", true},
+		{"", "", true},
+	} {
+		got, err := parseTier(test.in)
+		assert.Equal(t, test.want, got, test.in)
+		assert.Equal(t, test.wantErr, err != nil, test.in)
+	}
+}
+
+func TestTierSharedKey(t *testing.T) {
+	ts := newTierServer(t)
+	defer ts.Close()
+	key := []byte("0123456789abcdef")
+	client, err := storage.NewClient("account", base64.StdEncoding.EncodeToString(key), storage.DefaultBaseURL, apiVersion, true)
+	require.NoError(t, err)
+	bc := client.GetBlobService()
+	f := newTestFs(ts)
+	f.account, f.key, f.bc = "account", key, &bc
+	f.cc = bc.GetContainerReference(f.container)
+	o := &Object{fs: f, remote: "file"}
+
+	assert.Equal(t, "Hot", o.GetTier())
+	require.NoError(t, o.SetTier("cool"))
+	assert.Equal(t, "Cool", ts.tier)
+	assert.Equal(t, "Cool", o.GetTier())
+	require.Len(t, ts.requests, 2, "tier should be cached")
+
+	// Check the signature of the set tier request
+	req := ts.requests[1]
+	assert.Equal(t, "tier", req.URL.Query().Get("comp"))
+	stringToSign := "PUT\n\n\n\n\n\n\n\n\n\n\n\n" +
+		"x-ms-access-tier:Cool\n" +
+		"x-ms-date:" + req.Header.Get("x-ms-date") + "\n" +
+		"x-ms-version:" + apiVersion + "\n" +
+		"/account/container/dir/file\ncomp:tier"
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(stringToSign))
+	assert.Equal(t, "SharedKey account:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get("Authorization"))
+
+	// A missing blob returns the service error
+	missing := &Object{fs: f, remote: "missing"}
+	err = missing.SetTier("Archive")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "The specified blob does not exist")
+	assert.Equal(t, "", missing.GetTier())
+
+	// Bad tiers aren't sent
+	n := len(ts.requests)
+	assert.Error(t, o.SetTier("Glacier"))
+	assert.Equal(t, n, len(ts.requests))
+}
+
+func TestTierSAS(t *testing.T) {
+	ts := newTierServer(t)
+	defer ts.Close()
+	f := newTestFs(ts)
+	var err error
+	f.sasURL, err = url.Parse("https://account.blob.core.windows.net/container?sv=2017-04-17&sig=signature")
+	require.NoError(t, err)
+	o := &Object{fs: f, remote: "file"}
+
+	require.NoError(t, o.SetTier("Archive"))
+	assert.Equal(t, "Archive", ts.tier)
+	assert.Equal(t, "Archive", o.GetTier())
+	require.Len(t, ts.requests, 1)
+	req := ts.requests[0]
+	assert.Equal(t, "", req.Header.Get("Authorization"))
+	query := req.URL.Query()
+	assert.Equal(t, "tier", query.Get("comp"))
+	assert.Equal(t, "signature", query.Get("sig"))
+	assert.Equal(t, "/container/dir/file", req.URL.Path)
+}
+
+func TestNewFsContainerSASHTTPClient(t *testing.T) {
+	config.LoadConfig()
+	config.FileSet("TestAzureBlobSAS", "type", "azureblob")
+	config.FileSet("TestAzureBlobSAS", "sas_url", "https://account.blob.core.windows.net/container?sv=2017-04-17&sig=signature")
+	f, err := NewFs("TestAzureBlobSAS", "container")
+	require.NoError(t, err)
+	client := f.(*Fs).cc.Client().HTTPClient
+	assert.NotEqual(t, http.DefaultClient, client, "must use the rclone HTTP client")
+}
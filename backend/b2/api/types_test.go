@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -85,3 +86,28 @@ func TestTimestampEqual(t *testing.T) {
 	assert.True(t, t0.Equal(t0))
 	assert.True(t, t1.Equal(t1))
 }
+
+func TestLifecycleRuleMarshalJSON(t *testing.T) {
+	days := 30
+	rules := []api.LifecycleRule{
+		{DaysFromHidingToDeleting: &days, FileNamePrefix: "logs/"},
+	}
+	resB, err := json.Marshal(rules)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"daysFromHidingToDeleting":30,"daysFromUploadingToHiding":null,"fileNamePrefix":"logs/"}]`, string(resB))
+
+	var back []api.LifecycleRule
+	require.NoError(t, json.Unmarshal(resB, &back))
+	assert.Equal(t, rules, back)
+}
+
+func TestUpdateBucketRequestMarshalJSON(t *testing.T) {
+	request := api.UpdateBucketRequest{
+		ID:             "bid",
+		AccountID:      "aid",
+		LifecycleRules: []api.LifecycleRule{},
+	}
+	resB, err := json.Marshal(&request)
+	require.NoError(t, err)
+	assert.Equal(t, `{"bucketId":"bid","accountId":"aid","lifecycleRules":[]}`, string(resB))
+}
@@ -58,7 +58,7 @@ var (
 	b2TestMode         = flags.StringP("b2-test-mode", "", "", "A flag string for X-Bz-Test-Mode header.")
 	b2Versions         = flags.BoolP("b2-versions", "", false, "Include old versions in directory listings.")
 	b2HardDelete       = flags.BoolP("b2-hard-delete", "", false, "Permanently delete files on remote removal, otherwise hide files.")
-	errNotWithVersions = errors.New("can't modify or delete files in --b2-versions mode")
+	errNotWithVersions = errors.New("can't modify files in --b2-versions mode")
 )
 
 // Register with Fs
@@ -78,6 +78,7 @@ func init() {
 			Help: "Endpoint for the service - leave blank normally.",
 		},
 		},
+		CommandHelp: commandHelp,
 	})
 	flags.VarP(&uploadCutoff, "b2-upload-cutoff", "", "Cutoff for switching to chunked upload")
 	flags.VarP(&chunkSize, "b2-chunk-size", "", "Upload chunk size. Must fit in memory.")
@@ -173,8 +174,8 @@ var retryErrorCodes = []int{
 // deserve to be retried.  It returns the err as a convenience
 func (f *Fs) shouldRetryNoReauth(resp *http.Response, err error) (bool, error) {
 	// For 429 or 503 errors look at the Retry-After: header and
-	// set the retry appropriately, starting with a minimum of 1
-	// second if it isn't set.
+	// tell the pacer to wait that long before retrying, starting
+	// with a minimum of 1 second if it isn't set.
 	if resp != nil && (resp.StatusCode == 429 || resp.StatusCode == 503) {
 		var retryAfter = 1
 		retryAfterString := resp.Header.Get(retryAfterHeader)
@@ -186,12 +187,8 @@ func (f *Fs) shouldRetryNoReauth(resp *http.Response, err error) (bool, error) {
 			}
 		}
 		retryAfterDuration := time.Duration(retryAfter) * time.Second
-		if f.pacer.GetSleep() < retryAfterDuration {
-			fs.Debugf(f, "Setting sleep to %v after error: %v", retryAfterDuration, err)
-			// We set 1/2 the value here because the pacer will double it immediately
-			f.pacer.SetSleep(retryAfterDuration / 2)
-		}
-		return true, err
+		fs.Debugf(f, "Retrying after %v after error: %v", retryAfterDuration, err)
+		return true, fserrors.RetryAfterError(err, retryAfterDuration)
 	}
 	return fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), err
 }
@@ -260,7 +257,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		key:          key,
 		endpoint:     endpoint,
 		srv:          rest.NewClient(fshttp.NewClient(fs.Config)).SetErrorHandler(errorHandler),
-		pacer:        pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer:        pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant).SetName(name),
 		bufferTokens: make(chan []byte, fs.Config.Transfers),
 	}
 	f.features = (&fs.Features{
@@ -861,6 +858,194 @@ func (f *Fs) deleteByID(ID, Name string) error {
 	return nil
 }
 
+// unhide removes the hide marker from the file at remote making the
+// previous version of it the current one again
+func (f *Fs) unhide(remote string) error {
+	var marker *api.File
+	err := f.list("", true, remote, 1, true, func(entryRemote string, object *api.File, isDirectory bool) error {
+		if !isDirectory && entryRemote == remote {
+			marker = object
+		}
+		return errEndList // the newest version is listed first
+	})
+	if err != nil {
+		if err == fs.ErrorDirNotFound {
+			return fs.ErrorObjectNotFound
+		}
+		return err
+	}
+	if marker == nil {
+		return fs.ErrorObjectNotFound
+	}
+	if marker.Action != "hide" {
+		return errors.Errorf("%q is not hidden", remote)
+	}
+	return f.deleteByID(marker.ID, marker.Name)
+}
+
+// getLifecycle returns the lifecycle rules of the bucket
+func (f *Fs) getLifecycle() (rules []api.LifecycleRule, err error) {
+	found := false
+	err = f.listBucketsToFn(func(bucket *api.Bucket) error {
+		if bucket.Name == f.bucket {
+			found = true
+			rules = bucket.LifecycleRules
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read lifecycle rules")
+	}
+	if !found {
+		return nil, fs.ErrorDirNotFound
+	}
+	return rules, nil
+}
+
+// setLifecycle replaces the lifecycle rules of the bucket with rules,
+// returning the rules the bucket now has
+//
+// Passing no rules removes all the lifecycle rules.
+func (f *Fs) setLifecycle(rules []api.LifecycleRule) ([]api.LifecycleRule, error) {
+	bucketID, err := f.getBucketID()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []api.LifecycleRule{}
+	}
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/b2_update_bucket",
+	}
+	var request = api.UpdateBucketRequest{
+		ID:             bucketID,
+		AccountID:      f.info.AccountID,
+		LifecycleRules: rules,
+	}
+	var response api.Bucket
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.CallJSON(&opts, &request, &response)
+		return f.shouldRetry(resp, err)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set lifecycle rules")
+	}
+	return response.LifecycleRules, nil
+}
+
+var commandHelp = []fs.CommandHelp{{
+	Name:  "hide",
+	Short: "Hide files in the bucket.",
+	Long: `This hides the files passed in as arguments, making their current
+versions into old ones, eg
+
+    rclone backend hide b2:bucket/path file1.txt file2.txt
+
+The files can be made visible again with the "unhide" command.
+`,
+}, {
+	Name:  "unhide",
+	Short: "Unhide hidden files in the bucket.",
+	Long: `This removes the hide markers from the files passed in as
+arguments, making their newest old versions current again, eg
+
+    rclone backend unhide b2:bucket/path file1.txt file2.txt
+`,
+}, {
+	Name:  "lifecycle",
+	Short: "Read or set the lifecycle rules for a bucket.",
+	Long: `This command can be used to read or set the lifecycle rules of a
+bucket.  With no options it shows the current rules, eg
+
+    rclone backend lifecycle b2:bucket
+
+Setting any of the options replaces all the rules of the bucket with a
+single rule for the files under the remote path, eg
+
+    rclone backend lifecycle b2:bucket/path -o daysFromHidingToDeleting=1
+
+Use "-o clear" to remove all the lifecycle rules.  Note that the rules
+apply to the whole bucket, not just the files rclone can see.
+`,
+	Opts: map[string]string{
+		"daysFromHidingToDeleting":  "Number of days from a file being hidden to it being deleted",
+		"daysFromUploadingToHiding": "Number of days from a file being uploaded to it being hidden",
+		"clear":                     "Remove all the lifecycle rules",
+	},
+}}
+
+// Command the backend to run a named command
+//
+// The command run is name
+// args may be used to read arguments from
+// opt may be used to read optional arguments from
+//
+// The result should be capable of being JSON encoded
+// If it is a string or a []string it will be shown to the user
+// otherwise it will be JSON encoded and shown to the user like that
+func (f *Fs) Command(name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	switch name {
+	case "hide":
+		for _, remote := range arg {
+			err = f.hide(f.root + remote)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case "unhide":
+		for _, remote := range arg {
+			err = f.unhide(remote)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to unhide %q", remote)
+			}
+		}
+		return nil, nil
+	case "lifecycle":
+		if _, ok := opt["clear"]; ok {
+			return f.setLifecycle(nil)
+		}
+		rule, ok, err := f.lifecycleRuleFromOpts(opt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return f.getLifecycle()
+		}
+		return f.setLifecycle([]api.LifecycleRule{rule})
+	default:
+		return nil, fs.ErrorCommandNotFound
+	}
+}
+
+// lifecycleRuleFromOpts makes a lifecycle rule for the root of the Fs
+// from the options passed to the lifecycle command
+//
+// It returns false if no rule was asked for.
+func (f *Fs) lifecycleRuleFromOpts(opt map[string]string) (rule api.LifecycleRule, ok bool, err error) {
+	rule.FileNamePrefix = f.root
+	for _, item := range []struct {
+		name  string
+		value **int
+	}{
+		{"daysFromHidingToDeleting", &rule.DaysFromHidingToDeleting},
+		{"daysFromUploadingToHiding", &rule.DaysFromUploadingToHiding},
+	} {
+		s, found := opt[item.name]
+		if !found {
+			continue
+		}
+		days, err := strconv.Atoi(s)
+		if err != nil || days <= 0 {
+			return rule, false, errors.Errorf("bad value %q for %s - must be a positive number of days", s, item.name)
+		}
+		*item.value = &days
+		ok = true
+	}
+	return rule, ok, nil
+}
+
 // purge deletes all the files and directories
 //
 // if oldOnly is true then it deletes only non current files.
@@ -1153,14 +1338,14 @@ func (file *openFile) Close() (err error) {
 
 	// Check to see we read the correct number of bytes
 	if file.o.Size() != file.bytes {
-		return errors.Errorf("object corrupted on transfer - length mismatch (want %d got %d)", file.o.Size(), file.bytes)
+		return fserrors.ClassError(errors.Errorf("object corrupted on transfer - length mismatch (want %d got %d)", file.o.Size(), file.bytes), fserrors.ClassChecksum)
 	}
 
 	// Check the SHA1
 	receivedSHA1 := file.o.sha1
 	calculatedSHA1 := fmt.Sprintf("%x", file.hash.Sum(nil))
 	if receivedSHA1 != "" && receivedSHA1 != calculatedSHA1 {
-		return errors.Errorf("object corrupted on transfer - SHA1 mismatch (want %q got %q)", receivedSHA1, calculatedSHA1)
+		return fserrors.ClassError(errors.Errorf("object corrupted on transfer - SHA1 mismatch (want %q got %q)", receivedSHA1, calculatedSHA1), fserrors.ClassChecksum)
 	}
 
 	return nil
@@ -1409,7 +1594,13 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 // Remove an object
 func (o *Object) Remove() error {
 	if *b2Versions {
-		return errNotWithVersions
+		// Delete just this version of the file
+		err := o.readMetaData()
+		if err != nil {
+			return err
+		}
+		_, baseRemote := api.RemoveVersion(o.remote)
+		return o.fs.deleteByID(o.id, o.fs.root+baseRemote)
 	}
 	if *b2HardDelete {
 		return o.fs.deleteByID(o.id, o.fs.root+o.remote)
@@ -1428,6 +1619,7 @@ var (
 	_ fs.Purger      = &Fs{}
 	_ fs.PutStreamer = &Fs{}
 	_ fs.CleanUpper  = &Fs{}
+	_ fs.Commander   = &Fs{}
 	_ fs.ListRer     = &Fs{}
 	_ fs.Object      = &Object{}
 	_ fs.MimeTyper   = &Object{}
@@ -168,3 +168,50 @@ func TestParseTimeString(t *testing.T) {
 	}
 
 }
+
+func TestLifecycleRuleFromOpts(t *testing.T) {
+	f := &Fs{root: "path/"}
+	for _, test := range []struct {
+		opt       map[string]string
+		wantOK    bool
+		wantHide  int
+		wantDel   int
+		wantError bool
+	}{
+		{map[string]string{}, false, 0, 0, false},
+		{map[string]string{"daysFromHidingToDeleting": "1"}, true, 0, 1, false},
+		{map[string]string{"daysFromUploadingToHiding": "7", "daysFromHidingToDeleting": "2"}, true, 7, 2, false},
+		{map[string]string{"daysFromHidingToDeleting": "0"}, false, 0, 0, true},
+		{map[string]string{"daysFromUploadingToHiding": "potato"}, false, 0, 0, true},
+	} {
+		rule, ok, err := f.lifecycleRuleFromOpts(test.opt)
+		if test.wantError {
+			if err == nil {
+				t.Errorf("%v: expecting error", test.opt)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", test.opt, err)
+			continue
+		}
+		if ok != test.wantOK {
+			t.Errorf("%v: want ok %v got %v", test.opt, test.wantOK, ok)
+		}
+		if rule.FileNamePrefix != "path/" {
+			t.Errorf("%v: want prefix %q got %q", test.opt, "path/", rule.FileNamePrefix)
+		}
+		days := func(p *int) int {
+			if p == nil {
+				return 0
+			}
+			return *p
+		}
+		if got := days(rule.DaysFromUploadingToHiding); got != test.wantHide {
+			t.Errorf("%v: want daysFromUploadingToHiding %d got %d", test.opt, test.wantHide, got)
+		}
+		if got := days(rule.DaysFromHidingToDeleting); got != test.wantDel {
+			t.Errorf("%v: want daysFromHidingToDeleting %d got %d", test.opt, test.wantDel, got)
+		}
+	}
+}
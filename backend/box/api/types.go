@@ -190,3 +190,13 @@ type CommitUpload struct {
 		ContentModifiedAt Time `json:"content_modified_at"`
 	} `json:"attributes"`
 }
+
+// User is returned from the get users/me call
+type User struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Login       string `json:"login"`
+	SpaceAmount int64  `json:"space_amount"`
+	SpaceUsed   int64  `json:"space_used"`
+}
@@ -62,6 +62,11 @@ var (
 		RedirectURL:  oauthutil.RedirectURL,
 	}
 	uploadCutoff = fs.SizeSuffix(50 * 1024 * 1024)
+
+	// Flags
+	boxPacerMinSleep = flags.DurationP("box-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	boxPacerMaxSleep = flags.DurationP("box-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	boxPacerBurst    = flags.IntP("box-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
 )
 
 // Register with Fs
@@ -159,7 +164,7 @@ func shouldRetry(resp *http.Response, err error) (bool, error) {
 		authRety = true
 		fs.Debugf(nil, "Should retry: %v", err)
 	}
-	return authRety || fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), err
+	return fserrors.RetryAfterHTTP(authRety || fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
 }
 
 // substitute reserved characters for box
@@ -234,7 +239,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:        name,
 		root:        root,
 		srv:         rest.NewClient(oAuthClient).SetRoot(rootURL),
-		pacer:       pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer:       pacer.New().SetMinSleep(*boxPacerMinSleep).SetMaxSleep(*boxPacerMaxSleep).SetBurst(*boxPacerBurst).SetDecayConstant(decayConstant).SetName(name),
 		uploadToken: pacer.NewTokenDispenser(fs.Config.Transfers),
 	}
 	f.features = (&fs.Features{
@@ -825,6 +830,41 @@ func (f *Fs) DirCacheFlush() {
 	f.dirCache.ResetRoot()
 }
 
+// About gets quota information
+func (f *Fs) About() (usage *fs.Usage, err error) {
+	opts := rest.Opts{
+		Method:     "GET",
+		Path:       "/users/me",
+		Parameters: url.Values{"fields": []string{"space_amount,space_used"}},
+	}
+	var resp *http.Response
+	var user api.User
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.srv.CallJSON(&opts, nil, &user)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "about failed")
+	}
+	return usageFromUser(&user), nil
+}
+
+// usageFromUser makes an fs.Usage from the space in the user info
+//
+// Box lets the space used go over the quota so the free space is
+// never allowed to go negative.
+func usageFromUser(user *api.User) *fs.Usage {
+	free := user.SpaceAmount - user.SpaceUsed
+	if free < 0 {
+		free = 0
+	}
+	return &fs.Usage{
+		Total: fs.NewUsageValue(user.SpaceAmount), // quota of bytes that can be used
+		Used:  fs.NewUsageValue(user.SpaceUsed),   // bytes in use
+		Free:  fs.NewUsageValue(free),             // bytes which can be uploaded before reaching the quota
+	}
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hash.SHA1)
@@ -1057,5 +1097,6 @@ var (
 	_ fs.Mover           = (*Fs)(nil)
 	_ fs.DirMover        = (*Fs)(nil)
 	_ fs.DirCacheFlusher = (*Fs)(nil)
+	_ fs.Abouter         = (*Fs)(nil)
 	_ fs.Object          = (*Object)(nil)
 )
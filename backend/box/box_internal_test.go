@@ -0,0 +1,25 @@
+package box
+
+import (
+	"testing"
+
+	"github.com/ncw/rclone/backend/box/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageFromUser(t *testing.T) {
+	for _, test := range []struct {
+		amount, used int64
+		wantFree     int64
+	}{
+		{amount: 100, used: 30, wantFree: 70},
+		{amount: 100, used: 100, wantFree: 0},
+		// Over quota
+		{amount: 100, used: 150, wantFree: 0},
+	} {
+		usage := usageFromUser(&api.User{SpaceAmount: test.amount, SpaceUsed: test.used})
+		assert.Equal(t, test.wantFree, *usage.Free, "%+v", test)
+		assert.Equal(t, test.amount, *usage.Total, "%+v", test)
+		assert.Equal(t, test.used, *usage.Used, "%+v", test)
+	}
+}
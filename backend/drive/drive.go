@@ -8,6 +8,7 @@ package drive
 // * files with / in name
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -19,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/ncw/rclone/fs"
@@ -46,7 +48,8 @@ const (
 	timeFormatIn                = time.RFC3339
 	timeFormatOut               = "2006-01-02T15:04:05.000000000Z07:00"
 	minSleep                    = 10 * time.Millisecond
-	defaultExtensions           = "docx,xlsx,pptx,svg"
+	maxSleep                    = 16 * time.Second
+	defaultExportExtensions     = "docx,xlsx,pptx,svg"
 	scopePrefix                 = "https://www.googleapis.com/auth/"
 	defaultScope                = "drive"
 )
@@ -54,15 +57,22 @@ const (
 // Globals
 var (
 	// Flags
-	driveAuthOwnerOnly  = flags.BoolP("drive-auth-owner-only", "", false, "Only consider files owned by the authenticated user.")
-	driveUseTrash       = flags.BoolP("drive-use-trash", "", true, "Send files to the trash instead of deleting permanently.")
-	driveSkipGdocs      = flags.BoolP("drive-skip-gdocs", "", false, "Skip google documents in all listings.")
-	driveSharedWithMe   = flags.BoolP("drive-shared-with-me", "", false, "Only show files that are shared with me")
-	driveTrashedOnly    = flags.BoolP("drive-trashed-only", "", false, "Only show files that are in the trash")
-	driveExtensions     = flags.StringP("drive-formats", "", defaultExtensions, "Comma separated list of preferred formats for downloading Google docs.")
-	driveUseCreatedDate = flags.BoolP("drive-use-created-date", "", false, "Use created date instead of modified date.")
-	driveListChunk      = flags.Int64P("drive-list-chunk", "", 1000, "Size of listing chunk 100-1000. 0 to disable.")
-	driveImpersonate    = flags.StringP("drive-impersonate", "", "", "Impersonate this user when using a service account.")
+	driveAuthOwnerOnly           = flags.BoolP("drive-auth-owner-only", "", false, "Only consider files owned by the authenticated user.")
+	driveUseTrash                = flags.BoolP("drive-use-trash", "", true, "Send files to the trash instead of deleting permanently.")
+	driveSkipGdocs               = flags.BoolP("drive-skip-gdocs", "", false, "Skip google documents in all listings.")
+	driveSharedWithMe            = flags.BoolP("drive-shared-with-me", "", false, "Only show files that are shared with me")
+	driveTrashedOnly             = flags.BoolP("drive-trashed-only", "", false, "Only show files that are in the trash")
+	driveExtensions              = flags.StringP("drive-formats", "", "", "Deprecated: see --drive-export-formats")
+	driveExportFormats           = flags.StringP("drive-export-formats", "", defaultExportExtensions, "Comma separated list of preferred formats for downloading Google docs.")
+	driveImportFormats           = flags.StringP("drive-import-formats", "", "", "Comma separated list of preferred formats for uploading Google docs.")
+	driveUseCreatedDate          = flags.BoolP("drive-use-created-date", "", false, "Use created date instead of modified date.")
+	driveListChunk               = flags.Int64P("drive-list-chunk", "", 1000, "Size of listing chunk 100-1000. 0 to disable.")
+	driveImpersonate             = flags.StringP("drive-impersonate", "", "", "Impersonate this user when using a service account.")
+	driveTeamDrive               = flags.StringP("drive-team-drive", "", "", "ID of the Team Drive to use instead of the one in the config.")
+	driveServerSideAcrossConfigs = flags.BoolP("drive-server-side-across-configs", "", false, "Allow server side operations (eg copy) to work across different drive configs.")
+	drivePacerMinSleep           = flags.DurationP("drive-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	drivePacerMaxSleep           = flags.DurationP("drive-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	drivePacerBurst              = flags.IntP("drive-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
 	// chunkSize is the size of the chunks created during a resumable upload and should be a power of two.
 	// 1<<18 is the minimum size supported by the Google uploader, and there is no maximum.
 	chunkSize         = fs.SizeSuffix(8 * 1024 * 1024)
@@ -97,10 +107,56 @@ var (
 		"text/plain":                                                                "txt",
 		"text/tab-separated-values":                                                 "tsv",
 	}
+	// Link files which can be exported instead of the document
+	// itself.  These have made up mime types.
+	mimeTypeToExtensionLinks = map[string]string{
+		"application/x-link-desktop": "desktop",
+		"application/x-link-html":    "link.html",
+		"application/x-link-url":     "url",
+		"application/x-link-webloc":  "webloc",
+	}
+	linkTemplates = map[string]*template.Template{
+		"application/x-link-desktop": template.Must(template.New("desktop").Parse(desktopTemplate)),
+		"application/x-link-html":    template.Must(template.New("link.html").Parse(htmlTemplate)),
+		"application/x-link-url":     template.Must(template.New("url").Parse(urlTemplate)),
+		"application/x-link-webloc":  template.Must(template.New("webloc").Parse(weblocTemplate)),
+	}
 	extensionToMimeType map[string]string
-	partialFields       = "id,name,size,md5Checksum,trashed,modifiedTime,createdTime,mimeType"
-	exportFormatsOnce   sync.Once           // make sure we fetch the export formats only once
+	partialFields       = "id,name,size,md5Checksum,trashed,modifiedTime,createdTime,mimeType,webViewLink"
+	formatsOnce         sync.Once           // make sure we fetch the export and import formats only once
 	_exportFormats      map[string][]string // allowed export mime-type conversions
+	_importFormats      map[string][]string // allowed import mime-type conversions
+)
+
+// Templates for the link files
+const (
+	desktopTemplate = `[Desktop Entry]
+Encoding=UTF-8
+Name={{ .Title }}
+URL={{ .URL }}
+Icon=text-html
+Type=Link
+`
+	htmlTemplate = `<html>
+<head>
+  <meta http-equiv="refresh" content="0; url={{ .URL | html }}" />
+  <title>{{ .Title | html }}</title>
+</head>
+<body>
+  Loading <a href="{{ .URL | html }}">{{ .Title | html }}</a>
+</body>
+</html>
+`
+	urlTemplate    = "[InternetShortcut]\r\nURL={{ .URL }}\r\n"
+	weblocTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+  <dict>
+    <key>URL</key>
+    <string>{{ .URL | html }}</string>
+  </dict>
+</plist>
+`
 )
 
 // Register with Fs
@@ -112,17 +168,10 @@ func init() {
 		Config: func(name string) {
 			var err error
 			// Fill in the scopes
-			scope := config.FileGet(name, "scope")
-			if scope == "" {
-				scope = defaultScope
-			}
-			driveConfig.Scopes = nil
-			for _, scope := range strings.Split(scope, ",") {
-				driveConfig.Scopes = append(driveConfig.Scopes, scopePrefix+strings.TrimSpace(scope))
+			driveConfig.Scopes = driveScopes(name)
+			if driveScopesContainsAppFolder(driveConfig.Scopes) {
 				// Set the root_folder_id if using drive.appfolder
-				if scope == "drive.appfolder" {
-					config.FileSet(name, "root_folder_id", "appDataFolder")
-				}
+				config.FileSet(name, "root_folder_id", "appDataFolder")
 			}
 			if config.FileGet(name, "service_account_file") == "" {
 				err = oauthutil.Config("drive", name, driveConfig)
@@ -166,31 +215,38 @@ func init() {
 		}, {
 			Name: "service_account_file",
 			Help: "Service Account Credentials JSON file path  - leave blank normally.\nNeeded only if you want use SA instead of interactive login.",
+		}, {
+			Name: "impersonate",
+			Help: "Email of the user to impersonate when using a service account - leave blank normally.\nNeeds domain-wide delegation.  Overridden by --drive-impersonate.",
 		}},
 	})
 	flags.VarP(&driveUploadCutoff, "drive-upload-cutoff", "", "Cutoff for switching to chunked upload")
 	flags.VarP(&chunkSize, "drive-chunk-size", "", "Upload chunk size. Must a power of 2 >= 256k.")
 
-	// Invert mimeTypeToExtension
-	extensionToMimeType = make(map[string]string, len(mimeTypeToExtension))
+	// Invert mimeTypeToExtension and mimeTypeToExtensionLinks
+	extensionToMimeType = make(map[string]string, len(mimeTypeToExtension)+len(mimeTypeToExtensionLinks))
 	for mimeType, extension := range mimeTypeToExtension {
 		extensionToMimeType[extension] = mimeType
 	}
+	for mimeType, extension := range mimeTypeToExtensionLinks {
+		extensionToMimeType[extension] = mimeType
+	}
 }
 
 // Fs represents a remote drive server
 type Fs struct {
-	name         string             // name of this remote
-	root         string             // the path we are working on
-	features     *fs.Features       // optional features
-	svc          *drive.Service     // the connection to the drive server
-	client       *http.Client       // authorized client
-	rootFolderID string             // the id of the root folder
-	dirCache     *dircache.DirCache // Map of directory path to directory id
-	pacer        *pacer.Pacer       // To pace the API calls
-	extensions   []string           // preferred extensions to download docs
-	teamDriveID  string             // team drive ID, may be ""
-	isTeamDrive  bool               // true if this is a team drive
+	name             string             // name of this remote
+	root             string             // the path we are working on
+	features         *fs.Features       // optional features
+	svc              *drive.Service     // the connection to the drive server
+	client           *http.Client       // authorized client
+	rootFolderID     string             // the id of the root folder
+	dirCache         *dircache.DirCache // Map of directory path to directory id
+	pacer            *pacer.Pacer       // To pace the API calls
+	exportExtensions []string           // preferred extensions to download docs
+	importMimeTypes  []string           // mime types of files to convert to docs on upload
+	teamDriveID      string             // team drive ID, may be ""
+	isTeamDrive      bool               // true if this is a team drive
 }
 
 // Object describes a drive object
@@ -204,6 +260,7 @@ type Object struct {
 	modifiedDate string // RFC3339 time it was last modified
 	isDocument   bool   // if set this is a Google doc
 	mimeType     string
+	linkContent  []byte // if set this is a link file for a Google doc
 }
 
 // ------------------------------------------------------------
@@ -240,12 +297,23 @@ func shouldRetry(err error) (again bool, errOut error) {
 				if gerr.Code >= 500 && gerr.Code < 600 {
 					// All 5xx errors should be retried
 					again = true
+				} else if gerr.Code == http.StatusTooManyRequests {
+					again = true
+					err = fserrors.RateLimitError(err)
 				} else if len(gerr.Errors) > 0 {
 					reason := gerr.Errors[0].Reason
-					if reason == "rateLimitExceeded" || reason == "userRateLimitExceeded" {
+					switch reason {
+					case "rateLimitExceeded", "userRateLimitExceeded":
+						// These come with a 403
 						again = true
+						err = fserrors.RateLimitError(err)
+					case "storageQuotaExceeded", "quotaExceeded", "teamDriveFileLimitExceeded":
+						err = fserrors.ClassError(err, fserrors.ClassQuota)
+					case "insufficientPermissions", "insufficientFilePermissions", "forbidden":
+						err = fserrors.ClassError(err, fserrors.ClassPermission)
 					}
 				}
+				again, err = fserrors.RetryAfterHeader(again, gerr.Header, err)
 			}
 		}
 	}
@@ -370,25 +438,33 @@ func isPowerOfTwo(x int64) bool {
 	}
 }
 
-// parseExtensions parses drive export extensions from a string
-func (f *Fs) parseExtensions(extensions string) error {
-	for _, extension := range strings.Split(extensions, ",") {
-		extension = strings.ToLower(strings.TrimSpace(extension))
-		if _, found := extensionToMimeType[extension]; !found {
-			return errors.Errorf("couldn't find mime type for extension %q", extension)
-		}
-		found := false
-		for _, existingExtension := range f.extensions {
-			if extension == existingExtension {
-				found = true
-				break
+// parseExtensions parses comma separated lists of drive extensions,
+// returning the extensions without duplicates and their mime types
+func parseExtensions(extensionsIn ...string) (extensions, mimeTypes []string, err error) {
+	for _, extensionText := range extensionsIn {
+		for _, extension := range strings.Split(extensionText, ",") {
+			extension = strings.ToLower(strings.TrimSpace(extension))
+			if extension == "" {
+				continue
+			}
+			mimeType, found := extensionToMimeType[extension]
+			if !found {
+				return extensions, mimeTypes, errors.Errorf("couldn't find mime type for extension %q", extension)
+			}
+			found = false
+			for _, existingExtension := range extensions {
+				if extension == existingExtension {
+					found = true
+					break
+				}
+			}
+			if !found {
+				extensions = append(extensions, extension)
+				mimeTypes = append(mimeTypes, mimeType)
 			}
-		}
-		if !found {
-			f.extensions = append(f.extensions, extension)
 		}
 	}
-	return nil
+	return extensions, mimeTypes, nil
 }
 
 // Figure out if the user wants to use a team drive
@@ -415,7 +491,7 @@ func configTeamDrive(name string) error {
 	listTeamDrives := svc.Teamdrives.List().PageSize(100)
 	for {
 		var teamDrives *drive.TeamDriveList
-		err = newPacer().Call(func() (bool, error) {
+		err = newPacer(name).Call(func() (bool, error) {
 			teamDrives, err = listTeamDrives.Do()
 			return shouldRetry(err)
 		})
@@ -433,7 +509,7 @@ func configTeamDrive(name string) error {
 	}
 	var driveID string
 	if len(driveIDs) == 0 {
-		fmt.Printf("No team drives found in your account")
+		fmt.Printf("No team drives found in your account\n")
 	} else {
 		driveID = config.Choose("Enter a Team Drive ID", driveIDs, driveNames, true)
 	}
@@ -441,18 +517,50 @@ func configTeamDrive(name string) error {
 	return nil
 }
 
-// newPacer makes a pacer configured for drive
-func newPacer() *pacer.Pacer {
-	return pacer.New().SetMinSleep(minSleep).SetPacer(pacer.GoogleDrivePacer)
+// newPacer makes a pacer configured for drive for the remote name
+func newPacer(name string) *pacer.Pacer {
+	return pacer.New().SetMinSleep(*drivePacerMinSleep).SetMaxSleep(*drivePacerMaxSleep).SetBurst(*drivePacerBurst).SetPacer(pacer.AdaptivePacer).SetName(name)
 }
 
-func getServiceAccountClient(credentialsData []byte) (*http.Client, error) {
-	conf, err := google.JWTConfigFromJSON(credentialsData, driveConfig.Scopes...)
+// driveScopes returns the scopes configured for the remote name
+func driveScopes(name string) (scopes []string) {
+	scopesString := config.FileGet(name, "scope")
+	if scopesString == "" {
+		scopesString = defaultScope
+	}
+	for _, scope := range strings.Split(scopesString, ",") {
+		scopes = append(scopes, scopePrefix+strings.TrimSpace(scope))
+	}
+	return scopes
+}
+
+// driveScopesContainsAppFolder returns true if one of the scopes
+// is drive.appfolder
+func driveScopesContainsAppFolder(scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == scopePrefix+"drive.appfolder" {
+			return true
+		}
+	}
+	return false
+}
+
+// impersonateUser returns the user to impersonate with the service
+// account for the remote name, or "" if none
+func impersonateUser(name string) string {
+	if *driveImpersonate != "" {
+		return *driveImpersonate
+	}
+	return config.FileGet(name, "impersonate")
+}
+
+func getServiceAccountClient(credentialsData []byte, scopes []string, impersonate string) (*http.Client, error) {
+	conf, err := google.JWTConfigFromJSON(credentialsData, scopes...)
 	if err != nil {
 		return nil, errors.Wrap(err, "error processing credentials")
 	}
-	if *driveImpersonate != "" {
-		conf.Subject = *driveImpersonate
+	if impersonate != "" {
+		conf.Subject = impersonate
 	}
 	ctxWithSpecialClient := oauthutil.Context(fshttp.NewClient(fs.Config))
 	return oauth2.NewClient(ctxWithSpecialClient, conf.TokenSource(ctxWithSpecialClient)), nil
@@ -472,12 +580,16 @@ func createOAuthClient(name string) (*http.Client, error) {
 		}
 		serviceAccountCreds = loadedCreds
 	}
+	impersonate := impersonateUser(name)
 	if len(serviceAccountCreds) > 0 {
-		oAuthClient, err = getServiceAccountClient(serviceAccountCreds)
+		oAuthClient, err = getServiceAccountClient(serviceAccountCreds, driveScopes(name), impersonate)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create oauth client from service account")
 		}
 	} else {
+		if impersonate != "" {
+			fs.Logf(nil, "drive: ignoring impersonate %q as it needs a service account - set service_account_file", impersonate)
+		}
 		oAuthClient, _, err = oauthutil.NewClient(name, driveConfig)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create oauth client")
@@ -509,15 +621,19 @@ func NewFs(name, path string) (fs.Fs, error) {
 	f := &Fs{
 		name:  name,
 		root:  root,
-		pacer: newPacer(),
+		pacer: newPacer(name),
 	}
 	f.teamDriveID = config.FileGet(name, "team_drive")
+	if *driveTeamDrive != "" {
+		f.teamDriveID = *driveTeamDrive
+	}
 	f.isTeamDrive = f.teamDriveID != ""
 	f.features = (&fs.Features{
 		DuplicateFiles:          true,
 		ReadMimeType:            true,
 		WriteMimeType:           true,
 		CanHaveEmptyDirectories: true,
+		ServerSideAcrossConfigs: *driveServerSideAcrossConfigs,
 	}).Fill(f)
 
 	// Create a new authorized Drive client.
@@ -542,11 +658,17 @@ func NewFs(name, path string) (fs.Fs, error) {
 	f.dirCache = dircache.New(root, f.rootFolderID, f)
 
 	// Parse extensions
-	err = f.parseExtensions(*driveExtensions)
+	exportExtensions := *driveExportFormats
+	if *driveExtensions != "" {
+		fs.Logf(f, "--drive-formats is deprecated: use --drive-export-formats instead")
+		exportExtensions = *driveExtensions
+	}
+	// make sure there are some sensible ones on there
+	f.exportExtensions, _, err = parseExtensions(exportExtensions, defaultExportExtensions)
 	if err != nil {
 		return nil, err
 	}
-	err = f.parseExtensions(defaultExtensions) // make sure there are some sensible ones on there
+	_, f.importMimeTypes, err = parseExtensions(*driveImportFormats)
 	if err != nil {
 		return nil, err
 	}
@@ -656,36 +778,56 @@ func isAuthOwned(item *drive.File) bool {
 	return false
 }
 
-// exportFormats returns the export formats from drive, fetching them
-// if necessary.
+// fetchFormats fetches the export and import formats from drive if
+// necessary.
 //
-// if the fetch fails then it will not export any drive formats
-func (f *Fs) exportFormats() map[string][]string {
-	exportFormatsOnce.Do(func() {
+// if the fetch fails then it will not export or import any drive
+// formats
+func (f *Fs) fetchFormats() {
+	formatsOnce.Do(func() {
 		var about *drive.About
 		var err error
 		err = f.pacer.Call(func() (bool, error) {
-			about, err = f.svc.About.Get().Fields("exportFormats").Do()
+			about, err = f.svc.About.Get().Fields("exportFormats,importFormats").Do()
 			return shouldRetry(err)
 		})
 		if err != nil {
-			fs.Errorf(f, "Failed to get Drive exportFormats: %v", err)
+			fs.Errorf(f, "Failed to get Drive exportFormats and importFormats: %v", err)
 			_exportFormats = map[string][]string{}
+			_importFormats = map[string][]string{}
 			return
 		}
 		_exportFormats = about.ExportFormats
+		_importFormats = about.ImportFormats
 	})
+}
+
+// exportFormats returns the export formats from drive, fetching them
+// if necessary.
+func (f *Fs) exportFormats() map[string][]string {
+	f.fetchFormats()
 	return _exportFormats
 }
 
+// importFormats returns the import formats from drive, fetching them
+// if necessary.
+func (f *Fs) importFormats() map[string][]string {
+	f.fetchFormats()
+	return _importFormats
+}
+
 // findExportFormat works out the optimum extension and mime-type
 // for this item.
 //
 // Look through the extensions and find the first format that can be
-// converted.  If none found then return "", ""
-func (f *Fs) findExportFormat(filepath string, exportMimeTypes []string) (extension, mimeType string) {
-	for _, extension := range f.extensions {
+// converted.  Link files can be made for any document.  If none
+// found then return "", ""
+func (f *Fs) findExportFormat(exportMimeTypes []string) (extension, mimeType string) {
+	for _, extension := range f.exportExtensions {
 		mimeType := extensionToMimeType[extension]
+		if _, isLink := linkTemplates[mimeType]; isLink {
+			return extension, mimeType
+		}
 		for _, emt := range exportMimeTypes {
 			if emt == mimeType {
 				return extension, mimeType
@@ -697,6 +839,51 @@ func (f *Fs) findExportFormat(filepath string, exportMimeTypes []string) (extens
 	return "", ""
 }
 
+// findImportFormat works out the mime-type of the Google document
+// that remote should be converted into on upload.
+//
+// The file is only converted if its extension was given in
+// --drive-import-formats and drive can import it.  If it shouldn't be
+// converted then return "", ""
+func (f *Fs) findImportFormat(remote string) (srcMimeType, importMimeType string) {
+	extension := strings.ToLower(strings.TrimPrefix(path.Ext(remote), "."))
+	srcMimeType, found := extensionToMimeType[extension]
+	if !found {
+		return "", ""
+	}
+	for _, mimeType := range f.importMimeTypes {
+		if mimeType == srcMimeType {
+			importMimeTypes := f.importFormats()[srcMimeType]
+			if len(importMimeTypes) > 0 {
+				return srcMimeType, importMimeTypes[0]
+			}
+			break
+		}
+	}
+	return "", ""
+}
+
+// newDocumentObject makes an Object for the Google document in info
+// using the first export format which matches.  remote should not
+// have an extension - the one for the export format is added.
+//
+// If the document can't be exported then it returns nil
+func (f *Fs) newDocumentObject(remote string, info *drive.File, exportMimeTypes []string) (*Object, error) {
+	extension, exportMimeType := f.findExportFormat(exportMimeTypes)
+	if extension == "" {
+		return nil, nil
+	}
+	o := &Object{
+		fs:     f,
+		remote: remote + "." + extension,
+	}
+	err := o.setDocumentMetaData(info, exportMimeType)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
 // List the objects and directories in dir into entries.  The
 // entries can be returned in any order but should be for a
 // complete directory.
@@ -745,21 +932,15 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 				break
 			}
 			// If item has export links then it is a google doc
-			extension, exportMimeType := f.findExportFormat(remote, exportMimeTypes)
-			if extension == "" {
-				fs.Debugf(remote, "No export formats found for %q", item.MimeType)
-				break
-			}
-			o, err := f.newObjectWithInfo(remote+"."+extension, item)
+			o, err := f.newDocumentObject(remote, item, exportMimeTypes)
 			if err != nil {
 				iErr = err
 				return true
 			}
-			obj := o.(*Object)
-			obj.url = fmt.Sprintf("%sfiles/%s/export?mimeType=%s", f.svc.BasePath, item.Id, url.QueryEscape(exportMimeType))
-			obj.isDocument = true
-			obj.mimeType = exportMimeType
-			obj.bytes = -1
+			if o == nil {
+				fs.Debugf(remote, "No export formats found for %q", item.MimeType)
+				break
+			}
 			entries = append(entries, o)
 		}
 		return false
@@ -838,24 +1019,47 @@ func (f *Fs) PutUnchecked(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOpt
 		return nil, err
 	}
 
+	// Convert the file into a Google doc if required
+	srcMimeType, importMimeType := f.findImportFormat(remote)
+	contentType := ""
+	if importMimeType != "" {
+		remote = remote[:len(remote)-len(path.Ext(remote))]
+		createInfo.Name = path.Base(remote)
+		createInfo.Description = createInfo.Name
+		createInfo.MimeType = importMimeType
+		contentType = srcMimeType
+	}
+
 	var info *drive.File
 	if size == 0 || size < int64(driveUploadCutoff) {
 		// Make the API request to upload metadata and file data.
 		// Don't retry, return a retry error instead
 		err = f.pacer.CallNoRetry(func() (bool, error) {
-			info, err = f.svc.Files.Create(createInfo).Media(in, googleapi.ContentType("")).Fields(googleapi.Field(partialFields)).SupportsTeamDrives(f.isTeamDrive).Do()
+			info, err = f.svc.Files.Create(createInfo).Media(in, googleapi.ContentType(contentType)).Fields(googleapi.Field(partialFields)).SupportsTeamDrives(f.isTeamDrive).Do()
 			return shouldRetry(err)
 		})
 		if err != nil {
 			return o, err
 		}
 	} else {
+		if contentType == "" {
+			contentType = createInfo.MimeType
+		}
 		// Upload the file in chunks
-		info, err = f.Upload(in, size, createInfo.MimeType, "", createInfo, remote)
+		info, err = f.Upload(in, size, contentType, "", createInfo, remote)
 		if err != nil {
 			return o, err
 		}
 	}
+	if importMimeType != "" {
+		doc, err := f.newDocumentObject(remote, info, f.exportFormats()[info.MimeType])
+		if err != nil {
+			return o, err
+		}
+		if doc != nil {
+			return doc, nil
+		}
+	}
 	o.setMetaData(info)
 	return o, nil
 }
@@ -1058,7 +1262,9 @@ func (f *Fs) CleanUp() error {
 // About gets quota information
 func (f *Fs) About() (*fs.Usage, error) {
 	if f.isTeamDrive {
-		// Teamdrives don't appear to have a usage API so just return empty
+		// Teamdrives don't appear to have a usage API and files
+		// in them don't count against the user's quota so just
+		// return empty rather than the user's My Drive usage
 		return &fs.Usage{}, nil
 	}
 	var about *drive.About
@@ -1129,20 +1335,16 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func (f *Fs) PublicLink(remote string) (link string, err error) {
-	id, err := f.dirCache.FindDir(remote, false)
-	if err == nil {
-		fs.Debugf(f, "attempting to share directory '%s'", remote)
-	} else {
-		fs.Debugf(f, "attempting to share single file '%s'", remote)
-		o := &Object{
-			fs:     f,
-			remote: remote,
-		}
-		if err = o.readMetaData(); err != nil {
-			return
-		}
-		id = o.id
+//
+// Drive doesn't support expiry on "anyone" permissions so expire is
+// ignored.
+func (f *Fs) PublicLink(remote string, expire fs.Duration) (link string, err error) {
+	if expire.IsSet() {
+		fs.Debugf(f, "Ignoring expire %v - not supported by drive", expire)
+	}
+	id, err := f.itemID(remote)
+	if err != nil {
+		return "", err
 	}
 
 	permission := &drive.Permission{
@@ -1151,16 +1353,118 @@ func (f *Fs) PublicLink(remote string) (link string, err error) {
 		Type:               "anyone",
 	}
 
+	// On team drives the organizer may not allow members to share
+	// so check first to give a helpful error
+	if f.isTeamDrive {
+		var info *drive.File
+		err = f.pacer.Call(func() (bool, error) {
+			info, err = f.svc.Files.Get(id).Fields("capabilities(canShare)").SupportsTeamDrives(true).Do()
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read sharing capabilities")
+		}
+		if info.Capabilities == nil || !info.Capabilities.CanShare {
+			return "", errors.Errorf("not allowed to share %q on this team drive", remote)
+		}
+	}
+
 	err = f.pacer.Call(func() (bool, error) {
-		// TODO: On TeamDrives this might fail if lacking permissions to change ACLs.
-		// Need to either check `canShare` attribute on the object or see if a sufficient permission is already present.
 		_, err = f.svc.Permissions.Create(id, permission).Fields(googleapi.Field("id")).SupportsTeamDrives(f.isTeamDrive).Do()
 		return shouldRetry(err)
 	})
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("https://drive.google.com/open?id=%s", id), nil
+	return publicLinkURL(id), nil
+}
+
+// publicLinkURL returns the link to the item with id
+func publicLinkURL(id string) string {
+	return fmt.Sprintf("https://drive.google.com/open?id=%s", id)
+}
+
+// itemID returns the ID of the directory or file at remote
+func (f *Fs) itemID(remote string) (id string, err error) {
+	id, err = f.dirCache.FindDir(remote, false)
+	if err == nil {
+		fs.Debugf(f, "found directory '%s'", remote)
+		return id, nil
+	}
+	fs.Debugf(f, "looking for single file '%s'", remote)
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	if err = o.readMetaData(); err != nil {
+		return "", err
+	}
+	return o.id, nil
+}
+
+// publicPermissions returns the IDs of the "anyone" permissions on
+// the item with id which are the ones PublicLink makes
+func (f *Fs) publicPermissions(id string) (permissionIDs []string, err error) {
+	pageToken := ""
+	for {
+		var list *drive.PermissionList
+		err = f.pacer.Call(func() (bool, error) {
+			list, err = f.svc.Permissions.List(id).PageToken(pageToken).Fields("nextPageToken,permissions(id,type)").SupportsTeamDrives(f.isTeamDrive).Do()
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list permissions")
+		}
+		for _, permission := range list.Permissions {
+			if permission.Type == "anyone" {
+				permissionIDs = append(permissionIDs, permission.Id)
+			}
+		}
+		if list.NextPageToken == "" {
+			return permissionIDs, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// ListPublicLinks returns the link to the given file or folder if it
+// is readable by anyone with the link
+func (f *Fs) ListPublicLinks(remote string) (links []string, err error) {
+	id, err := f.itemID(remote)
+	if err != nil {
+		return nil, err
+	}
+	permissionIDs, err := f.publicPermissions(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissionIDs) > 0 {
+		links = append(links, publicLinkURL(id))
+	}
+	return links, nil
+}
+
+// RemovePublicLinks removes the "readable by anyone with link"
+// permissions from the given file or folder
+func (f *Fs) RemovePublicLinks(remote string) error {
+	id, err := f.itemID(remote)
+	if err != nil {
+		return err
+	}
+	permissionIDs, err := f.publicPermissions(id)
+	if err != nil {
+		return err
+	}
+	for _, permissionID := range permissionIDs {
+		err = f.pacer.Call(func() (bool, error) {
+			err = f.svc.Permissions.Delete(id, permissionID).SupportsTeamDrives(f.isTeamDrive).Do()
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to remove permission")
+		}
+	}
+	return nil
 }
 
 // DirMove moves src, srcRemote to this remote at dstRemote
@@ -1268,25 +1572,30 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 func (f *Fs) ChangeNotify(notifyFunc func(string, fs.EntryType), pollInterval time.Duration) chan bool {
 	quit := make(chan bool)
 	go func() {
-		select {
-		case <-quit:
-			return
-		default:
-			for {
-				f.changeNotifyRunner(notifyFunc, pollInterval)
-				fs.Debugf(f, "Notify listener service ran into issues, restarting shortly.")
-				time.Sleep(pollInterval)
+		for {
+			f.changeNotifyRunner(notifyFunc, pollInterval, quit)
+			select {
+			case <-quit:
+				return
+			case <-time.After(pollInterval):
+				fs.Debugf(f, "Notify listener service ran into issues, restarting.")
 			}
 		}
 	}()
 	return quit
 }
 
-func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInterval time.Duration) {
+// changeNotifyRunner reads the changes list until quit is closed or
+// an error occurs
+func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInterval time.Duration, quit chan bool) {
 	var err error
 	var startPageToken *drive.StartPageToken
 	err = f.pacer.Call(func() (bool, error) {
-		startPageToken, err = f.svc.Changes.GetStartPageToken().SupportsTeamDrives(f.isTeamDrive).Do()
+		changesCall := f.svc.Changes.GetStartPageToken().SupportsTeamDrives(f.isTeamDrive)
+		if f.isTeamDrive {
+			changesCall = changesCall.TeamDriveId(f.teamDriveID)
+		}
+		startPageToken, err = changesCall.Do()
 		return shouldRetry(err)
 	})
 	if err != nil {
@@ -1304,6 +1613,9 @@ func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInter
 			if *driveListChunk > 0 {
 				changesCall = changesCall.PageSize(*driveListChunk)
 			}
+			if f.isTeamDrive {
+				changesCall = changesCall.TeamDriveId(f.teamDriveID).IncludeTeamDriveItems(true)
+			}
 			changeList, err = changesCall.SupportsTeamDrives(f.isTeamDrive).Do()
 			return shouldRetry(err)
 		})
@@ -1363,7 +1675,11 @@ func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInter
 		if changeList.NewStartPageToken != "" {
 			pageToken = changeList.NewStartPageToken
 			fs.Debugf(f, "All changes were processed. Waiting for more.")
-			time.Sleep(pollInterval)
+			select {
+			case <-quit:
+				return
+			case <-time.After(pollInterval):
+			}
 		} else if changeList.NextPageToken != "" {
 			pageToken = changeList.NextPageToken
 			fs.Debugf(f, "There are more changes pending, checking now.")
@@ -1432,6 +1748,34 @@ func (o *Object) setMetaData(info *drive.File) {
 	o.mimeType = info.MimeType
 }
 
+// setDocumentMetaData sets the fs data from a drive.File for a Google
+// document which will be exported as exportMimeType
+func (o *Object) setDocumentMetaData(info *drive.File, exportMimeType string) error {
+	o.setMetaData(info)
+	o.isDocument = true
+	o.mimeType = exportMimeType
+	o.linkContent = nil
+	if linkTemplate, isLink := linkTemplates[exportMimeType]; isLink {
+		var buf bytes.Buffer
+		err := linkTemplate.Execute(&buf, struct {
+			URL, Title string
+		}{
+			URL:   info.WebViewLink,
+			Title: info.Name,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to make link file")
+		}
+		o.url = ""
+		o.linkContent = buf.Bytes()
+		o.bytes = int64(len(o.linkContent))
+		return nil
+	}
+	o.url = fmt.Sprintf("%sfiles/%s/export?mimeType=%s", o.fs.svc.BasePath, info.Id, url.QueryEscape(exportMimeType))
+	o.bytes = -1
+	return nil
+}
+
 // readMetaData gets the info if it hasn't already been fetched
 func (o *Object) readMetaData() (err error) {
 	if o.id != "" {
@@ -1582,6 +1926,14 @@ var _ io.ReadCloser = &openFile{}
 
 // Open an object for read
 func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
+	if o.linkContent != nil {
+		for _, option := range options {
+			if _, ok := option.(*fs.RangeOption); ok {
+				return nil, errors.New("partial downloads are not supported for link files")
+			}
+		}
+		return ioutil.NopCloser(bytes.NewReader(o.linkContent)), nil
+	}
 	_, res, err := o.httpResponse("GET", options)
 	if err != nil {
 		return nil, errors.Wrap(err, "open file failed")
@@ -1604,13 +1956,21 @@ func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
 func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
 	size := src.Size()
 	modTime := src.ModTime()
-	if o.isDocument {
-		return errors.New("can't update a google document")
-	}
 	updateInfo := &drive.File{
 		MimeType:     fs.MimeType(src),
 		ModifiedTime: modTime.Format(timeFormatOut),
 	}
+	contentType := ""
+
+	// Google docs can only be updated by converting an importable file
+	if o.isDocument {
+		srcMimeType, importMimeType := o.fs.findImportFormat(src.Remote())
+		if importMimeType == "" || o.linkContent != nil {
+			return errors.New("can't update a google document")
+		}
+		updateInfo.MimeType = importMimeType
+		contentType = srcMimeType
+	}
 
 	// Make the API request to upload metadata and file data.
 	var err error
@@ -1618,19 +1978,25 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 	if size == 0 || size < int64(driveUploadCutoff) {
 		// Don't retry, return a retry error instead
 		err = o.fs.pacer.CallNoRetry(func() (bool, error) {
-			info, err = o.fs.svc.Files.Update(o.id, updateInfo).Media(in, googleapi.ContentType("")).Fields(googleapi.Field(partialFields)).SupportsTeamDrives(o.fs.isTeamDrive).Do()
+			info, err = o.fs.svc.Files.Update(o.id, updateInfo).Media(in, googleapi.ContentType(contentType)).Fields(googleapi.Field(partialFields)).SupportsTeamDrives(o.fs.isTeamDrive).Do()
 			return shouldRetry(err)
 		})
 		if err != nil {
 			return err
 		}
 	} else {
+		if contentType == "" {
+			contentType = updateInfo.MimeType
+		}
 		// Upload the file in chunks
-		info, err = o.fs.Upload(in, size, updateInfo.MimeType, o.id, updateInfo, o.remote)
+		info, err = o.fs.Upload(in, size, contentType, o.id, updateInfo, o.remote)
 		if err != nil {
 			return err
 		}
 	}
+	if o.isDocument {
+		return o.setDocumentMetaData(info, o.mimeType)
+	}
 	o.setMetaData(info)
 	return nil
 }
@@ -1667,19 +2033,20 @@ func (o *Object) MimeType() string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs              = (*Fs)(nil)
-	_ fs.Purger          = (*Fs)(nil)
-	_ fs.CleanUpper      = (*Fs)(nil)
-	_ fs.PutStreamer     = (*Fs)(nil)
-	_ fs.Copier          = (*Fs)(nil)
-	_ fs.Mover           = (*Fs)(nil)
-	_ fs.DirMover        = (*Fs)(nil)
-	_ fs.DirCacheFlusher = (*Fs)(nil)
-	_ fs.ChangeNotifier  = (*Fs)(nil)
-	_ fs.PutUncheckeder  = (*Fs)(nil)
-	_ fs.PublicLinker    = (*Fs)(nil)
-	_ fs.MergeDirser     = (*Fs)(nil)
-	_ fs.Abouter         = (*Fs)(nil)
-	_ fs.Object          = (*Object)(nil)
-	_ fs.MimeTyper       = (*Object)(nil)
+	_ fs.Fs                = (*Fs)(nil)
+	_ fs.Purger            = (*Fs)(nil)
+	_ fs.CleanUpper        = (*Fs)(nil)
+	_ fs.PutStreamer       = (*Fs)(nil)
+	_ fs.Copier            = (*Fs)(nil)
+	_ fs.Mover             = (*Fs)(nil)
+	_ fs.DirMover          = (*Fs)(nil)
+	_ fs.DirCacheFlusher   = (*Fs)(nil)
+	_ fs.ChangeNotifier    = (*Fs)(nil)
+	_ fs.PutUncheckeder    = (*Fs)(nil)
+	_ fs.PublicLinker      = (*Fs)(nil)
+	_ fs.PublicLinkManager = (*Fs)(nil)
+	_ fs.MergeDirser       = (*Fs)(nil)
+	_ fs.Abouter           = (*Fs)(nil)
+	_ fs.Object            = (*Object)(nil)
+	_ fs.MimeTyper         = (*Object)(nil)
 )
@@ -2,10 +2,13 @@ package drive
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
 
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -70,23 +73,28 @@ func TestInternalParseExtensions(t *testing.T) {
 		{" docx ,XLSX, 	pptx,svg", []string{"docx", "xlsx", "pptx", "svg"}, nil},
 		{"docx,svg,Docx", []string{"docx", "svg"}, nil},
 		{"docx,potato,docx", []string{"docx"}, errors.New(`couldn't find mime type for extension "potato"`)},
+		{"", nil, nil},
+		{"url,link.html", []string{"url", "link.html"}, nil},
 	} {
-		f := new(Fs)
-		gotErr := f.parseExtensions(test.in)
+		extensions, mimeTypes, gotErr := parseExtensions(test.in)
 		if test.wantErr == nil {
 			assert.NoError(t, gotErr)
 		} else {
 			assert.EqualError(t, gotErr, test.wantErr.Error())
 		}
-		assert.Equal(t, test.want, f.extensions)
+		assert.Equal(t, test.want, extensions)
+		assert.Equal(t, len(extensions), len(mimeTypes))
 	}
 
 	// Test it is appending
-	f := new(Fs)
-	assert.Nil(t, f.parseExtensions("docx,svg"))
-	assert.Nil(t, f.parseExtensions("docx,svg,xlsx"))
-	assert.Equal(t, []string{"docx", "svg", "xlsx"}, f.extensions)
-
+	extensions, mimeTypes, err := parseExtensions("docx,svg", "docx,svg,xlsx")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docx", "svg", "xlsx"}, extensions)
+	assert.Equal(t, []string{
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"image/svg+xml",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}, mimeTypes)
 }
 
 func TestInternalFindExportFormat(t *testing.T) {
@@ -102,11 +110,69 @@ func TestInternalFindExportFormat(t *testing.T) {
 		{[]string{"pdf", "rtf", "xls"}, "pdf", "application/pdf"},
 		{[]string{"xls", "rtf", "pdf"}, "rtf", "application/rtf"},
 		{[]string{"xls", "csv", "svg"}, "", ""},
+		{[]string{"xls", "url", "pdf"}, "url", "application/x-link-url"},
 	} {
 		f := new(Fs)
-		f.extensions = test.extensions
-		gotExtension, gotMimeType := f.findExportFormat("file", exportFormats[item.MimeType])
+		f.exportExtensions = test.extensions
+		gotExtension, gotMimeType := f.findExportFormat(exportFormats[item.MimeType])
 		assert.Equal(t, test.wantExtension, gotExtension)
 		assert.Equal(t, test.wantMimeType, gotMimeType)
 	}
 }
+
+func TestInternalLinkTemplates(t *testing.T) {
+	for mimeType, extension := range mimeTypeToExtensionLinks {
+		o := &Object{fs: &Fs{svc: new(drive.Service)}}
+		err := o.setDocumentMetaData(&drive.File{
+			Id:          "id",
+			Name:        "My <Doc>",
+			WebViewLink: "https://docs.google.com/document/d/id/edit?usp=drivesdk&x=1",
+		}, mimeType)
+		assert.NoError(t, err, extension)
+		assert.True(t, o.isDocument)
+		assert.Equal(t, int64(len(o.linkContent)), o.Size())
+		assert.Contains(t, string(o.linkContent), "https://docs.google.com/document/d/id/edit?usp=drivesdk", extension)
+		assert.Equal(t, "", o.url)
+	}
+}
+
+func TestInternalShouldRetry(t *testing.T) {
+	for _, test := range []struct {
+		err             error
+		wantRetry       bool
+		wantRateLimited bool
+	}{
+		{nil, false, false},
+		{errors.New("potato"), false, false},
+		{&googleapi.Error{Code: 500}, true, false},
+		{&googleapi.Error{Code: 404}, false, false},
+		{&googleapi.Error{Code: 429}, true, true},
+		{&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, true, true},
+		{&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, true, true},
+		{&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}}}, false, false},
+	} {
+		gotRetry, gotErr := shouldRetry(test.err)
+		assert.Equal(t, test.wantRetry, gotRetry, "%v", test.err)
+		assert.Equal(t, test.wantRateLimited, fserrors.IsRateLimitError(gotErr), "%v", test.err)
+	}
+
+	// Retry-After is passed on to the pacer
+	_, err := shouldRetry(&googleapi.Error{Code: 429, Header: http.Header{"Retry-After": {"10"}}})
+	assert.True(t, fserrors.IsRateLimitError(err))
+	assert.False(t, fserrors.RetryAfterErrorTime(err).IsZero())
+}
+
+func TestInternalShouldRetryClass(t *testing.T) {
+	for _, test := range []struct {
+		reason string
+		want   fserrors.Class
+	}{
+		{"storageQuotaExceeded", fserrors.ClassQuota},
+		{"insufficientFilePermissions", fserrors.ClassPermission},
+		{"userRateLimitExceeded", fserrors.ClassRateLimited},
+		{"somethingElse", fserrors.ClassOther},
+	} {
+		_, err := shouldRetry(&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: test.reason}}})
+		assert.Equal(t, test.want, fserrors.Classify(err), test.reason)
+	}
+}
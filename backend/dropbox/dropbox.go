@@ -170,6 +170,9 @@ func (f *Fs) Features() *fs.Features {
 
 // shouldRetry returns a boolean as to whether this err deserves to be
 // retried.  It returns the err as a convenience
+//
+// The SDK doesn't pass on the response headers in its errors so any
+// Retry-After header can't be honoured.
 func shouldRetry(err error) (bool, error) {
 	if err == nil {
 		return false, err
@@ -218,7 +221,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		srv:           srv,
 		sharingClient: sharingClient,
 		users:         users,
-		pacer:         pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer:         pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant).SetName(name),
 	}
 	f.features = (&fs.Features{
 		CaseInsensitive:         true,
@@ -628,12 +631,20 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func (f *Fs) PublicLink(remote string) (link string, err error) {
+//
+// Links with an expiry need a Dropbox Professional or Business
+// account.
+func (f *Fs) PublicLink(remote string, expire fs.Duration) (link string, err error) {
 	absPath := "/" + path.Join(f.Root(), remote)
 	fs.Debugf(f, "attempting to share '%s' (absolute path: %s)", remote, absPath)
 	createArg := sharing.CreateSharedLinkWithSettingsArg{
 		Path: absPath,
 	}
+	if expire.IsSet() {
+		createArg.Settings = &sharing.SharedLinkSettings{
+			Expires: time.Now().Add(time.Duration(expire)).UTC().Round(time.Second),
+		}
+	}
 	var linkRes sharing.IsSharedLinkMetadata
 	err = f.pacer.Call(func() (bool, error) {
 		linkRes, err = f.sharingClient.CreateSharedLinkWithSettings(&createArg)
@@ -642,35 +653,91 @@ func (f *Fs) PublicLink(remote string) (link string, err error) {
 
 	if err != nil && strings.Contains(err.Error(), sharing.CreateSharedLinkWithSettingsErrorSharedLinkAlreadyExists) {
 		fs.Debugf(absPath, "has a public link already, attempting to retrieve it")
-		listArg := sharing.ListSharedLinksArg{
-			Path:       absPath,
-			DirectOnly: true,
+		var links []string
+		links, err = f.listSharedLinks(absPath)
+		if err != nil {
+			return
 		}
+		if len(links) == 0 {
+			err = errors.New("Dropbox says the sharing link already exists, but list came back empty")
+			return
+		}
+		return links[0], nil
+	}
+	if err == nil {
+		link, err = sharedLinkURL(linkRes)
+	}
+	return
+}
+
+// sharedLinkURL extracts the URL from the shared link metadata
+func sharedLinkURL(linkRes sharing.IsSharedLinkMetadata) (string, error) {
+	switch res := linkRes.(type) {
+	case *sharing.FileLinkMetadata:
+		return res.Url, nil
+	case *sharing.FolderLinkMetadata:
+		return res.Url, nil
+	}
+	return "", fmt.Errorf("Don't know how to extract link, response has unknown format: %T", linkRes)
+}
+
+// listSharedLinks returns the URLs of the shared links to absPath
+func (f *Fs) listSharedLinks(absPath string) (links []string, err error) {
+	listArg := sharing.ListSharedLinksArg{
+		Path:       absPath,
+		DirectOnly: true,
+	}
+	for {
 		var listRes *sharing.ListSharedLinksResult
 		err = f.pacer.Call(func() (bool, error) {
 			listRes, err = f.sharingClient.ListSharedLinks(&listArg)
 			return shouldRetry(err)
 		})
 		if err != nil {
-			return
+			return nil, err
 		}
-		if len(listRes.Links) == 0 {
-			err = errors.New("Dropbox says the sharing link already exists, but list came back empty")
-			return
+		for _, linkRes := range listRes.Links {
+			link, err := sharedLinkURL(linkRes)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, link)
+		}
+		if !listRes.HasMore || listRes.Cursor == "" {
+			break
 		}
-		linkRes = listRes.Links[0]
+		listArg.Cursor = listRes.Cursor
 	}
-	if err == nil {
-		switch res := linkRes.(type) {
-		case *sharing.FileLinkMetadata:
-			link = res.Url
-		case *sharing.FolderLinkMetadata:
-			link = res.Url
-		default:
-			err = fmt.Errorf("Don't know how to extract link, response has unknown format: %T", res)
+	return links, nil
+}
+
+// ListPublicLinks returns the shared links to the given file or folder
+func (f *Fs) ListPublicLinks(remote string) (links []string, err error) {
+	absPath := "/" + path.Join(f.Root(), remote)
+	return f.listSharedLinks(absPath)
+}
+
+// RemovePublicLinks revokes the shared links to the given file or
+// folder
+func (f *Fs) RemovePublicLinks(remote string) error {
+	absPath := "/" + path.Join(f.Root(), remote)
+	links, err := f.listSharedLinks(absPath)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		revokeArg := sharing.RevokeSharedLinkArg{
+			Url: link,
+		}
+		err = f.pacer.Call(func() (bool, error) {
+			err = f.sharingClient.RevokeSharedLink(&revokeArg)
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to revoke link")
 		}
 	}
-	return
+	return nil
 }
 
 // DirMove moves src, srcRemote to this remote at dstRemote
@@ -743,6 +810,116 @@ func (f *Fs) About() (usage *fs.Usage, err error) {
 	return usage, nil
 }
 
+// ChangeNotify calls the passed function with a path that has had changes.
+// If the implementation uses polling, it should adhere to the given interval.
+//
+// Automatically restarts itself in case of unexpected behaviour of the remote.
+//
+// Close the returned channel to stop being notified.
+func (f *Fs) ChangeNotify(notifyFunc func(string, fs.EntryType), pollInterval time.Duration) chan bool {
+	quit := make(chan bool)
+	go func() {
+		for {
+			f.changeNotifyRunner(notifyFunc, pollInterval, quit)
+			select {
+			case <-quit:
+				return
+			case <-time.After(pollInterval):
+				fs.Debugf(f, "Notify listener service ran into issues, restarting.")
+			}
+		}
+	}()
+	return quit
+}
+
+// changeNotifyRunner polls for changes under the root using a
+// recursive list_folder cursor until quit is closed or an error
+// occurs
+func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInterval time.Duration, quit chan bool) {
+	var err error
+	var res *files.ListFolderGetLatestCursorResult
+	arg := files.ListFolderArg{
+		Path:      f.slashRoot,
+		Recursive: true,
+	}
+	if arg.Path == "/" {
+		arg.Path = "" // Specify root folder as empty string
+	}
+	err = f.pacer.Call(func() (bool, error) {
+		res, err = f.srv.ListFolderGetLatestCursor(&arg)
+		return shouldRetry(err)
+	})
+	if err != nil {
+		fs.Debugf(f, "Failed to get latest cursor: %v", err)
+		return
+	}
+	cursor := res.Cursor
+	prefix := strings.ToLower(f.slashRootSlash)
+
+	for {
+		select {
+		case <-quit:
+			return
+		case <-time.After(pollInterval):
+		}
+		fs.Debugf(f, "Checking for changes on remote")
+		hasMore := true
+		for hasMore {
+			var changes *files.ListFolderResult
+			err = f.pacer.Call(func() (bool, error) {
+				changes, err = f.srv.ListFolderContinue(&files.ListFolderContinueArg{
+					Cursor: cursor,
+				})
+				return shouldRetry(err)
+			})
+			if err != nil {
+				fs.Debugf(f, "Failed to get changes: %v", err)
+				return
+			}
+			visitedPaths := make(map[string]bool)
+			for _, entry := range changes.Entries {
+				var metadata *files.Metadata
+				isDirectory := false
+				switch info := entry.(type) {
+				case *files.FolderMetadata:
+					metadata = &info.Metadata
+					isDirectory = true
+				case *files.FileMetadata:
+					metadata = &info.Metadata
+				case *files.DeletedMetadata:
+					// we don't know what this was so clear
+					// its parent directory
+					metadata = &info.Metadata
+				default:
+					fs.Debugf(f, "Unknown change type %T", entry)
+					continue
+				}
+				if !strings.HasPrefix(metadata.PathLower, prefix) || len(metadata.PathDisplay) < len(prefix) {
+					continue
+				}
+				remote := metadata.PathDisplay[len(prefix):]
+				entryType := fs.EntryObject
+				if isDirectory {
+					// a new directory needs its parent
+					// listing clearing too
+					remote = path.Dir(remote)
+					if remote == "." {
+						remote = ""
+					}
+					entryType = fs.EntryDirectory
+				}
+				if visitedPaths[remote] {
+					continue
+				}
+				visitedPaths[remote] = true
+				notifyFunc(remote, entryType)
+			}
+			cursor = changes.Cursor
+			hasMore = changes.HasMore
+		}
+	}
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hash.Dropbox)
@@ -1021,13 +1198,15 @@ func (o *Object) Remove() (err error) {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs           = (*Fs)(nil)
-	_ fs.Copier       = (*Fs)(nil)
-	_ fs.Purger       = (*Fs)(nil)
-	_ fs.PutStreamer  = (*Fs)(nil)
-	_ fs.Mover        = (*Fs)(nil)
-	_ fs.PublicLinker = (*Fs)(nil)
-	_ fs.DirMover     = (*Fs)(nil)
-	_ fs.Abouter      = (*Fs)(nil)
-	_ fs.Object       = (*Object)(nil)
+	_ fs.Fs                = (*Fs)(nil)
+	_ fs.Copier            = (*Fs)(nil)
+	_ fs.Purger            = (*Fs)(nil)
+	_ fs.PutStreamer       = (*Fs)(nil)
+	_ fs.Mover             = (*Fs)(nil)
+	_ fs.PublicLinker      = (*Fs)(nil)
+	_ fs.PublicLinkManager = (*Fs)(nil)
+	_ fs.DirMover          = (*Fs)(nil)
+	_ fs.Abouter           = (*Fs)(nil)
+	_ fs.ChangeNotifier    = (*Fs)(nil)
+	_ fs.Object            = (*Object)(nil)
 )
@@ -232,6 +232,7 @@ type Object struct {
 	bytes    int64     // Bytes in the object
 	modTime  time.Time // Modified time of the object
 	mimeType string
+	class    string // storage class of the object
 }
 
 // ------------------------------------------------------------
@@ -332,6 +333,8 @@ func NewFs(name, root string) (fs.Fs, error) {
 		ReadMimeType:  true,
 		WriteMimeType: true,
 		BucketBased:   true,
+		SetTier:       true,
+		GetTier:       true,
 	}).Fill(f)
 	if f.objectACL == "" {
 		f.objectACL = "private"
@@ -748,6 +751,7 @@ func (o *Object) setMetaData(info *storage.Object) {
 	o.url = info.MediaLink
 	o.bytes = int64(info.Size)
 	o.mimeType = info.ContentType
+	o.class = info.StorageClass
 
 	// Read md5sum
 	md5sumData, err := base64.StdEncoding.DecodeString(info.Md5Hash)
@@ -893,6 +897,51 @@ func (o *Object) MimeType() string {
 	return o.mimeType
 }
 
+// GetTier returns the storage class of the object
+func (o *Object) GetTier() string {
+	return o.class
+}
+
+// SetTier changes the storage class of the object by rewriting it
+// over itself
+//
+// The rewrite is given the whole of the existing object resource with
+// only the storage class changed so the metadata and ACL of the
+// object are preserved.
+func (o *Object) SetTier(tier string) error {
+	tier = strings.ToUpper(tier)
+	err := o.readMetaData()
+	if err != nil {
+		return err
+	}
+	if o.class == tier {
+		fs.Debugf(o, "Already has storage class %q", tier)
+		return nil
+	}
+	name := o.fs.root + o.remote
+	object, err := o.fs.svc.Objects.Get(o.fs.bucket, name).Projection("full").Do()
+	if err != nil {
+		return errors.Wrap(err, "failed to read object to set storage class")
+	}
+	object.StorageClass = tier
+	rewriteToken := ""
+	for {
+		call := o.fs.svc.Objects.Rewrite(o.fs.bucket, name, o.fs.bucket, name, object)
+		if rewriteToken != "" {
+			call.RewriteToken(rewriteToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return errors.Wrap(err, "failed to set storage class")
+		}
+		if resp.Done {
+			o.setMetaData(resp.Resource)
+			return nil
+		}
+		rewriteToken = resp.RewriteToken
+	}
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs          = &Fs{}
@@ -901,4 +950,6 @@ var (
 	_ fs.ListRer     = &Fs{}
 	_ fs.Object      = &Object{}
 	_ fs.MimeTyper   = &Object{}
+	_ fs.SetTierer   = &Object{}
+	_ fs.GetTierer   = &Object{}
 )
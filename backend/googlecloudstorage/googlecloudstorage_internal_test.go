@@ -0,0 +1,86 @@
+package googlecloudstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// newTestTierServer returns a server holding the object file in
+// bucket which implements enough of the API to get and rewrite it
+func newTestTierServer(t *testing.T, object *storage.Object, rewrites *[]*storage.Object, acls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/b/bucket/o/file":
+			assert.Equal(t, "full", r.URL.Query().Get("projection"))
+			require.NoError(t, json.NewEncoder(w).Encode(object))
+		case r.Method == "POST" && r.URL.Path == "/b/bucket/o/file/rewriteTo/b/bucket/o/file":
+			var got storage.Object
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			*rewrites = append(*rewrites, &got)
+			*acls = append(*acls, r.URL.Query().Get("destinationPredefinedAcl"))
+			// Make the client go round the rewrite loop once
+			resp := storage.RewriteResponse{Done: r.URL.Query().Get("rewriteToken") != ""}
+			if resp.Done {
+				resp.Resource = &got
+			} else {
+				resp.RewriteToken = "token"
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(&resp))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestSetTier(t *testing.T) {
+	object := &storage.Object{
+		Bucket:       "bucket",
+		Name:         "file",
+		ContentType:  "text/plain",
+		StorageClass: "STANDARD",
+		CacheControl: "no-cache",
+		Metadata:     map[string]string{metaMtime: "2001-02-03T04:05:06.000000000Z", "other": "value"},
+		Acl:          []*storage.ObjectAccessControl{{Entity: "allUsers", Role: "READER"}},
+		Md5Hash:      "1B2M2Y8AsgTpgAcHWDRIvw==",
+		Updated:      "2001-02-03T04:05:06.000Z",
+	}
+	var rewrites []*storage.Object
+	var acls []string
+	server := newTestTierServer(t, object, &rewrites, &acls)
+	defer server.Close()
+
+	svc, err := storage.New(http.DefaultClient)
+	require.NoError(t, err)
+	svc.BasePath = server.URL + "/"
+	f := &Fs{svc: svc, bucket: "bucket", objectACL: "private"}
+	o := &Object{fs: f, remote: "file"}
+	o.setMetaData(object)
+	assert.Equal(t, "STANDARD", o.GetTier())
+
+	// Setting the same class does nothing
+	require.NoError(t, o.SetTier("standard"))
+	assert.Len(t, rewrites, 0)
+
+	require.NoError(t, o.SetTier("nearline"))
+	assert.Equal(t, "NEARLINE", o.GetTier())
+	require.Len(t, rewrites, 2)
+	for i, got := range rewrites {
+		assert.Equal(t, "", acls[i], "must not replace the ACL")
+		assert.Equal(t, "NEARLINE", got.StorageClass)
+		assert.Equal(t, "text/plain", got.ContentType)
+		assert.Equal(t, "no-cache", got.CacheControl)
+		assert.Equal(t, object.Metadata, got.Metadata)
+		require.Len(t, got.Acl, 1)
+		assert.Equal(t, "allUsers", got.Acl[0].Entity)
+	}
+	assert.Equal(t, time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC), o.modTime)
+}
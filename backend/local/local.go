@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path"
 	"path/filepath"
@@ -29,6 +30,14 @@ var (
 	skipSymlinks   = flags.BoolP("skip-links", "", false, "Don't warn about skipped symlinks.")
 	noUTFNorm      = flags.BoolP("local-no-unicode-normalization", "", false, "Don't apply unicode normalization to paths and filenames")
 	noCheckUpdated = flags.BoolP("local-no-check-updated", "", false, "Don't check to see if the files change during upload")
+	noPreallocate  = flags.BoolP("local-no-preallocate", "", false, "Don't preallocate disk space for files being written")
+	noSparse       = flags.BoolP("local-no-sparse", "", false, "Don't make sparse files when writing blocks of zeros")
+)
+
+// Files being written are called this until they are complete
+const (
+	partialPrefix = ".rclone-"
+	partialSuffix = ".partial"
 )
 
 // Constants
@@ -239,6 +248,10 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 
 		for _, fi := range fis {
 			name := fi.Name()
+			if isPartial(name) {
+				// skip files which are still being written
+				continue
+			}
 			mode := fi.Mode()
 			newRemote := path.Join(remote, name)
 			newPath := filepath.Join(fsDirPath, name)
@@ -379,6 +392,11 @@ func (f *Fs) Precision() (precision time.Duration) {
 }
 
 // Read the precision
+//
+// This is measured in the temporary directory rather than the root
+// so no files are made where another transfer might see them.  This
+// means it doesn't measure the filesystem being synced if that is
+// different.
 func (f *Fs) readPrecision() (precision time.Duration) {
 	// Default precision of 1s
 	precision = time.Second
@@ -403,21 +421,23 @@ func (f *Fs) readPrecision() (precision time.Duration) {
 		_ = os.Remove(path) // ignore error
 	}()
 
-	// Find the minimum duration we can detect
-	for duration := time.Duration(1); duration < time.Second; duration *= 10 {
+	// Find the minimum duration we can detect - start from an
+	// even second so that 2s precision (FAT) can be detected
+	base := time.Now().Unix() &^ 1
+	for duration := time.Duration(1); duration <= time.Second; duration *= 10 {
 		// Current time with delta
-		t := time.Unix(time.Now().Unix(), int64(duration))
+		t := time.Unix(base, int64(duration))
 		err := os.Chtimes(path, t, t)
 		if err != nil {
 			// fmt.Println("Failed to Chtimes", err)
-			break
+			return
 		}
 
 		// Read the actual time back
 		fi, err := os.Stat(path)
 		if err != nil {
 			// fmt.Println("Failed to Stat", err)
-			break
+			return
 		}
 
 		// If it matches - have found the precision
@@ -427,7 +447,8 @@ func (f *Fs) readPrecision() (precision time.Duration) {
 			return duration
 		}
 	}
-	return
+	// Couldn't store odd seconds
+	return 2 * time.Second
 }
 
 // Purge deletes all the files and directories
@@ -590,9 +611,12 @@ func (o *Object) Hash(r hash.Type) (string, error) {
 
 	o.fs.objectHashesMu.Lock()
 	hashes := o.hashes
+	_, found := hashes[r]
 	o.fs.objectHashesMu.Unlock()
 
-	if !o.modTime.Equal(oldtime) || oldsize != o.size || hashes == nil {
+	// The hashes read during a transfer may not include r if it
+	// wasn't asked for, eg with --size-only
+	if !o.modTime.Equal(oldtime) || oldsize != o.size || hashes == nil || !found {
 		in, err := os.Open(o.path)
 		if err != nil {
 			return "", errors.Wrap(err, "hash: failed to open")
@@ -758,7 +782,30 @@ func (o *Object) mkdirAll() error {
 	return os.MkdirAll(dir, 0777)
 }
 
+// isPartial returns true if name is a file being written by Update
+func isPartial(name string) bool {
+	return strings.HasPrefix(name, partialPrefix) && strings.HasSuffix(name, partialSuffix)
+}
+
+// createPartial makes a new file in the same directory as dst to
+// write the data to before it is renamed into place
+func createPartial(dst string) (out *os.File, partial string, err error) {
+	dir, _ := getDirFile(dst)
+	for tries := 0; tries < 10; tries++ {
+		partial = filepath.Join(dir, fmt.Sprintf("%s%08x%s", partialPrefix, rand.Uint32(), partialSuffix))
+		out, err = os.OpenFile(partial, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	return out, partial, err
+}
+
 // Update the object from in with modTime and size
+//
+// The data is written to a temporary file in the same directory which
+// is renamed over the object when complete, so the object is never
+// seen partially written.
 func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
 	hashes := hash.Supported
 	for _, option := range options {
@@ -773,26 +820,59 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 		return err
 	}
 
-	out, err := os.OpenFile(o.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	// Calculate the hash of the object we are reading as we go along
+	hash, err := hash.NewMultiHasherTypes(hashes)
 	if err != nil {
 		return err
 	}
+	in = io.TeeReader(in, hash)
 
-	// Calculate the hash of the object we are reading as we go along
-	hash, err := hash.NewMultiHasherTypes(hashes)
+	// Write through symlinks and keep the permissions of any
+	// existing file
+	dst := o.path
+	if target, err := filepath.EvalSymlinks(dst); err == nil {
+		dst = target
+	}
+	out, partial, err := createPartial(dst)
 	if err != nil {
 		return err
 	}
-	in = io.TeeReader(in, hash)
+	if fi, err := os.Stat(dst); err == nil {
+		_ = out.Chmod(fi.Mode().Perm())
+	}
 
-	_, err = io.Copy(out, in)
+	// Reserve the space for the file if we know how big it is,
+	// otherwise leave out blocks of zeros to make a sparse file
+	var w io.Writer = out
+	preallocated := false
+	if size := src.Size(); size > 0 && !*noPreallocate {
+		err = preAllocate(size, out)
+		if err == nil {
+			preallocated = true
+		} else if err != errPreallocateNotSupported {
+			fs.Debugf(o, "Failed to preallocate: %v", err)
+		}
+	}
+	var sparse *sparseWriter
+	if !preallocated && !*noSparse {
+		sparse = newSparseWriter(out)
+		w = sparse
+	}
+
+	_, err = io.Copy(w, in)
+	if err == nil && sparse != nil {
+		err = sparse.Close()
+	}
 	closeErr := out.Close()
 	if err == nil {
 		err = closeErr
 	}
+	if err == nil {
+		err = os.Rename(partial, dst)
+	}
 	if err != nil {
 		fs.Logf(o, "Removing partially written file on error: %v", err)
-		if removeErr := os.Remove(o.path); removeErr != nil {
+		if removeErr := os.Remove(partial); removeErr != nil {
 			fs.Errorf(o, "Failed to remove partially written file: %v", removeErr)
 		}
 		return err
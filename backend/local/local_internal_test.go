@@ -1,8 +1,10 @@
 package local
 
 import (
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -76,3 +78,48 @@ func TestUpdatingCheck(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestSparseWriter(t *testing.T) {
+	fd, err := ioutil.TempFile("", "rclone-sparse-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = fd.Close()
+		_ = os.Remove(fd.Name())
+	}()
+
+	zeros := make([]byte, 4096)
+	w := newSparseWriter(fd)
+	for _, chunk := range [][]byte{[]byte("hello"), zeros, []byte("world"), zeros, {}} {
+		n, err := w.Write(chunk)
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+	require.NoError(t, w.Close())
+
+	got, err := ioutil.ReadFile(fd.Name())
+	require.NoError(t, err)
+	want := append(append(append([]byte("hello"), zeros...), "world"...), zeros...)
+	assert.Equal(t, want, got)
+}
+
+// Test Update writes via a partial file which isn't listed
+func TestUpdatePartial(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	filePath := "sub dir/local test"
+	file1 := r.WriteObject(filePath, "content", time.Now())
+
+	// A partial file left behind isn't listed
+	partial := filepath.Join(r.Fremote.Root(), "sub dir", partialPrefix+"12345678"+partialSuffix)
+	require.NoError(t, ioutil.WriteFile(partial, []byte("partial"), 0600))
+	fstest.CheckItems(t, r.Fremote, file1)
+	require.NoError(t, os.Remove(partial))
+
+	// Overwrite the file and check no partial files are left
+	file2 := r.WriteObject(filePath, "new content", time.Now())
+	fstest.CheckItems(t, r.Fremote, file2)
+	fis, err := ioutil.ReadDir(filepath.Join(r.Fremote.Root(), "sub dir"))
+	require.NoError(t, err)
+	require.Len(t, fis, 1)
+	assert.Equal(t, "local test", fis[0].Name())
+}
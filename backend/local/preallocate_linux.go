@@ -0,0 +1,30 @@
+// Preallocating disk space on linux
+
+//+build linux
+
+package local
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// errPreallocateNotSupported is returned by preAllocate if the OS or
+// the filesystem doesn't support it
+var errPreallocateNotSupported = errors.New("preallocate not supported")
+
+// fallocFlKeepSize leaves the file size unchanged so the file is
+// written in the normal way
+const fallocFlKeepSize = 0x01
+
+// preAllocate reserves size bytes of disk space for out
+func preAllocate(size int64, out *os.File) error {
+	err := syscall.Fallocate(int(out.Fd()), fallocFlKeepSize, 0, size)
+	switch err {
+	case syscall.ENOTSUP, syscall.ENOSYS:
+		return errPreallocateNotSupported
+	}
+	return err
+}
@@ -0,0 +1,20 @@
+// Preallocating disk space on other OSes
+
+//+build !linux
+
+package local
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// errPreallocateNotSupported is returned by preAllocate if the OS or
+// the filesystem doesn't support it
+var errPreallocateNotSupported = errors.New("preallocate not supported")
+
+// preAllocate reserves size bytes of disk space for out
+func preAllocate(size int64, out *os.File) error {
+	return errPreallocateNotSupported
+}
@@ -0,0 +1,61 @@
+// Writing sparse files
+
+package local
+
+import (
+	"io"
+	"os"
+)
+
+// sparseWriter writes to a file, seeking over writes which are all
+// zeros instead of writing them so the filesystem can leave holes.
+type sparseWriter struct {
+	out  *os.File
+	hole bool // set if the last write was skipped
+}
+
+// newSparseWriter makes a sparseWriter writing to out which should
+// be empty
+func newSparseWriter(out *os.File) *sparseWriter {
+	return &sparseWriter{out: out}
+}
+
+// isZero returns true if p is all zeros
+func isZero(p []byte) bool {
+	for _, c := range p {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Write p to the file or seek over it if it is all zeros
+func (w *sparseWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if isZero(p) {
+		_, err = w.out.Seek(int64(len(p)), io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		w.hole = true
+		return len(p), nil
+	}
+	w.hole = false
+	return w.out.Write(p)
+}
+
+// Close sets the length of the file if it ended with a hole.  It
+// doesn't close the underlying file.
+func (w *sparseWriter) Close() error {
+	if !w.hole {
+		return nil
+	}
+	size, err := w.out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return w.out.Truncate(size)
+}
@@ -186,7 +186,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:  name,
 		root:  root,
 		srv:   srv,
-		pacer: pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer: pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant).SetName(name),
 	}
 	f.features = (&fs.Features{
 		DuplicateFiles:          true,
@@ -758,7 +758,12 @@ func (f *Fs) Hashes() hash.Set {
 }
 
 // PublicLink generates a public link to the remote path (usually readable by anyone)
-func (f *Fs) PublicLink(remote string) (link string, err error) {
+//
+// Mega links don't expire so expire is ignored.
+func (f *Fs) PublicLink(remote string, expire fs.Duration) (link string, err error) {
+	if expire.IsSet() {
+		fs.Debugf(f, "Ignoring expire %v - not supported by mega", expire)
+	}
 	root, err := f.findRoot(false)
 	if err != nil {
 		return "", errors.Wrap(err, "PublicLink failed to find root node")
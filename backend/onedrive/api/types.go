@@ -228,3 +228,37 @@ type AsyncOperationStatus struct {
 	PercentageComplete float64 `json:"percentageComplete"` // An float value between 0 and 100 that indicates the percentage complete.
 	Status             string  `json:"status"`             // A string value that maps to an enumeration of possible values about the status of the job. "notStarted | inProgress | completed | updating | failed | deletePending | deleteFailed | waiting"
 }
+
+// CreateShareLinkRequest is the request to create a sharing link
+type CreateShareLinkRequest struct {
+	Type   string     `json:"type"`                         // Link type: "view", "edit" or "embed"
+	Scope  string     `json:"scope,omitempty"`              // Optional. Scope of the link: "anonymous" or "organization"
+	Expiry *Timestamp `json:"expirationDateTime,omitempty"` // Optional. When the link expires - only supported on personal accounts
+}
+
+// SharingLinkFacet groups sharing link-related data on OneDrive items
+type SharingLinkFacet struct {
+	Type   string `json:"type"`   // The type of the link created.
+	Scope  string `json:"scope"`  // The scope of the link represented by this permission.
+	WebURL string `json:"webUrl"` // A URL that opens the item in the browser on the OneDrive website.
+}
+
+// CreateShareLinkResponse is the response from the createLink call
+type CreateShareLinkResponse struct {
+	ID    string           `json:"id"`    // The unique identifier of the permission.
+	Roles []string         `json:"roles"` // The type of permission, eg "read".
+	Link  SharingLinkFacet `json:"link"`  // The sharing link created.
+}
+
+// Permission is a sharing permission on an item
+type Permission struct {
+	ID    string            `json:"id"`             // The unique identifier of the permission.
+	Roles []string          `json:"roles"`          // The type of permission, eg "read".
+	Link  *SharingLinkFacet `json:"link,omitempty"` // Set if this permission is a sharing link.
+}
+
+// PermissionsResponse is returned from the list permissions call
+type PermissionsResponse struct {
+	Value    []Permission `json:"value"`           // The permissions on the item
+	NextLink string       `json:"@odata.nextLink"` // A URL to retrieve the next available page of permissions.
+}
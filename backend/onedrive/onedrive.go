@@ -75,6 +75,11 @@ var (
 	oauthBusinessResource = oauth2.SetAuthURLParam("resource", discoveryServiceURL)
 
 	chunkSize = fs.SizeSuffix(10 * 1024 * 1024)
+
+	// Flags
+	onedrivePacerMinSleep = flags.DurationP("onedrive-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	onedrivePacerMaxSleep = flags.DurationP("onedrive-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	onedrivePacerBurst    = flags.IntP("onedrive-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
 )
 
 // Register with Fs
@@ -297,6 +302,10 @@ var retryErrorCodes = []int{
 
 // shouldRetry returns a boolean as to whether this resp and err
 // deserve to be retried.  It returns the err as a convenience
+//
+// OneDrive throttles with a 429 or a 503 with a Retry-After header
+// and these are marked as rate limited for the pacer.  A full quota or
+// a permissions error is classified so it gives the right exit code.
 func shouldRetry(resp *http.Response, err error) (bool, error) {
 	authRety := false
 
@@ -304,7 +313,19 @@ func shouldRetry(resp *http.Response, err error) (bool, error) {
 		authRety = true
 		fs.Debugf(nil, "Should retry: %v", err)
 	}
-	return authRety || fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), err
+	retry, err := fserrors.RetryAfterHTTP(authRety || fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
+	if retry && resp != nil && (resp.StatusCode == 429 || (resp.StatusCode == 503 && resp.Header.Get("Retry-After") != "")) {
+		err = fserrors.RateLimitError(err)
+	}
+	if !retry && resp != nil {
+		switch resp.StatusCode {
+		case http.StatusInsufficientStorage:
+			err = fserrors.ClassError(err, fserrors.ClassQuota)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			err = fserrors.ClassError(err, fserrors.ClassPermission)
+		}
+	}
+	return retry, err
 }
 
 // readMetaDataForPath reads the metadata from the path
@@ -363,7 +384,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:       name,
 		root:       root,
 		srv:        rest.NewClient(oAuthClient).SetRoot(rootURL),
-		pacer:      pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer:      pacer.New().SetMinSleep(*onedrivePacerMinSleep).SetMaxSleep(*onedrivePacerMaxSleep).SetBurst(*onedrivePacerBurst).SetDecayConstant(decayConstant).SetPacer(pacer.AdaptivePacer).SetName(name),
 		isBusiness: resourceURL != "",
 	}
 	f.features = (&fs.Features{
@@ -946,6 +967,128 @@ func (f *Fs) About() (usage *fs.Usage, err error) {
 	return usage, nil
 }
 
+// PublicLink returns a link for downloading without an account.
+//
+// An expiry can only be set on personal accounts.
+func (f *Fs) PublicLink(remote string, expire fs.Duration) (link string, err error) {
+	id, err := f.itemID(remote)
+	if err != nil {
+		return "", err
+	}
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/items/" + id + "/createLink",
+	}
+	share := api.CreateShareLinkRequest{
+		Type:  "view",
+		Scope: "anonymous",
+	}
+	if expire.IsSet() {
+		expiry := api.Timestamp(time.Now().Add(time.Duration(expire)))
+		share.Expiry = &expiry
+	}
+	var resp *http.Response
+	var result api.CreateShareLinkResponse
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.srv.CallJSON(&opts, &share, &result)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create link")
+	}
+	return result.Link.WebURL, nil
+}
+
+// itemID returns the ID of the directory or file at remote
+func (f *Fs) itemID(remote string) (id string, err error) {
+	id, err = f.dirCache.FindDir(remote, false)
+	if err == nil {
+		fs.Debugf(f, "found directory '%s'", remote)
+		return id, nil
+	}
+	fs.Debugf(f, "looking for single file '%s'", remote)
+	o, err := f.NewObject(remote)
+	if err != nil {
+		return "", err
+	}
+	return o.(*Object).id, nil
+}
+
+// linkPermissions returns the permissions on the item with id which
+// are sharing links
+func (f *Fs) linkPermissions(id string) (permissions []api.Permission, err error) {
+	opts := rest.Opts{
+		Method: "GET",
+		Path:   "/items/" + id + "/permissions",
+	}
+	for {
+		var resp *http.Response
+		var result api.PermissionsResponse
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err = f.srv.CallJSON(&opts, nil, &result)
+			return shouldRetry(resp, err)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list permissions")
+		}
+		for _, permission := range result.Value {
+			if permission.Link != nil {
+				permissions = append(permissions, permission)
+			}
+		}
+		if result.NextLink == "" {
+			break
+		}
+		opts.Path = ""
+		opts.RootURL = result.NextLink
+	}
+	return permissions, nil
+}
+
+// ListPublicLinks returns the sharing links to the given file or folder
+func (f *Fs) ListPublicLinks(remote string) (links []string, err error) {
+	id, err := f.itemID(remote)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := f.linkPermissions(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range permissions {
+		links = append(links, permission.Link.WebURL)
+	}
+	return links, nil
+}
+
+// RemovePublicLinks deletes the sharing links to the given file or
+// folder
+func (f *Fs) RemovePublicLinks(remote string) error {
+	id, err := f.itemID(remote)
+	if err != nil {
+		return err
+	}
+	permissions, err := f.linkPermissions(id)
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		opts := rest.Opts{
+			Method:     "DELETE",
+			Path:       "/items/" + id + "/permissions/" + permission.ID,
+			NoResponse: true,
+		}
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err := f.srv.Call(&opts)
+			return shouldRetry(resp, err)
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to remove link")
+		}
+	}
+	return nil
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	if f.isBusiness {
@@ -1046,7 +1189,7 @@ func (o *Object) readMetaData() (err error) {
 	}
 	info, _, err := o.fs.readMetaDataForPath(o.srvPath())
 	if err != nil {
-		if apiErr, ok := err.(*api.Error); ok {
+		if apiErr, ok := errors.Cause(err).(*api.Error); ok {
 			if apiErr.ErrorInfo.Code == "itemNotFound" {
 				return fs.ErrorObjectNotFound
 			}
@@ -1309,8 +1452,10 @@ var (
 	_ fs.Copier = (*Fs)(nil)
 	_ fs.Mover  = (*Fs)(nil)
 	// _ fs.DirMover = (*Fs)(nil)
-	_ fs.DirCacheFlusher = (*Fs)(nil)
-	_ fs.Abouter         = (*Fs)(nil)
-	_ fs.Object          = (*Object)(nil)
-	_ fs.MimeTyper       = &Object{}
+	_ fs.DirCacheFlusher   = (*Fs)(nil)
+	_ fs.Abouter           = (*Fs)(nil)
+	_ fs.PublicLinker      = (*Fs)(nil)
+	_ fs.PublicLinkManager = (*Fs)(nil)
+	_ fs.Object            = (*Object)(nil)
+	_ fs.MimeTyper         = &Object{}
 )
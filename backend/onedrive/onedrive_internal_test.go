@@ -0,0 +1,41 @@
+package onedrive
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ncw/rclone/lib/pacer"
+	"github.com/ncw/rclone/lib/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkPermissionsPaging(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/items/id/permissions":
+			_, _ = w.Write([]byte(`{"value": [{"id": "1", "link": {"webUrl": "https://one"}}, {"id": "2", "roles": ["owner"]}], "@odata.nextLink": "` + server.URL + `/next"}`))
+		case "/next":
+			_, _ = w.Write([]byte(`{"value": [{"id": "3", "link": {"webUrl": "https://three"}}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	f := &Fs{
+		srv:   rest.NewClient(http.DefaultClient).SetRoot(server.URL),
+		pacer: pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+	}
+
+	permissions, err := f.linkPermissions("id")
+	require.NoError(t, err)
+	require.Len(t, permissions, 2)
+	assert.Equal(t, "1", permissions[0].ID)
+	assert.Equal(t, "https://one", permissions[0].Link.WebURL)
+	assert.Equal(t, "3", permissions[1].ID)
+	assert.Equal(t, "https://three", permissions[1].Link.WebURL)
+}
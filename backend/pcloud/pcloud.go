@@ -23,6 +23,7 @@ import (
 	"github.com/ncw/rclone/backend/pcloud/api"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/config/obscure"
 	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
@@ -57,6 +58,11 @@ var (
 		ClientSecret: obscure.MustReveal(rcloneEncryptedClientSecret),
 		RedirectURL:  oauthutil.RedirectLocalhostURL,
 	}
+
+	// Flags
+	pcloudPacerMinSleep = flags.DurationP("pcloud-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	pcloudPacerMaxSleep = flags.DurationP("pcloud-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	pcloudPacerBurst    = flags.IntP("pcloud-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
 )
 
 // Register with Fs
@@ -166,7 +172,7 @@ func shouldRetry(resp *http.Response, err error) (bool, error) {
 		doRetry = true
 		fs.Debugf(nil, "Should retry: %v", err)
 	}
-	return doRetry || fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), err
+	return fserrors.RetryAfterHTTP(doRetry || fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
 }
 
 // substitute reserved characters for pcloud
@@ -240,7 +246,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:  name,
 		root:  root,
 		srv:   rest.NewClient(oAuthClient).SetRoot(rootURL),
-		pacer: pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer: pacer.New().SetMinSleep(*pcloudPacerMinSleep).SetMaxSleep(*pcloudPacerMaxSleep).SetBurst(*pcloudPacerBurst).SetDecayConstant(decayConstant).SetName(name),
 	}
 	f.features = (&fs.Features{
 		CaseInsensitive:         false,
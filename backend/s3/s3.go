@@ -21,12 +21,14 @@ import (
 	"net/http"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/corehandlers"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
@@ -39,9 +41,11 @@ import (
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/fshttp"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/pacer"
 	"github.com/ncw/rclone/lib/rest"
 	"github.com/ncw/swift"
 	"github.com/pkg/errors"
@@ -51,7 +55,7 @@ import (
 func init() {
 	fs.Register(&fs.RegInfo{
 		Name:        "s3",
-		Description: "Amazon S3 Compliant Storage Providers (AWS, Ceph, Dreamhost, IBM COS, Minio)",
+		Description: "Amazon S3 Compliant Storage Providers (AWS, Alibaba, Ceph, Dreamhost, IBM COS, Minio)",
 		NewFs:       NewFs,
 		Options: []fs.Option{{
 			Name: fs.ConfigProvider,
@@ -59,6 +63,9 @@ func init() {
 			Examples: []fs.OptionExample{{
 				Value: "AWS",
 				Help:  "Amazon Web Services (AWS) S3",
+			}, {
+				Value: "Alibaba",
+				Help:  "Alibaba Cloud Object Storage System (OSS) formerly Aliyun",
 			}, {
 				Value: "Ceph",
 				Help:  "Ceph Object Storage",
@@ -74,6 +81,9 @@ func init() {
 			}, {
 				Value: "Minio",
 				Help:  "Minio Object Storage",
+			}, {
+				Value: "Scaleway",
+				Help:  "Scaleway Object Storage",
 			}, {
 				Value: "Wasabi",
 				Help:  "Wasabi Object Storage",
@@ -290,6 +300,34 @@ func init() {
 				Value:    "s3.wasabisys.com",
 				Help:     "Wasabi Object Storage",
 				Provider: "Wasabi",
+			}, {
+				Value:    "oss-cn-hangzhou.aliyuncs.com",
+				Help:     "Alibaba East China 1 (Hangzhou)",
+				Provider: "Alibaba",
+			}, {
+				Value:    "oss-cn-shanghai.aliyuncs.com",
+				Help:     "Alibaba East China 2 (Shanghai)",
+				Provider: "Alibaba",
+			}, {
+				Value:    "oss-cn-beijing.aliyuncs.com",
+				Help:     "Alibaba North China 2 (Beijing)",
+				Provider: "Alibaba",
+			}, {
+				Value:    "oss-us-west-1.aliyuncs.com",
+				Help:     "Alibaba US West 1 (Silicon Valley)",
+				Provider: "Alibaba",
+			}, {
+				Value:    "oss-eu-central-1.aliyuncs.com",
+				Help:     "Alibaba Central Europe 1 (Frankfurt)",
+				Provider: "Alibaba",
+			}, {
+				Value:    "s3.nl-ams.scw.cloud",
+				Help:     "Scaleway Amsterdam Endpoint",
+				Provider: "Scaleway",
+			}, {
+				Value:    "s3.fr-par.scw.cloud",
+				Help:     "Scaleway Paris Endpoint",
+				Provider: "Scaleway",
 			}},
 		}, {
 			Name:     "location_constraint",
@@ -491,6 +529,33 @@ func init() {
 			Name:     "server_side_encryption",
 			Help:     "The server-side encryption algorithm used when storing this object in S3.",
 			Provider: "AWS",
+			Examples: []fs.OptionExample{{
+				Value: "",
+				Help:  "None",
+			}, {
+				Value: "AES256",
+				Help:  "AES256",
+			}, {
+				Value: "aws:kms",
+				Help:  "aws:kms",
+			}},
+		}, {
+			Name:     "sse_kms_key_id",
+			Help:     "If using KMS ID you must provide the ARN of Key.",
+			Provider: "AWS",
+			Optional: true,
+			Examples: []fs.OptionExample{{
+				Value: "",
+				Help:  "None",
+			}, {
+				Value: "arn:aws:kms:us-east-1:*",
+				Help:  "arn:aws:kms:*",
+			}},
+		}, {
+			Name:     "sse_customer_algorithm",
+			Help:     "If using SSE-C, the server-side encryption algorithm used when storing this object in S3.",
+			Provider: "AWS,Ceph,Minio",
+			Optional: true,
 			Examples: []fs.OptionExample{{
 				Value: "",
 				Help:  "None",
@@ -498,6 +563,16 @@ func init() {
 				Value: "AES256",
 				Help:  "AES256",
 			}},
+		}, {
+			Name:     "sse_customer_key",
+			Help:     "If using SSE-C you must provide the secret encryption key used to encrypt/decrypt your data.\nThis should be 32 bytes for AES256. Leave blank if using sse_customer_key_base64.",
+			Provider: "AWS,Ceph,Minio",
+			Optional: true,
+		}, {
+			Name:     "sse_customer_key_base64",
+			Help:     "If using SSE-C you may provide the secret encryption key encoded in base64 format instead of sse_customer_key.",
+			Provider: "AWS,Ceph,Minio",
+			Optional: true,
 		}, {
 			Name:     "storage_class",
 			Help:     "The storage class to use when storing objects in S3.",
@@ -518,20 +593,52 @@ func init() {
 				Value: "ONEZONE_IA",
 				Help:  "One Zone Infrequent Access storage class",
 			}},
+		}, {
+			Name:     "list_version",
+			Help:     "Version of ListObjects to use: 1, 2 or 0 for auto.\nLeave blank to use the default for the provider.",
+			Optional: true,
+			Examples: []fs.OptionExample{{
+				Value: "0",
+				Help:  "Auto - use the default for the provider",
+			}, {
+				Value: "1",
+				Help:  "ListObjects - the original version, supported everywhere",
+			}, {
+				Value: "2",
+				Help:  "ListObjectsV2 - needed for some very large buckets",
+			}},
+		}, {
+			Name:     "signature_version",
+			Help:     "Version of the signatures to sign requests with: 2, 4 or 0 for auto.\nLeave blank to use the default for the provider.",
+			Optional: true,
+			Examples: []fs.OptionExample{{
+				Value: "0",
+				Help:  "Auto - use the default for the provider",
+			}, {
+				Value: "2",
+				Help:  "v2 signatures - only use this if v4 signatures don't work, eg pre Jewel/v10 CEPH",
+			}, {
+				Value: "4",
+				Help:  "v4 signatures - supported by AWS and most other providers",
+			}},
 		},
 		},
 	})
 	flags.VarP(&s3ChunkSize, "s3-chunk-size", "", "Chunk size to use for uploading")
+	flags.VarP(&s3CleanupMaxAge, "s3-cleanup-max-age", "", "Abort incomplete multipart uploads older than this with cleanup")
 }
 
 // Constants
 const (
-	metaMtime      = "Mtime"                       // the meta key to store mtime in - eg X-Amz-Meta-Mtime
-	metaMD5Hash    = "Md5chksum"                   // the meta key to store md5hash in
-	listChunkSize  = 1000                          // number of items to read at once
-	maxRetries     = 10                            // number of retries to make of operations
-	maxSizeForCopy = 5 * 1024 * 1024 * 1024        // The maximum size of object we can COPY
-	maxFileSize    = 5 * 1024 * 1024 * 1024 * 1024 // largest possible upload file size
+	metaMtime      = "Mtime"                         // the meta key to store mtime in - eg X-Amz-Meta-Mtime
+	metaMD5Hash    = "Md5chksum"                     // the meta key to store md5hash in
+	listChunkSize  = 1000                            // number of items to read at once
+	maxRetries     = 10                              // number of retries to make of operations
+	maxSizeForCopy = 5 * 1024 * 1024 * 1024          // The maximum size of object we can COPY
+	maxFileSize    = 5 * 1024 * 1024 * 1024 * 1024   // largest possible upload file size
+	maxExpire      = fs.Duration(7 * 24 * time.Hour) // longest a presigned URL can be valid for
+	minSleep       = 0                               // calls aren't paced unless we are rate limited
+	maxSleep       = 2 * time.Second                 // longest time to sleep between calls when retrying
 )
 
 // Globals
@@ -541,8 +648,73 @@ var (
 	s3StorageClass    = flags.StringP("s3-storage-class", "", "", "Storage class to use when uploading S3 objects (STANDARD|REDUCED_REDUNDANCY|STANDARD_IA|ONEZONE_IA)")
 	s3ChunkSize       = fs.SizeSuffix(s3manager.MinUploadPartSize)
 	s3DisableChecksum = flags.BoolP("s3-disable-checksum", "", false, "Don't store MD5 checksum with object metadata")
+	s3RequesterPays   = flags.BoolP("s3-requester-pays", "", false, "Enables requester pays option when interacting with the bucket")
+	s3CleanupMaxAge   = fs.Duration(24 * time.Hour)
+	s3Versions        = flags.BoolP("s3-versions", "", false, "Include old versions in directory listings")
+	s3VersionAt       = flags.StringP("s3-version-at", "", "", "Show the bucket as it was at the time given, eg \"2018-06-01 12:00:00\" or \"2d\" for 2 days ago")
+	s3PacerMinSleep   = flags.DurationP("s3-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	s3PacerMaxSleep   = flags.DurationP("s3-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	s3PacerBurst      = flags.IntP("s3-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
 )
 
+// providerQuirks describes the ways in which an S3 provider differs
+// from AWS
+type providerQuirks struct {
+	listVersion      int  // version of ListObjects to use
+	virtualHostStyle bool // address buckets as bucket.endpoint rather than endpoint/bucket
+	maxUploadParts   int  // maximum number of parts in a multipart upload
+	signatureVersion int  // version of the signatures to sign requests with - 2 or 4
+}
+
+// getQuirks returns the quirks for the provider named in the config
+// for remote name, letting the user override them
+func getQuirks(name string) (quirks providerQuirks, err error) {
+	quirks = providerQuirks{
+		listVersion:      2,
+		maxUploadParts:   s3manager.MaxUploadParts,
+		signatureVersion: 4,
+	}
+	switch provider := config.FileGet(name, fs.ConfigProvider); provider {
+	case "AWS", "DigitalOcean", "Dreamhost", "Minio", "Wasabi":
+		// No quirks
+	case "Alibaba":
+		// OSS only supports virtual host style addressing
+		quirks.virtualHostStyle = true
+		quirks.listVersion = 1
+	case "Ceph", "IBMCOS":
+		// Older versions don't support ListObjectsV2
+		quirks.listVersion = 1
+	case "Scaleway":
+		// Scaleway can only have 1000 parts in an upload
+		quirks.maxUploadParts = 1000
+	default:
+		// Be conservative with "Other" and with old configs
+		// which don't have a provider
+		quirks.listVersion = 1
+	}
+	listVersion := config.FileGet(name, "list_version")
+	switch listVersion {
+	case "", "0":
+	case "1", "2":
+		quirks.listVersion, _ = strconv.Atoi(listVersion)
+	default:
+		return quirks, errors.Errorf("list_version must be 0, 1 or 2 not %q", listVersion)
+	}
+	if config.FileGet(name, "region") == "other-v2-signature" {
+		// Old way of asking for v2 signatures
+		quirks.signatureVersion = 2
+	}
+	signatureVersion := config.FileGet(name, "signature_version")
+	switch signatureVersion {
+	case "", "0":
+	case "2", "4":
+		quirks.signatureVersion, _ = strconv.Atoi(signatureVersion)
+	default:
+		return quirks, errors.Errorf("signature_version must be 0, 2 or 4 not %q", signatureVersion)
+	}
+	return quirks, nil
+}
+
 // Fs represents a remote s3 server
 type Fs struct {
 	name               string           // the name of the remote
@@ -557,7 +729,14 @@ type Fs struct {
 	acl                string           // ACL for new buckets / objects
 	locationConstraint string           // location constraint of new buckets
 	sse                string           // the type of server-side encryption
+	sseKMSKeyID        string           // the KMS key ID if using SSE-KMS
+	sseCustomerAlgo    string           // the algorithm if using SSE-C
+	sseCustomerKey     string           // the raw customer key if using SSE-C
 	storageClass       string           // storage class
+	quirks             providerQuirks   // the ways the provider differs from AWS
+	versions           bool             // list old versions too
+	versionAt          time.Time        // if set show the bucket as it was at this time
+	pacer              *pacer.Pacer     // To pace the API calls
 }
 
 // Object describes a s3 object
@@ -573,6 +752,9 @@ type Object struct {
 	lastModified time.Time          // Last modified
 	meta         map[string]*string // The object metadata if known - may be nil
 	mimeType     string             // MimeType of object - may be ""
+	storageClass string             // eg GLACIER - may be "" for STANDARD
+	versionID    *string            // version of the object if not the latest - may be nil
+	versionKey   string             // the real key if remote has a version suffix - may be ""
 }
 
 // ------------------------------------------------------------
@@ -618,8 +800,57 @@ func s3ParsePath(path string) (bucket, directory string, err error) {
 	return
 }
 
+// isRateLimit returns true if err is the remote asking us to slow down
+//
+// AWS returns a 503 with a SlowDown code, other providers may return
+// a 429.  Other 503 errors aren't rate limiting so are left to the
+// SDK to retry.
+func isRateLimit(err error) bool {
+	err = errors.Cause(err)
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "SlowDown" {
+		return true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusTooManyRequests {
+		return true
+	}
+	return false
+}
+
+// shouldRetry returns a boolean as to whether this err deserves to be
+// retried by the pacer.  It returns the err as a convenience.
+//
+// The SDK retries most errors itself, so only rate limiting errors,
+// which the SDK leaves to the pacer, are retried here.  Permission
+// errors are classified so they give the right exit code.
+func shouldRetry(err error) (bool, error) {
+	if isRateLimit(err) {
+		return true, fserrors.RateLimitError(err)
+	}
+	if awsErr, ok := errors.Cause(err).(awserr.Error); ok && awsErr.Code() == "AccessDenied" {
+		return false, fserrors.ClassError(err, fserrors.ClassPermission)
+	}
+	return false, err
+}
+
+// retryer is the SDK Retryer used for the s3 connection
+//
+// It retries errors in the same way as the SDK does except for rate
+// limiting errors which are left to the pacer so it can slow down all
+// the calls to the remote, not just the one which failed.
+type retryer struct {
+	client.DefaultRetryer
+}
+
+// ShouldRetry returns true if the SDK should retry r
+func (r retryer) ShouldRetry(req *request.Request) bool {
+	if isRateLimit(req.Error) {
+		return false
+	}
+	return r.DefaultRetryer.ShouldRetry(req)
+}
+
 // s3Connection makes a connection to s3
-func s3Connection(name string) (*s3.S3, *session.Session, error) {
+func s3Connection(name string, quirks *providerQuirks) (*s3.S3, *session.Session, error) {
 	// Make the auth
 	v := credentials.Value{
 		AccessKeyID:     config.FileGet(name, "access_key_id"),
@@ -684,11 +915,19 @@ func s3Connection(name string) (*s3.S3, *session.Session, error) {
 		WithCredentials(cred).
 		WithEndpoint(endpoint).
 		WithHTTPClient(fshttp.NewClient(fs.Config)).
-		WithS3ForcePathStyle(true)
+		WithS3ForcePathStyle(!quirks.virtualHostStyle)
+	request.WithRetryer(awsConfig, retryer{client.DefaultRetryer{NumMaxRetries: maxRetries}})
 	// awsConfig.WithLogLevel(aws.LogDebugWithSigning)
 	ses := session.New()
 	c := s3.New(ses, awsConfig)
-	if region == "other-v2-signature" {
+	// A rate limiting error the SDK isn't going to retry goes back
+	// to the pacer so pass on any Retry-After header with it
+	c.Handlers.AfterRetry.PushBack(func(r *request.Request) {
+		if r.Error != nil && r.HTTPResponse != nil && isRateLimit(r.Error) {
+			_, r.Error = fserrors.RetryAfterHTTP(true, r.HTTPResponse, r.Error)
+		}
+	})
+	if quirks.signatureVersion == 2 {
 		fs.Debugf(name, "Using v2 auth")
 		signer := func(req *request.Request) {
 			// Ignore AnonymousCredentials object
@@ -710,7 +949,11 @@ func NewFs(name, root string) (fs.Fs, error) {
 	if err != nil {
 		return nil, err
 	}
-	c, ses, err := s3Connection(name)
+	quirks, err := getQuirks(name)
+	if err != nil {
+		return nil, err
+	}
+	c, ses, err := s3Connection(name, &quirks)
 	if err != nil {
 		return nil, err
 	}
@@ -723,12 +966,23 @@ func NewFs(name, root string) (fs.Fs, error) {
 		root:               directory,
 		locationConstraint: config.FileGet(name, "location_constraint"),
 		sse:                config.FileGet(name, "server_side_encryption"),
+		sseKMSKeyID:        config.FileGet(name, "sse_kms_key_id"),
+		sseCustomerAlgo:    config.FileGet(name, "sse_customer_algorithm"),
+		sseCustomerKey:     config.FileGet(name, "sse_customer_key"),
 		storageClass:       config.FileGet(name, "storage_class"),
+		quirks:             quirks,
+		pacer:              pacer.New().SetMinSleep(*s3PacerMinSleep).SetMaxSleep(*s3PacerMaxSleep).SetBurst(*s3PacerBurst).SetPacer(pacer.AdaptivePacer).SetName(name),
+	}
+	err = f.setEncryption(config.FileGet(name, "sse_customer_key_base64"))
+	if err != nil {
+		return nil, err
 	}
 	f.features = (&fs.Features{
 		ReadMimeType:  true,
 		WriteMimeType: true,
 		BucketBased:   true,
+		SetTier:       true,
+		GetTier:       true,
 	}).Fill(f)
 	if *s3ACL != "" {
 		f.acl = *s3ACL
@@ -736,6 +990,16 @@ func NewFs(name, root string) (fs.Fs, error) {
 	if *s3StorageClass != "" {
 		f.storageClass = *s3StorageClass
 	}
+	f.versions = *s3Versions
+	if *s3VersionAt != "" {
+		if f.versions {
+			return nil, errors.New("can't use --s3-versions with --s3-version-at")
+		}
+		f.versionAt, err = parseVersionAt(*s3VersionAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "--s3-version-at")
+		}
+	}
 	if s3ChunkSize < fs.SizeSuffix(s3manager.MinUploadPartSize) {
 		return nil, errors.Errorf("s3 chunk size must be >= %v", fs.SizeSuffix(s3manager.MinUploadPartSize))
 	}
@@ -743,10 +1007,15 @@ func NewFs(name, root string) (fs.Fs, error) {
 		f.root += "/"
 		// Check to see if the object exists
 		req := s3.HeadObjectInput{
-			Bucket: &f.bucket,
-			Key:    &directory,
+			Bucket:       &f.bucket,
+			Key:          &directory,
+			RequestPayer: f.requestPayer(),
 		}
-		_, err = f.c.HeadObject(&req)
+		req.SSECustomerAlgorithm, req.SSECustomerKey = f.sseCustomer()
+		err = f.pacer.Call(func() (bool, error) {
+			_, err = f.c.HeadObject(&req)
+			return shouldRetry(err)
+		})
 		if err == nil {
 			f.root = path.Dir(directory)
 			if f.root == "." {
@@ -762,14 +1031,77 @@ func NewFs(name, root string) (fs.Fs, error) {
 	return f, nil
 }
 
+// setEncryption checks the server side encryption config for
+// consistency, decoding the base64 SSE-C key if supplied
+func (f *Fs) setEncryption(customerKeyBase64 string) error {
+	if customerKeyBase64 != "" {
+		if f.sseCustomerKey != "" {
+			return errors.New("s3: can't use sse_customer_key and sse_customer_key_base64 at the same time")
+		}
+		key, err := base64.StdEncoding.DecodeString(customerKeyBase64)
+		if err != nil {
+			return errors.Wrap(err, "s3: couldn't decode sse_customer_key_base64")
+		}
+		f.sseCustomerKey = string(key)
+	}
+	if f.sseKMSKeyID != "" {
+		if f.sse == "" {
+			f.sse = "aws:kms"
+		} else if f.sse != "aws:kms" {
+			return errors.Errorf("s3: sse_kms_key_id needs server_side_encryption = aws:kms not %q", f.sse)
+		}
+	}
+	if f.sseCustomerKey != "" {
+		if f.sse != "" {
+			return errors.New("s3: can't use server_side_encryption with an SSE-C key")
+		}
+		if f.sseCustomerAlgo == "" {
+			f.sseCustomerAlgo = s3.ServerSideEncryptionAes256
+		}
+	} else if f.sseCustomerAlgo != "" {
+		return errors.New("s3: sse_customer_algorithm needs sse_customer_key to be set")
+	}
+	return nil
+}
+
+// requestPayer returns the RequestPayer value to pass on requests
+// if --s3-requester-pays is set or nil otherwise
+func (f *Fs) requestPayer() *string {
+	if *s3RequesterPays {
+		return aws.String(s3.RequestPayerRequester)
+	}
+	return nil
+}
+
+// sseCustomer returns the SSE-C algorithm and key to send with each
+// request for an object, or nils if SSE-C isn't in use
+func (f *Fs) sseCustomer() (algorithm, key *string) {
+	if f.sseCustomerKey == "" {
+		return nil, nil
+	}
+	return &f.sseCustomerAlgo, &f.sseCustomerKey
+}
+
+// etagIsNotMD5 returns true if the ETags of objects uploaded with
+// the current encryption settings won't be the MD5 of the content
+func (f *Fs) etagIsNotMD5() bool {
+	return f.sse == "aws:kms" || f.sseCustomerKey != ""
+}
+
 // Return an Object from a path
 //
 //If it can't be found it returns the error ErrorObjectNotFound.
-func (f *Fs) newObjectWithInfo(remote string, info *s3.Object) (fs.Object, error) {
+func (f *Fs) newObjectWithInfo(remote string, info *s3.Object, version *objectVersion) (fs.Object, error) {
 	o := &Object{
 		fs:     f,
 		remote: remote,
 	}
+	if version != nil {
+		o.versionID = version.id
+		if version.key != f.root+remote {
+			o.versionKey = version.key
+		}
+	}
 	if info != nil {
 		// Set info but not meta
 		if info.LastModified == nil {
@@ -780,6 +1112,7 @@ func (f *Fs) newObjectWithInfo(remote string, info *s3.Object) (fs.Object, error
 		}
 		o.etag = aws.StringValue(info.ETag)
 		o.bytes = aws.Int64Value(info.Size)
+		o.storageClass = aws.StringValue(info.StorageClass)
 	} else {
 		err := o.readMetaData() // reads info and meta, returning an error
 		if err != nil {
@@ -792,11 +1125,58 @@ func (f *Fs) newObjectWithInfo(remote string, info *s3.Object) (fs.Object, error
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error fs.ErrorObjectNotFound.
 func (f *Fs) NewObject(remote string) (fs.Object, error) {
-	return f.newObjectWithInfo(remote, nil)
+	if f.versionMode() {
+		return f.newObjectVersion(remote)
+	}
+	return f.newObjectWithInfo(remote, nil, nil)
+}
+
+// listObjects lists one chunk of objects using the version of
+// ListObjects the provider supports.
+//
+// For ListObjectsV2 the Marker in req is used as the continuation
+// token and the NextMarker in the response is set to the next
+// continuation token.
+func (f *Fs) listObjects(req *s3.ListObjectsInput) (resp *s3.ListObjectsOutput, err error) {
+	if f.quirks.listVersion != 2 {
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err = f.c.ListObjects(req)
+			return shouldRetry(err)
+		})
+		return resp, err
+	}
+	reqv2 := s3.ListObjectsV2Input{
+		Bucket:            req.Bucket,
+		Delimiter:         req.Delimiter,
+		Prefix:            req.Prefix,
+		MaxKeys:           req.MaxKeys,
+		ContinuationToken: req.Marker,
+		RequestPayer:      req.RequestPayer,
+	}
+	var respv2 *s3.ListObjectsV2Output
+	err = f.pacer.Call(func() (bool, error) {
+		respv2, err = f.c.ListObjectsV2(&reqv2)
+		return shouldRetry(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if aws.BoolValue(respv2.IsTruncated) && aws.StringValue(respv2.NextContinuationToken) == "" {
+		return nil, errors.New("s3 protocol error: received listing v2 with IsTruncated set and no NextContinuationToken")
+	}
+	return &s3.ListObjectsOutput{
+		CommonPrefixes: respv2.CommonPrefixes,
+		Contents:       respv2.Contents,
+		IsTruncated:    respv2.IsTruncated,
+		NextMarker:     respv2.NextContinuationToken,
+	}, nil
 }
 
 // listFn is called from list to handle an object.
-type listFn func(remote string, object *s3.Object, isDirectory bool) error
+//
+// version is only set for objects which need a particular version
+// to be read, eg old versions with --s3-versions.
+type listFn func(remote string, object *s3.Object, version *objectVersion, isDirectory bool) error
 
 // list the objects into the function supplied
 //
@@ -813,19 +1193,33 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 	if !recurse {
 		delimiter = "/"
 	}
-	var marker *string
+	var (
+		marker          *string
+		versionIDMarker *string
+		filter          versionFilter
+	)
 	for {
 		// FIXME need to implement ALL loop
 		req := s3.ListObjectsInput{
-			Bucket:    &f.bucket,
-			Delimiter: &delimiter,
-			Prefix:    &root,
-			MaxKeys:   &maxKeys,
-			Marker:    marker,
+			Bucket:       &f.bucket,
+			Delimiter:    &delimiter,
+			Prefix:       &root,
+			MaxKeys:      &maxKeys,
+			Marker:       marker,
+			RequestPayer: f.requestPayer(),
+		}
+		var (
+			resp     *s3.ListObjectsOutput
+			versions []*objectVersion
+			err      error
+		)
+		if f.versionMode() {
+			resp, versions, versionIDMarker, err = f.listObjectVersions(&req, versionIDMarker, &filter)
+		} else {
+			resp, err = f.listObjects(&req)
 		}
-		resp, err := f.c.ListObjects(&req)
 		if err != nil {
-			if awsErr, ok := err.(awserr.RequestFailure); ok {
+			if awsErr, ok := errors.Cause(err).(awserr.RequestFailure); ok {
 				if awsErr.StatusCode() == http.StatusNotFound {
 					err = fs.ErrorDirNotFound
 				}
@@ -848,13 +1242,13 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 				if strings.HasSuffix(remote, "/") {
 					remote = remote[:len(remote)-1]
 				}
-				err = fn(remote, &s3.Object{Key: &remote}, true)
+				err = fn(remote, &s3.Object{Key: &remote}, nil, true)
 				if err != nil {
 					return err
 				}
 			}
 		}
-		for _, object := range resp.Contents {
+		for i, object := range resp.Contents {
 			key := aws.StringValue(object.Key)
 			if !strings.HasPrefix(key, f.root) {
 				fs.Logf(f, "Odd name received %q", key)
@@ -866,14 +1260,18 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 				if recurse {
 					// add a directory in if --fast-list since will have no prefixes
 					remote = remote[:len(remote)-1]
-					err = fn(remote, &s3.Object{Key: &remote}, true)
+					err = fn(remote, &s3.Object{Key: &remote}, nil, true)
 					if err != nil {
 						return err
 					}
 				}
 				continue // skip directory marker
 			}
-			err = fn(remote, object, false)
+			var version *objectVersion
+			if versions != nil {
+				version = versions[i]
+			}
+			err = fn(remote, object, version, false)
 			if err != nil {
 				return err
 			}
@@ -895,7 +1293,7 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 }
 
 // Convert a list item into a DirEntry
-func (f *Fs) itemToDirEntry(remote string, object *s3.Object, isDirectory bool) (fs.DirEntry, error) {
+func (f *Fs) itemToDirEntry(remote string, object *s3.Object, version *objectVersion, isDirectory bool) (fs.DirEntry, error) {
 	if isDirectory {
 		size := int64(0)
 		if object.Size != nil {
@@ -904,7 +1302,7 @@ func (f *Fs) itemToDirEntry(remote string, object *s3.Object, isDirectory bool)
 		d := fs.NewDir(remote, time.Time{}).SetSize(size)
 		return d, nil
 	}
-	o, err := f.newObjectWithInfo(remote, object)
+	o, err := f.newObjectWithInfo(remote, object, version)
 	if err != nil {
 		return nil, err
 	}
@@ -924,8 +1322,8 @@ func (f *Fs) markBucketOK() {
 // listDir lists files and directories to out
 func (f *Fs) listDir(dir string) (entries fs.DirEntries, err error) {
 	// List the objects and directories
-	err = f.list(dir, false, func(remote string, object *s3.Object, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(remote, object, isDirectory)
+	err = f.list(dir, false, func(remote string, object *s3.Object, version *objectVersion, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(remote, object, version, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -948,7 +1346,11 @@ func (f *Fs) listBuckets(dir string) (entries fs.DirEntries, err error) {
 		return nil, fs.ErrorListBucketRequired
 	}
 	req := s3.ListBucketsInput{}
-	resp, err := f.c.ListBuckets(&req)
+	var resp *s3.ListBucketsOutput
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.c.ListBuckets(&req)
+		return shouldRetry(err)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -996,8 +1398,8 @@ func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
 		return fs.ErrorListBucketRequired
 	}
 	list := walk.NewListRHelper(callback)
-	err = f.list(dir, true, func(remote string, object *s3.Object, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(remote, object, isDirectory)
+	err = f.list(dir, true, func(remote string, object *s3.Object, version *objectVersion, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(remote, object, version, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -1013,6 +1415,9 @@ func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
 
 // Put the Object into the bucket
 func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	if !f.versionAt.IsZero() {
+		return nil, errNotWithVersionAt
+	}
 	// Temporary Object under construction
 	fs := &Object{
 		fs:     f,
@@ -1033,11 +1438,14 @@ func (f *Fs) dirExists() (bool, error) {
 	req := s3.HeadBucketInput{
 		Bucket: &f.bucket,
 	}
-	_, err := f.c.HeadBucket(&req)
+	err := f.pacer.Call(func() (bool, error) {
+		_, err := f.c.HeadBucket(&req)
+		return shouldRetry(err)
+	})
 	if err == nil {
 		return true, nil
 	}
-	if err, ok := err.(awserr.RequestFailure); ok {
+	if err, ok := errors.Cause(err).(awserr.RequestFailure); ok {
 		if err.StatusCode() == http.StatusNotFound {
 			return false, nil
 		}
@@ -1047,6 +1455,9 @@ func (f *Fs) dirExists() (bool, error) {
 
 // Mkdir creates the bucket if it doesn't exist
 func (f *Fs) Mkdir(dir string) error {
+	if !f.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
 	f.bucketOKMu.Lock()
 	defer f.bucketOKMu.Unlock()
 	if f.bucketOK {
@@ -1070,8 +1481,11 @@ func (f *Fs) Mkdir(dir string) error {
 			LocationConstraint: &f.locationConstraint,
 		}
 	}
-	_, err := f.c.CreateBucket(&req)
-	if err, ok := err.(awserr.Error); ok {
+	err := f.pacer.Call(func() (bool, error) {
+		_, err := f.c.CreateBucket(&req)
+		return shouldRetry(err)
+	})
+	if err, ok := errors.Cause(err).(awserr.Error); ok {
 		if err.Code() == "BucketAlreadyOwnedByYou" {
 			err = nil
 		}
@@ -1087,6 +1501,9 @@ func (f *Fs) Mkdir(dir string) error {
 //
 // Returns an error if it isn't empty
 func (f *Fs) Rmdir(dir string) error {
+	if !f.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
 	f.bucketOKMu.Lock()
 	defer f.bucketOKMu.Unlock()
 	if f.root != "" || dir != "" {
@@ -1095,7 +1512,10 @@ func (f *Fs) Rmdir(dir string) error {
 	req := s3.DeleteBucketInput{
 		Bucket: &f.bucket,
 	}
-	_, err := f.c.DeleteBucket(&req)
+	err := f.pacer.Call(func() (bool, error) {
+		_, err := f.c.DeleteBucket(&req)
+		return shouldRetry(err)
+	})
 	if err == nil {
 		f.bucketOK = false
 		f.bucketDeleted = true
@@ -1135,20 +1555,185 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 	}
 	srcFs := srcObj.fs
 	key := f.root + remote
-	source := pathEscape(srcFs.bucket + "/" + srcFs.root + srcObj.remote)
+	source := pathEscape(srcFs.bucket + "/" + srcObj.s3Key())
+	if srcObj.versionID != nil {
+		source += "?versionId=" + *srcObj.versionID
+	}
 	req := s3.CopyObjectInput{
 		Bucket:            &f.bucket,
 		Key:               &key,
 		CopySource:        &source,
 		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+		RequestPayer:      f.requestPayer(),
 	}
-	_, err = f.c.CopyObject(&req)
+	f.setCopyEncryption(&req, srcFs)
+	err = f.pacer.Call(func() (bool, error) {
+		_, err = f.c.CopyObject(&req)
+		return shouldRetry(err)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return f.NewObject(remote)
 }
 
+// setCopyEncryption sets the encryption parameters on a server side
+// copy from srcFs into f
+func (f *Fs) setCopyEncryption(req *s3.CopyObjectInput, srcFs *Fs) {
+	if f.sse != "" {
+		req.ServerSideEncryption = &f.sse
+	}
+	if f.sseKMSKeyID != "" {
+		req.SSEKMSKeyId = &f.sseKMSKeyID
+	}
+	req.SSECustomerAlgorithm, req.SSECustomerKey = f.sseCustomer()
+	req.CopySourceSSECustomerAlgorithm, req.CopySourceSSECustomerKey = srcFs.sseCustomer()
+}
+
+// PublicLink generates a presigned URL to download the object at
+// remote which is valid for expire
+//
+// Only objects can be shared - directories don't exist in S3.
+func (f *Fs) PublicLink(remote string, expire fs.Duration) (link string, err error) {
+	// Presigned URLs can only be made for objects
+	if remote == "" {
+		return "", fs.ErrorCantShareDirectories
+	}
+	obj, err := f.NewObject(remote)
+	if err == fs.ErrorObjectNotFound {
+		isDir, dirErr := f.isDirectory(remote)
+		if dirErr != nil {
+			return "", dirErr
+		}
+		if isDir {
+			return "", fs.ErrorCantShareDirectories
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	o := obj.(*Object)
+	if expire > maxExpire {
+		if expire != fs.DurationOff {
+			fs.Logf(f, "Reducing expiry of public link to %v as %v is longer than allowed", maxExpire, expire)
+		}
+		expire = maxExpire
+	}
+	key := o.s3Key()
+	req, _ := f.c.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:       &f.bucket,
+		Key:          &key,
+		VersionId:    o.versionID,
+		RequestPayer: f.requestPayer(),
+	})
+	link, err = req.Presign(time.Duration(expire))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to presign URL")
+	}
+	return link, nil
+}
+
+// isDirectory returns whether there are any objects under the
+// directory remote
+func (f *Fs) isDirectory(remote string) (bool, error) {
+	req := s3.ListObjectsInput{
+		Bucket:       &f.bucket,
+		Delimiter:    aws.String("/"),
+		Prefix:       aws.String(f.root + remote + "/"),
+		MaxKeys:      aws.Int64(1),
+		RequestPayer: f.requestPayer(),
+	}
+	resp, err := f.listObjects(&req)
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Contents) > 0 || len(resp.CommonPrefixes) > 0, nil
+}
+
+// listMultipartUploads lists all the incomplete multipart uploads
+// under the root calling fn for each one
+func (f *Fs) listMultipartUploads(fn func(upload *s3.MultipartUpload) error) error {
+	var (
+		keyMarker      *string
+		uploadIDMarker *string
+	)
+	for {
+		req := s3.ListMultipartUploadsInput{
+			Bucket:         &f.bucket,
+			MaxUploads:     aws.Int64(listChunkSize),
+			KeyMarker:      keyMarker,
+			UploadIdMarker: uploadIDMarker,
+			Prefix:         &f.root,
+		}
+		var resp *s3.ListMultipartUploadsOutput
+		err := f.pacer.Call(func() (bool, error) {
+			var err error
+			resp, err = f.c.ListMultipartUploads(&req)
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return errors.Wrap(err, "list multipart uploads failed")
+		}
+		for _, upload := range resp.Uploads {
+			err = fn(upload)
+			if err != nil {
+				return err
+			}
+		}
+		if !aws.BoolValue(resp.IsTruncated) {
+			break
+		}
+		keyMarker = resp.NextKeyMarker
+		uploadIDMarker = resp.NextUploadIdMarker
+	}
+	return nil
+}
+
+// CleanUp aborts the incomplete multipart uploads under the root
+// which were started more than --s3-cleanup-max-age ago.
+//
+// Incomplete uploads aren't visible in listings but are charged for.
+func (f *Fs) CleanUp() error {
+	if f.bucket == "" {
+		return errors.New("can't cleanup without a bucket")
+	}
+	olderThan := time.Now().Add(-time.Duration(s3CleanupMaxAge))
+	var errs int
+	err := f.listMultipartUploads(func(upload *s3.MultipartUpload) error {
+		key := aws.StringValue(upload.Key)
+		remote := strings.TrimPrefix(key, f.root)
+		initiated := aws.TimeValue(upload.Initiated)
+		if initiated.After(olderThan) {
+			fs.Debugf(f, "Ignoring incomplete multipart upload of %q started %v as too recent", remote, initiated)
+			return nil
+		}
+		req := s3.AbortMultipartUploadInput{
+			Bucket:       &f.bucket,
+			Key:          upload.Key,
+			UploadId:     upload.UploadId,
+			RequestPayer: f.requestPayer(),
+		}
+		err := f.pacer.Call(func() (bool, error) {
+			_, err := f.c.AbortMultipartUpload(&req)
+			return shouldRetry(err)
+		})
+		if err != nil {
+			fs.Errorf(f, "Failed to abort multipart upload of %q started %v: %v", remote, initiated, err)
+			errs++
+			return nil
+		}
+		fs.Infof(f, "Aborted incomplete multipart upload of %q started %v", remote, initiated)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if errs > 0 {
+		return errors.Errorf("failed to abort %d multipart uploads", errs)
+	}
+	return nil
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hash.MD5)
@@ -1183,7 +1768,7 @@ func (o *Object) Hash(t hash.Type) (string, error) {
 	}
 	hash := strings.Trim(strings.ToLower(o.etag), `"`)
 	// Check the etag is a valid md5sum
-	if !matchMd5.MatchString(hash) {
+	if !matchMd5.MatchString(hash) || o.fs.etagIsNotMD5() {
 		err := o.readMetaData()
 		if err != nil {
 			return "", err
@@ -1202,6 +1787,26 @@ func (o *Object) Hash(t hash.Type) (string, error) {
 	return hash, nil
 }
 
+// s3Key returns the key of the object in the bucket
+func (o *Object) s3Key() string {
+	if o.versionKey != "" {
+		return o.versionKey
+	}
+	return o.fs.root + o.remote
+}
+
+// checkWritable returns an error if the object can't be modified
+// because it is an old version
+func (o *Object) checkWritable() error {
+	if !o.fs.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
+	if o.versionID != nil {
+		return errOldVersion
+	}
+	return nil
+}
+
 // Size returns the size of an object in bytes
 func (o *Object) Size() int64 {
 	return o.bytes
@@ -1214,14 +1819,21 @@ func (o *Object) readMetaData() (err error) {
 	if o.meta != nil {
 		return nil
 	}
-	key := o.fs.root + o.remote
+	key := o.s3Key()
 	req := s3.HeadObjectInput{
-		Bucket: &o.fs.bucket,
-		Key:    &key,
-	}
-	resp, err := o.fs.c.HeadObject(&req)
+		Bucket:       &o.fs.bucket,
+		Key:          &key,
+		VersionId:    o.versionID,
+		RequestPayer: o.fs.requestPayer(),
+	}
+	req.SSECustomerAlgorithm, req.SSECustomerKey = o.fs.sseCustomer()
+	var resp *s3.HeadObjectOutput
+	err = o.fs.pacer.Call(func() (bool, error) {
+		resp, err = o.fs.c.HeadObject(&req)
+		return shouldRetry(err)
+	})
 	if err != nil {
-		if awsErr, ok := err.(awserr.RequestFailure); ok {
+		if awsErr, ok := errors.Cause(err).(awserr.RequestFailure); ok {
 			if awsErr.StatusCode() == http.StatusNotFound {
 				return fs.ErrorObjectNotFound
 			}
@@ -1237,6 +1849,7 @@ func (o *Object) readMetaData() (err error) {
 	o.etag = aws.StringValue(resp.ETag)
 	o.bytes = size
 	o.meta = resp.Metadata
+	o.storageClass = aws.StringValue(resp.StorageClass)
 	if resp.LastModified == nil {
 		fs.Logf(o, "Failed to read last modified from HEAD: %v", err)
 		o.lastModified = time.Now()
@@ -1276,7 +1889,11 @@ func (o *Object) ModTime() time.Time {
 
 // SetModTime sets the modification time of the local fs object
 func (o *Object) SetModTime(modTime time.Time) error {
-	err := o.readMetaData()
+	err := o.checkWritable()
+	if err != nil {
+		return err
+	}
+	err = o.readMetaData()
 	if err != nil {
 		return err
 	}
@@ -1302,9 +1919,17 @@ func (o *Object) SetModTime(modTime time.Time) error {
 		CopySource:        aws.String(pathEscape(sourceKey)),
 		Metadata:          o.meta,
 		MetadataDirective: &directive,
+		RequestPayer:      o.fs.requestPayer(),
 	}
-	_, err = o.fs.c.CopyObject(&req)
-	return err
+	// Keep the storage class otherwise the copy resets it to STANDARD
+	if o.storageClass != "" {
+		req.StorageClass = &o.storageClass
+	}
+	o.fs.setCopyEncryption(&req, o.fs)
+	return o.fs.pacer.Call(func() (bool, error) {
+		_, err := o.fs.c.CopyObject(&req)
+		return shouldRetry(err)
+	})
 }
 
 // Storable raturns a boolean indicating if this object is storable
@@ -1314,24 +1939,38 @@ func (o *Object) Storable() bool {
 
 // Open an object for read
 func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
-	key := o.fs.root + o.remote
+	key := o.s3Key()
 	req := s3.GetObjectInput{
-		Bucket: &o.fs.bucket,
-		Key:    &key,
+		Bucket:       &o.fs.bucket,
+		Key:          &key,
+		VersionId:    o.versionID,
+		RequestPayer: o.fs.requestPayer(),
 	}
+	req.SSECustomerAlgorithm, req.SSECustomerKey = o.fs.sseCustomer()
 	for _, option := range options {
 		switch option.(type) {
 		case *fs.RangeOption, *fs.SeekOption:
 			_, value := option.Header()
 			req.Range = &value
+		case *fs.HTTPOption:
+			// Set any extra headers passed in, eg with --header-download
+			key, value := option.Header()
+			err = setDownloadHeader(&req, key, value)
+			if err != nil {
+				fs.Errorf(o, "%v", err)
+			}
 		default:
 			if option.Mandatory() {
 				fs.Logf(o, "Unsupported mandatory option: %v", option)
 			}
 		}
 	}
-	resp, err := o.fs.c.GetObject(&req)
-	if err, ok := err.(awserr.RequestFailure); ok {
+	var resp *s3.GetObjectOutput
+	err = o.fs.pacer.Call(func() (bool, error) {
+		resp, err = o.fs.c.GetObject(&req)
+		return shouldRetry(err)
+	})
+	if err, ok := errors.Cause(err).(awserr.RequestFailure); ok {
 		if err.Code() == "InvalidObjectState" {
 			return nil, errors.Errorf("Object in GLACIER, restore first: %v", key)
 		}
@@ -1342,9 +1981,51 @@ func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	return resp.Body, nil
 }
 
+// setDownloadHeader sets the header key to value in req if it is
+// one GetObject understands.
+//
+// The Response-* headers set the headers S3 returns the object with.
+func setDownloadHeader(req *s3.GetObjectInput, key, value string) error {
+	parseTime := func() (*time.Time, error) {
+		t, err := http.ParseTime(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad time in header %q on download", key)
+		}
+		return &t, nil
+	}
+	var err error
+	switch strings.ToLower(key) {
+	case "if-match":
+		req.IfMatch = aws.String(value)
+	case "if-none-match":
+		req.IfNoneMatch = aws.String(value)
+	case "if-modified-since":
+		req.IfModifiedSince, err = parseTime()
+	case "if-unmodified-since":
+		req.IfUnmodifiedSince, err = parseTime()
+	case "response-cache-control":
+		req.ResponseCacheControl = aws.String(value)
+	case "response-content-disposition":
+		req.ResponseContentDisposition = aws.String(value)
+	case "response-content-encoding":
+		req.ResponseContentEncoding = aws.String(value)
+	case "response-content-language":
+		req.ResponseContentLanguage = aws.String(value)
+	case "response-content-type":
+		req.ResponseContentType = aws.String(value)
+	default:
+		return errors.Errorf("Don't know how to set header %q on download", key)
+	}
+	return err
+}
+
 // Update the Object from in with modTime and size
 func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
-	err := o.fs.Mkdir("")
+	err := o.checkWritable()
+	if err != nil {
+		return err
+	}
+	err = o.fs.Mkdir("")
 	if err != nil {
 		return err
 	}
@@ -1357,17 +2038,25 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 		u.S3 = o.fs.c
 		u.PartSize = int64(s3ChunkSize)
 		u.PartSize = s3manager.MinUploadPartSize
+		u.MaxUploadParts = o.fs.quirks.maxUploadParts
+		// The upload can't be retried by the pacer without
+		// uploading all the parts again, so let the SDK retry
+		// rate limited parts itself.
+		u.RequestOptions = append(u.RequestOptions, func(r *request.Request) {
+			r.Retryer = client.DefaultRetryer{NumMaxRetries: maxRetries}
+		})
+		maxUploadParts := int64(u.MaxUploadParts)
 
 		if size == -1 {
 			// Make parts as small as possible while still being able to upload to the
 			// S3 file size limit. Rounded up to nearest MB.
-			u.PartSize = (((maxFileSize / s3manager.MaxUploadParts) >> 20) + 1) << 20
+			u.PartSize = (((maxFileSize / maxUploadParts) >> 20) + 1) << 20
 			return
 		}
 		// Adjust PartSize until the number of parts is small enough.
-		if size/u.PartSize >= s3manager.MaxUploadParts {
+		if size/u.PartSize >= maxUploadParts {
 			// Calculate partition size rounded up to the nearest MB
-			u.PartSize = (((size / s3manager.MaxUploadParts) >> 20) + 1) << 20
+			u.PartSize = (((size / maxUploadParts) >> 20) + 1) << 20
 		}
 	})
 
@@ -1376,7 +2065,9 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 		metaMtime: aws.String(swift.TimeToFloatString(modTime)),
 	}
 
-	if !*s3DisableChecksum && size > uploader.PartSize {
+	// The ETag isn't the MD5 for multipart uploads or encrypted
+	// objects so store the MD5 in the metadata for those
+	if !*s3DisableChecksum && (size > uploader.PartSize || o.fs.etagIsNotMD5()) {
 		hash, err := src.Hash(hash.MD5)
 
 		if err == nil && matchMd5.MatchString(hash) {
@@ -1393,21 +2084,59 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 
 	key := o.fs.root + o.remote
 	req := s3manager.UploadInput{
-		Bucket:      &o.fs.bucket,
-		ACL:         &o.fs.acl,
-		Key:         &key,
-		Body:        in,
-		ContentType: &mimeType,
-		Metadata:    metadata,
+		Bucket:       &o.fs.bucket,
+		ACL:          &o.fs.acl,
+		Key:          &key,
+		Body:         in,
+		ContentType:  &mimeType,
+		Metadata:     metadata,
+		RequestPayer: o.fs.requestPayer(),
 		//ContentLength: &size,
 	}
 	if o.fs.sse != "" {
 		req.ServerSideEncryption = &o.fs.sse
 	}
+	if o.fs.sseKMSKeyID != "" {
+		req.SSEKMSKeyId = &o.fs.sseKMSKeyID
+	}
+	req.SSECustomerAlgorithm, req.SSECustomerKey = o.fs.sseCustomer()
 	if o.fs.storageClass != "" {
 		req.StorageClass = &o.fs.storageClass
 	}
-	_, err = uploader.Upload(&req)
+
+	// Set any extra headers passed in, eg with --header-upload
+	for _, option := range options {
+		key, value := option.Header()
+		lowerKey := strings.ToLower(key)
+		switch lowerKey {
+		case "":
+			// ignore options which aren't headers, eg HashesOption
+		case "cache-control":
+			req.CacheControl = aws.String(value)
+		case "content-disposition":
+			req.ContentDisposition = aws.String(value)
+		case "content-encoding":
+			req.ContentEncoding = aws.String(value)
+		case "content-language":
+			req.ContentLanguage = aws.String(value)
+		case "content-type":
+			req.ContentType = aws.String(value)
+		case "x-amz-tagging":
+			req.Tagging = aws.String(value)
+		default:
+			const amzMetaPrefix = "x-amz-meta-"
+			if strings.HasPrefix(lowerKey, amzMetaPrefix) {
+				metaKey := lowerKey[len(amzMetaPrefix):]
+				req.Metadata[metaKey] = aws.String(value)
+			} else {
+				fs.Errorf(o, "Don't know how to set header %q on upload", key)
+			}
+		}
+	}
+	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
+		_, err = uploader.Upload(&req)
+		return shouldRetry(err)
+	})
 	if err != nil {
 		return err
 	}
@@ -1419,14 +2148,103 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 }
 
 // Remove an object
+//
+// Removing an old version listed with --s3-versions deletes that
+// version permanently.
 func (o *Object) Remove() error {
-	key := o.fs.root + o.remote
+	if !o.fs.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
+	key := o.s3Key()
 	req := s3.DeleteObjectInput{
-		Bucket: &o.fs.bucket,
-		Key:    &key,
+		Bucket:       &o.fs.bucket,
+		Key:          &key,
+		VersionId:    o.versionID,
+		RequestPayer: o.fs.requestPayer(),
+	}
+	return o.fs.pacer.Call(func() (bool, error) {
+		_, err := o.fs.c.DeleteObject(&req)
+		return shouldRetry(err)
+	})
+}
+
+// storageClasses are the storage classes objects can be set to with SetTier
+var storageClasses = []string{
+	s3.StorageClassStandard,
+	s3.StorageClassReducedRedundancy,
+	s3.StorageClassStandardIa,
+	s3.StorageClassOnezoneIa,
+	"GLACIER",
+}
+
+// validateStorageClass returns the storage class in canonical form
+// or an error if it isn't known
+func validateStorageClass(tier string) (string, error) {
+	upperTier := strings.ToUpper(tier)
+	for _, storageClass := range storageClasses {
+		if upperTier == storageClass {
+			return storageClass, nil
+		}
 	}
-	_, err := o.fs.c.DeleteObject(&req)
-	return err
+	return "", errors.Errorf("invalid storage class %q - must be one of %s", tier, strings.Join(storageClasses, ", "))
+}
+
+// SetTier changes the storage class of the object by copying it
+// over itself
+func (o *Object) SetTier(tier string) (err error) {
+	err = o.checkWritable()
+	if err != nil {
+		return err
+	}
+	tier, err = validateStorageClass(tier)
+	if err != nil {
+		return err
+	}
+	err = o.readMetaData()
+	if err != nil {
+		return err
+	}
+	currentTier := o.storageClass
+	if currentTier == "" {
+		currentTier = s3.StorageClassStandard
+	}
+	if currentTier == tier {
+		// S3 refuses to copy an object over itself without changes
+		fs.Debugf(o, "Already has storage class %q", tier)
+		return nil
+	}
+	if o.bytes >= maxSizeForCopy {
+		return errors.Errorf("can't set storage class for objects bigger than %v", fs.SizeSuffix(maxSizeForCopy))
+	}
+	key := o.fs.root + o.remote
+	sourceKey := o.fs.bucket + "/" + key
+	req := s3.CopyObjectInput{
+		Bucket:            &o.fs.bucket,
+		ACL:               &o.fs.acl,
+		Key:               &key,
+		CopySource:        aws.String(pathEscape(sourceKey)),
+		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+		StorageClass:      &tier,
+		RequestPayer:      o.fs.requestPayer(),
+	}
+	o.fs.setCopyEncryption(&req, o.fs)
+	err = o.fs.pacer.Call(func() (bool, error) {
+		_, err = o.fs.c.CopyObject(&req)
+		return shouldRetry(err)
+	})
+	if err != nil {
+		return err
+	}
+	o.storageClass = tier
+	return nil
+}
+
+// GetTier returns the storage class of the object
+func (o *Object) GetTier() string {
+	if o.storageClass == "" {
+		return s3.StorageClassStandard
+	}
+	return o.storageClass
 }
 
 // MimeType of an Object if known, "" otherwise
@@ -1441,10 +2259,14 @@ func (o *Object) MimeType() string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
+	_ fs.Fs           = &Fs{}
+	_ fs.Copier       = &Fs{}
+	_ fs.PutStreamer  = &Fs{}
+	_ fs.ListRer      = &Fs{}
+	_ fs.PublicLinker = &Fs{}
+	_ fs.CleanUpper   = &Fs{}
+	_ fs.Object       = &Object{}
+	_ fs.MimeTyper    = &Object{}
+	_ fs.SetTierer    = &Object{}
+	_ fs.GetTierer    = &Object{}
 )
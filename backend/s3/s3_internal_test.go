@@ -0,0 +1,376 @@
+package s3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/lib/pacer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalSetEncryption(t *testing.T) {
+	for _, test := range []struct {
+		sse, kmsKeyID, algo, key, keyBase64 string
+		wantSSE, wantAlgo, wantKey          string
+		wantErr                             bool
+	}{
+		{},
+		{sse: "AES256", wantSSE: "AES256"},
+		{kmsKeyID: "arn:aws:kms:key", wantSSE: "aws:kms"},
+		{sse: "aws:kms", kmsKeyID: "arn:aws:kms:key", wantSSE: "aws:kms"},
+		{sse: "AES256", kmsKeyID: "arn:aws:kms:key", wantErr: true},
+		{key: "01234567890123456789012345678901", wantAlgo: "AES256", wantKey: "01234567890123456789012345678901"},
+		{keyBase64: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=", wantAlgo: "AES256", wantKey: "01234567890123456789012345678901"},
+		{key: "a", keyBase64: "YQ==", wantErr: true},
+		{keyBase64: "not base64!", wantErr: true},
+		{sse: "AES256", key: "a", wantErr: true},
+		{algo: "AES256", wantErr: true},
+	} {
+		f := &Fs{
+			sse:             test.sse,
+			sseKMSKeyID:     test.kmsKeyID,
+			sseCustomerAlgo: test.algo,
+			sseCustomerKey:  test.key,
+		}
+		err := f.setEncryption(test.keyBase64)
+		if test.wantErr {
+			assert.Error(t, err, "%+v", test)
+			continue
+		}
+		assert.NoError(t, err, "%+v", test)
+		assert.Equal(t, test.wantSSE, f.sse, "%+v", test)
+		assert.Equal(t, test.wantAlgo, f.sseCustomerAlgo, "%+v", test)
+		assert.Equal(t, test.wantKey, f.sseCustomerKey, "%+v", test)
+		assert.Equal(t, test.wantKey != "" || test.wantSSE == "aws:kms", f.etagIsNotMD5(), "%+v", test)
+	}
+}
+
+func TestInternalValidateStorageClass(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "STANDARD", want: "STANDARD"},
+		{in: "standard_ia", want: "STANDARD_IA"},
+		{in: "OneZone_IA", want: "ONEZONE_IA"},
+		{in: "glacier", want: "GLACIER"},
+		{in: "REDUCED_REDUNDANCY", want: "REDUCED_REDUNDANCY"},
+		{in: "", wantErr: true},
+		{in: "HOT", wantErr: true},
+	} {
+		got, err := validateStorageClass(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
+
+func TestInternalGetQuirks(t *testing.T) {
+	const remoteName = "TestS3Quirks"
+	config.LoadConfig()
+	keys := []string{"provider", "list_version", "region", "signature_version"}
+	defer func() {
+		for _, key := range keys {
+			config.FileDeleteKey(remoteName, key)
+		}
+	}()
+	for _, test := range []struct {
+		provider         string
+		listVersion      string
+		region           string
+		signatureVersion string
+		want             providerQuirks
+		wantErr          bool
+	}{
+		{provider: "", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "AWS", want: providerQuirks{listVersion: 2, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "Alibaba", want: providerQuirks{listVersion: 1, virtualHostStyle: true, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "Ceph", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "Scaleway", want: providerQuirks{listVersion: 2, maxUploadParts: 1000, signatureVersion: 4}},
+		{provider: "Other", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "Ceph", listVersion: "2", want: providerQuirks{listVersion: 2, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "AWS", listVersion: "0", want: providerQuirks{listVersion: 2, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "AWS", listVersion: "3", wantErr: true},
+		{provider: "Ceph", region: "other-v2-signature", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 2}},
+		{provider: "Ceph", region: "other-v2-signature", signatureVersion: "4", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "Other", signatureVersion: "2", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 2}},
+		{provider: "Other", signatureVersion: "0", want: providerQuirks{listVersion: 1, maxUploadParts: 10000, signatureVersion: 4}},
+		{provider: "Other", signatureVersion: "3", wantErr: true},
+	} {
+		for i, value := range []string{test.provider, test.listVersion, test.region, test.signatureVersion} {
+			config.FileSet(remoteName, keys[i], value)
+		}
+		got, err := getQuirks(remoteName)
+		if test.wantErr {
+			assert.Error(t, err, "%+v", test)
+			continue
+		}
+		require.NoError(t, err, "%+v", test)
+		assert.Equal(t, test.want, got, "%+v", test)
+	}
+}
+
+func TestInternalVersionSuffix(t *testing.T) {
+	when := time.Date(2018, 6, 1, 12, 34, 56, 789000000, time.UTC)
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"file.txt", "file-v2018-06-01-123456.789.txt"},
+		{"dir/file.txt", "dir/file-v2018-06-01-123456.789.txt"},
+		{"dir.d/file", "dir.d/file-v2018-06-01-123456.789"},
+		{"file", "file-v2018-06-01-123456.789"},
+		{".bashrc", ".bashrc-v2018-06-01-123456.789"},
+		{"dir/.bashrc", "dir/.bashrc-v2018-06-01-123456.789"},
+		{"archive.tar.gz", "archive.tar-v2018-06-01-123456.789.gz"},
+	} {
+		got := addVersionSuffix(test.in, when)
+		assert.Equal(t, test.want, got, test.in)
+		stripped, gotWhen, ok := removeVersionSuffix(got)
+		assert.True(t, ok, test.in)
+		assert.Equal(t, test.in, stripped, test.in)
+		assert.True(t, when.Equal(gotWhen), test.in)
+	}
+	for _, in := range []string{
+		"file.txt",
+		"file",
+		"-v2018-06-01-123456.789",
+		"file-v2018-13-01-123456.789.txt",
+	} {
+		_, _, ok := removeVersionSuffix(in)
+		assert.False(t, ok, in)
+	}
+}
+
+func TestInternalParseVersionAt(t *testing.T) {
+	got, err := parseVersionAt("2018-06-01T12:00:00Z")
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)))
+
+	got, err = parseVersionAt("2018-06-01 12:00:00")
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2018, 6, 1, 12, 0, 0, 0, time.Local)))
+
+	got, err = parseVersionAt("2018-06-01")
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2018, 6, 1, 0, 0, 0, 0, time.Local)))
+
+	before := time.Now()
+	got, err = parseVersionAt("2d")
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(-48*time.Hour), got, time.Minute)
+
+	_, err = parseVersionAt("yesterday")
+	assert.Error(t, err)
+}
+
+func TestInternalShouldRetry(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("potato"), false},
+		{awserr.NewRequestFailure(awserr.New("NoSuchKey", "not found", nil), 404, "id"), false},
+		{awserr.NewRequestFailure(awserr.New("InternalError", "oops", nil), 500, "id"), false},
+		{awserr.NewRequestFailure(awserr.New("SlowDown", "slow down", nil), 503, "id"), true},
+		{awserr.NewRequestFailure(awserr.New("TooManyRequests", "slow down", nil), 429, "id"), true},
+		{awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "busy", nil), 503, "id"), false},
+		{awserr.New("Throttling", "slow down", nil), false},
+	} {
+		gotRetry, gotErr := shouldRetry(test.err)
+		assert.Equal(t, test.want, gotRetry, "%v", test.err)
+		assert.Equal(t, test.want, fserrors.IsRateLimitError(gotErr), "%v", test.err)
+
+		// The SDK leaves rate limiting to the pacer
+		req := &request.Request{Error: test.err}
+		if test.want {
+			assert.False(t, retryer{}.ShouldRetry(req), "%v", test.err)
+		}
+	}
+}
+
+func TestInternalShouldRetryAfter(t *testing.T) {
+	// The error is wrapped with the Retry-After from the response
+	// if the SDK gives up on a rate limited request
+	slowDown := awserr.NewRequestFailure(awserr.New("SlowDown", "slow down", nil), 503, "id")
+	_, err := fserrors.RetryAfterHTTP(true, &http.Response{Header: http.Header{"Retry-After": {"10"}}}, slowDown)
+	retry, err := shouldRetry(err)
+	assert.True(t, retry)
+	assert.True(t, fserrors.IsRateLimitError(err))
+	assert.False(t, fserrors.RetryAfterErrorTime(err).IsZero())
+}
+
+func TestInternalShouldRetryClass(t *testing.T) {
+	denied := awserr.NewRequestFailure(awserr.New("AccessDenied", "access denied", nil), 403, "id")
+	_, err := shouldRetry(denied)
+	assert.Equal(t, fserrors.ClassPermission, fserrors.Classify(err))
+	// callers look through the classification with errors.Cause
+	assert.Equal(t, denied, errors.Cause(err))
+
+	// InvalidObjectState is a 403 too but callers look for it
+	in := awserr.NewRequestFailure(awserr.New("InvalidObjectState", "in glacier", nil), 403, "id")
+	_, err = shouldRetry(in)
+	assert.Equal(t, in, err)
+}
+
+func TestInternalPublicLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "HEAD" && r.URL.Path == "/bucket/file":
+			w.Header().Set("Content-Length", "5")
+			w.Header().Set("Last-Modified", "Sat, 03 Feb 2001 04:05:06 GMT")
+		case r.Method == "GET" && r.URL.Path == "/bucket" && r.URL.Query().Get("prefix") == "dir/":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult><Name>bucket</Name><Prefix>dir/</Prefix><Contents><Key>dir/file</Key><Size>5</Size></Contents></ListBucketResult>`))
+		case r.Method == "GET" && r.URL.Path == "/bucket":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult><Name>bucket</Name></ListBucketResult>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ses, err := session.NewSession()
+	require.NoError(t, err)
+	c := s3.New(ses, &aws.Config{
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	f := &Fs{c: c, ses: ses, bucket: "bucket", pacer: pacer.New().SetMinSleep(time.Millisecond)}
+
+	var logs []string
+	oldLogPrint := fs.LogPrint
+	fs.LogPrint = func(level fs.LogLevel, text string) { logs = append(logs, text) }
+	defer func() { fs.LogPrint = oldLogPrint }()
+
+	for _, test := range []struct {
+		expire   fs.Duration
+		expires  string
+		reducing bool
+	}{
+		{fs.Duration(time.Hour), "3600", false},
+		{fs.DurationOff, "604800", false},
+		{fs.Duration(30 * 24 * time.Hour), "604800", true},
+	} {
+		logs = nil
+		link, err := f.PublicLink("file", test.expire)
+		require.NoError(t, err)
+		u, err := url.Parse(link)
+		require.NoError(t, err)
+		assert.Equal(t, test.expires, u.Query().Get("X-Amz-Expires"), test.expire.String())
+		assert.Equal(t, test.reducing, len(logs) > 0 && strings.Contains(logs[0], "Reducing expiry"), test.expire.String())
+	}
+
+	_, err = f.PublicLink("dir", fs.DurationOff)
+	assert.Equal(t, fs.ErrorCantShareDirectories, err)
+	_, err = f.PublicLink("", fs.DurationOff)
+	assert.Equal(t, fs.ErrorCantShareDirectories, err)
+	_, err = f.PublicLink("missing", fs.DurationOff)
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+}
+
+func TestInternalNewObjectVersion(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "HEAD" && r.URL.Path == "/bucket/file.txt":
+			w.Header().Set("Content-Length", "5")
+			w.Header().Set("Last-Modified", "Sun, 04 Feb 2001 04:05:06 GMT")
+		case r.Method == "GET" && r.URL.Path == "/bucket" && r.URL.Query()["versions"] != nil:
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListVersionsResult><Name>bucket</Name><IsTruncated>false</IsTruncated>
+<Version><Key>file.txt</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest><LastModified>2001-02-04T04:05:06.000Z</LastModified><Size>5</Size></Version>
+<Version><Key>file.txt</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2001-02-03T04:05:06.000Z</LastModified><Size>4</Size></Version>
+</ListVersionsResult>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ses, err := session.NewSession()
+	require.NoError(t, err)
+	c := s3.New(ses, &aws.Config{
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	f := &Fs{c: c, ses: ses, bucket: "bucket", versions: true, pacer: pacer.New().SetMinSleep(time.Millisecond)}
+
+	// The current version is read without listing
+	o, err := f.NewObject("file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), o.Size())
+	assert.Nil(t, o.(*Object).versionID)
+	assert.Equal(t, []string{"HEAD /bucket/file.txt"}, requests)
+
+	// An old version is found by listing
+	requests = nil
+	o, err = f.NewObject("file-v2001-02-03-040506.000.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), o.Size())
+	assert.Equal(t, "v1", aws.StringValue(o.(*Object).versionID))
+	assert.Equal(t, "file.txt", o.(*Object).versionKey)
+	assert.Equal(t, []string{"GET /bucket"}, requests)
+
+	_, err = f.NewObject("file-v2001-02-05-040506.000.txt")
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+}
+
+func TestInternalSetDownloadHeader(t *testing.T) {
+	var req s3.GetObjectInput
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	for _, test := range []struct {
+		key, value string
+	}{
+		{"If-Match", `"etag"`},
+		{"if-none-match", `"other"`},
+		{"If-Modified-Since", when.Format(http.TimeFormat)},
+		{"If-Unmodified-Since", when.Format(http.TimeFormat)},
+		{"Response-Cache-Control", "no-cache"},
+		{"Response-Content-Disposition", "attachment"},
+		{"Response-Content-Encoding", "gzip"},
+		{"Response-Content-Language", "en"},
+		{"Response-Content-Type", "text/plain"},
+	} {
+		require.NoError(t, setDownloadHeader(&req, test.key, test.value), test.key)
+	}
+	assert.Equal(t, `"etag"`, aws.StringValue(req.IfMatch))
+	assert.Equal(t, `"other"`, aws.StringValue(req.IfNoneMatch))
+	assert.Equal(t, when, aws.TimeValue(req.IfModifiedSince))
+	assert.Equal(t, when, aws.TimeValue(req.IfUnmodifiedSince))
+	assert.Equal(t, "no-cache", aws.StringValue(req.ResponseCacheControl))
+	assert.Equal(t, "attachment", aws.StringValue(req.ResponseContentDisposition))
+	assert.Equal(t, "gzip", aws.StringValue(req.ResponseContentEncoding))
+	assert.Equal(t, "en", aws.StringValue(req.ResponseContentLanguage))
+	assert.Equal(t, "text/plain", aws.StringValue(req.ResponseContentType))
+
+	err := setDownloadHeader(&req, "If-Modified-Since", "yesterday")
+	assert.Error(t, err)
+	err = setDownloadHeader(&req, "X-Amz-Meta-Test", "Foo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Don't know how to set header")
+}
@@ -0,0 +1,244 @@
+// Support for listing old versions of objects in versioned buckets
+
+package s3
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// versionFormat is inserted before the extension of old versions of
+// objects when listing with --s3-versions
+const versionFormat = "-v2006-01-02-150405.000"
+
+var (
+	errNotWithVersionAt = errors.New("can't modify or delete objects when using --s3-version-at")
+	errOldVersion       = errors.New("can't modify an old version of an object")
+)
+
+// objectVersion identifies a particular version of an object found in
+// a listing
+type objectVersion struct {
+	id  *string // the version ID
+	key string  // the real key of the object - the listed key may have a version suffix
+}
+
+// addVersionSuffix inserts the time t into remote before its extension
+//
+// eg "dir/file.txt" -> "dir/file-v2006-01-02-150405.000.txt"
+func addVersionSuffix(remote string, t time.Time) string {
+	ext := path.Ext(remote)
+	base := remote[:len(remote)-len(ext)]
+	if base == "" || strings.HasSuffix(base, "/") {
+		// names like ".bashrc" are all extension
+		base, ext = remote, ""
+	}
+	return base + t.UTC().Format(versionFormat) + ext
+}
+
+// removeVersionSuffix is the inverse of addVersionSuffix
+//
+// It returns the remote without the suffix, the time from the suffix
+// and true if a version suffix was found.
+func removeVersionSuffix(remote string) (string, time.Time, bool) {
+	try := func(base, ext string) (string, time.Time, bool) {
+		i := len(base) - len(versionFormat)
+		if i <= 0 {
+			return "", time.Time{}, false
+		}
+		t, err := time.Parse(versionFormat, base[i:])
+		if err != nil {
+			return "", time.Time{}, false
+		}
+		return base[:i] + ext, t, true
+	}
+	if stripped, t, ok := try(remote, ""); ok {
+		return stripped, t, true
+	}
+	ext := path.Ext(remote)
+	return try(remote[:len(remote)-len(ext)], ext)
+}
+
+// parseVersionAt parses the argument to --s3-version-at
+//
+// This can be an absolute time, eg "2018-06-01 12:00:00" or a
+// duration before now, eg "2d".
+func parseVersionAt(s string) (time.Time, error) {
+	for _, layout := range []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	} {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	ago, err := fs.ParseDuration(s)
+	if err != nil || ago < 0 {
+		return time.Time{}, errors.Errorf("couldn't parse %q as a time or as a duration", s)
+	}
+	return time.Now().Add(-ago), nil
+}
+
+// versionMode returns true if the listings should be made from the
+// object versions
+func (f *Fs) versionMode() bool {
+	return f.versions || !f.versionAt.IsZero()
+}
+
+// versionEntry is an object version or a delete marker
+type versionEntry struct {
+	key          *string
+	versionID    *string
+	isLatest     bool
+	lastModified time.Time
+	object       *s3.Object // nil for a delete marker
+}
+
+// versionFilter keeps track of which key has been dealt with across
+// calls to listObjectVersions for --s3-version-at
+type versionFilter struct {
+	lastKey string
+}
+
+// listObjectVersions lists one chunk of object versions and converts
+// them into a listing of objects.
+//
+// With --s3-versions all versions are returned, the old ones having a
+// version suffix added to their name.  With --s3-version-at only the
+// version current at that time is returned, if it wasn't deleted.
+//
+// The returned versions are the same length as resp.Contents.
+// NextMarker in the response is set to the next key marker and the
+// next version ID marker is returned.
+func (f *Fs) listObjectVersions(req *s3.ListObjectsInput, versionIDMarker *string, filter *versionFilter) (resp *s3.ListObjectsOutput, versions []*objectVersion, nextVersionIDMarker *string, err error) {
+	reqv := s3.ListObjectVersionsInput{
+		Bucket:          req.Bucket,
+		Delimiter:       req.Delimiter,
+		Prefix:          req.Prefix,
+		MaxKeys:         req.MaxKeys,
+		KeyMarker:       req.Marker,
+		VersionIdMarker: versionIDMarker,
+	}
+	var respv *s3.ListObjectVersionsOutput
+	err = f.pacer.Call(func() (bool, error) {
+		respv, err = f.c.ListObjectVersions(&reqv)
+		return shouldRetry(err)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if aws.BoolValue(respv.IsTruncated) && aws.StringValue(respv.NextKeyMarker) == "" {
+		return nil, nil, nil, errors.New("s3 protocol error: received version listing with IsTruncated set and no NextKeyMarker")
+	}
+
+	// Merge the versions and the delete markers in key order,
+	// newest first for each key
+	var entries []versionEntry
+	for _, v := range respv.Versions {
+		entries = append(entries, versionEntry{
+			key:          v.Key,
+			versionID:    v.VersionId,
+			isLatest:     aws.BoolValue(v.IsLatest),
+			lastModified: aws.TimeValue(v.LastModified),
+			object: &s3.Object{
+				Key:          v.Key,
+				ETag:         v.ETag,
+				LastModified: v.LastModified,
+				Size:         v.Size,
+				StorageClass: v.StorageClass,
+			},
+		})
+	}
+	for _, d := range respv.DeleteMarkers {
+		entries = append(entries, versionEntry{
+			key:          d.Key,
+			versionID:    d.VersionId,
+			isLatest:     aws.BoolValue(d.IsLatest),
+			lastModified: aws.TimeValue(d.LastModified),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := aws.StringValue(entries[i].key), aws.StringValue(entries[j].key)
+		if a != b {
+			return a < b
+		}
+		return entries[i].lastModified.After(entries[j].lastModified)
+	})
+
+	resp = &s3.ListObjectsOutput{
+		CommonPrefixes: respv.CommonPrefixes,
+		IsTruncated:    respv.IsTruncated,
+		NextMarker:     respv.NextKeyMarker,
+	}
+	for _, entry := range entries {
+		key := aws.StringValue(entry.key)
+		var version *objectVersion
+		if f.versions {
+			if entry.object == nil {
+				continue // skip delete markers
+			}
+			if !entry.isLatest && !strings.HasSuffix(key, "/") {
+				version = &objectVersion{id: entry.versionID, key: key}
+				entry.object.Key = aws.String(addVersionSuffix(key, entry.lastModified))
+			}
+		} else {
+			if key == filter.lastKey || entry.lastModified.After(f.versionAt) {
+				continue
+			}
+			filter.lastKey = key
+			if entry.object == nil {
+				continue // deleted at that time
+			}
+			version = &objectVersion{id: entry.versionID, key: key}
+		}
+		resp.Contents = append(resp.Contents, entry.object)
+		versions = append(versions, version)
+	}
+	return resp, versions, respv.NextVersionIdMarker, nil
+}
+
+// newObjectVersion finds the Object at remote when using
+// --s3-versions or --s3-version-at by listing its directory
+//
+// With --s3-versions a remote without a version suffix can only be
+// the current version so it is read directly instead.
+func (f *Fs) newObjectVersion(remote string) (fs.Object, error) {
+	if f.versionAt.IsZero() {
+		if _, _, ok := removeVersionSuffix(remote); !ok {
+			return f.newObjectWithInfo(remote, nil, nil)
+		}
+	}
+	dir := path.Dir(remote)
+	if dir == "." {
+		dir = ""
+	}
+	var o fs.Object
+	err := f.list(dir, false, func(entryRemote string, object *s3.Object, version *objectVersion, isDirectory bool) error {
+		if isDirectory || entryRemote != remote {
+			return nil
+		}
+		var err error
+		o, err = f.newObjectWithInfo(remote, object, version)
+		return err
+	})
+	if err == fs.ErrorDirNotFound {
+		return nil, fs.ErrorObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		return nil, fs.ErrorObjectNotFound
+	}
+	return o, nil
+}
@@ -0,0 +1,124 @@
+// Package api has type definitions for seafile
+//
+// Converted from the API docs at https://download.seafile.com/published/web-api
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Error is returned from seafile when things go wrong
+//
+// Seafile isn't consistent about which field it uses for the
+// message so all of them are decoded
+type Error struct {
+	StatusCode   int      `json:"-"`
+	Status       string   `json:"-"`
+	ErrorMessage string   `json:"error_msg"`
+	Detail       string   `json:"detail"`
+	NonField     []string `json:"non_field_errors"`
+}
+
+// Error returns a string for the error and statistifes the error interface
+func (e *Error) Error() string {
+	out := e.Status
+	if e.ErrorMessage != "" {
+		out += ": " + e.ErrorMessage
+	}
+	if e.Detail != "" {
+		out += ": " + e.Detail
+	}
+	if len(e.NonField) > 0 {
+		out += ": " + strings.Join(e.NonField, ", ")
+	}
+	return fmt.Sprintf("seafile error: %s", out)
+}
+
+// Check Error statisfies the error interface
+var _ error = (*Error)(nil)
+
+// AuthToken is returned from the auth-token call
+type AuthToken struct {
+	Token string `json:"token"`
+}
+
+// ServerInfo is returned from the server-info call
+type ServerInfo struct {
+	Version  string   `json:"version"`
+	Features []string `json:"features"`
+}
+
+// Library describes a seafile library (repo)
+type Library struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Owner      string `json:"owner"`
+	Type       string `json:"type"`
+	Encrypted  bool   `json:"encrypted"`
+	Permission string `json:"permission"`
+	Size       int64  `json:"size"`
+	Modified   int64  `json:"mtime"`
+}
+
+// ModTime returns the modification time of the library
+func (l *Library) ModTime() time.Time {
+	return time.Unix(l.Modified, 0)
+}
+
+// CreateLibrary is returned from creating a library
+type CreateLibrary struct {
+	ID   string `json:"repo_id"`
+	Name string `json:"repo_name"`
+}
+
+// Values for DirEntry.Type
+const (
+	EntryTypeFile = "file"
+	EntryTypeDir  = "dir"
+)
+
+// DirEntry describes a file or a directory in a listing
+//
+// The same object is returned from the file detail call
+type DirEntry struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Modified int64  `json:"mtime"`
+}
+
+// IsDir returns true if the entry is a directory
+func (d *DirEntry) IsDir() bool {
+	return d.Type == EntryTypeDir
+}
+
+// ModTime returns the modification time of the entry
+func (d *DirEntry) ModTime() time.Time {
+	return time.Unix(d.Modified, 0)
+}
+
+// FileUploaded is returned for each file after an upload with ret-json=1
+type FileUploaded struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// FileOpResult is returned from the fileops copy and move calls
+type FileOpResult struct {
+	LibraryID string `json:"repo_id"`
+	ParentDir string `json:"parent_dir"`
+	Name      string `json:"obj_name"`
+}
+
+// AccountInfo is returned from the account info call
+//
+// Total is negative if the quota is unlimited
+type AccountInfo struct {
+	Usage int64  `json:"usage"`
+	Total int64  `json:"total"`
+	Email string `json:"email"`
+}
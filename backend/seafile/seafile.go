@@ -0,0 +1,1257 @@
+// Package seafile provides an interface to the Seafile file sync and
+// share server.
+package seafile
+
+// Seafile stores files in libraries (repos) which are a bit like
+// buckets.  If the library isn't set in the config then the first
+// component of the path is used as the library name.
+
+// FIXME Seafile doesn't expose content hashes through the API
+
+// FIXME mod times can't be set - they are always the upload time
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/backend/seafile/api"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/config/obscure"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/lib/pacer"
+	"github.com/ncw/rclone/lib/rest"
+	"github.com/pkg/errors"
+)
+
+const (
+	minSleep        = 100 * time.Millisecond
+	maxSleep        = 2 * time.Second
+	decayConstant   = 2 // bigger for slower decay, exponential
+	configAuthToken = "auth_token"
+	otpHeader       = "X-Seafile-OTP"
+)
+
+// Globals
+var (
+	// Flags
+	seafilePacerMinSleep = flags.DurationP("seafile-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	seafilePacerMaxSleep = flags.DurationP("seafile-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	seafilePacerBurst    = flags.IntP("seafile-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
+)
+
+// Register with Fs
+func init() {
+	fs.Register(&fs.RegInfo{
+		Name:        "seafile",
+		Description: "Seafile",
+		NewFs:       NewFs,
+		Config:      configure,
+		Options: []fs.Option{{
+			Name: "url",
+			Help: "URL of seafile host to connect to",
+			Examples: []fs.OptionExample{{
+				Value: "https://cloud.seafile.com/",
+				Help:  "Connect to cloud.seafile.com",
+			}},
+		}, {
+			Name: "user",
+			Help: "User name (usually email address)",
+		}, {
+			Name:       "pass",
+			Help:       "Password.",
+			IsPassword: true,
+		}, {
+			Name: "2fa",
+			Help: "Two-factor authentication - set to true if the account has 2FA enabled.",
+			Examples: []fs.OptionExample{{
+				Value: "false",
+				Help:  "Log in with the user name and password only",
+			}, {
+				Value: "true",
+				Help:  "Ask for a 2FA code at the end of the config and store an auth token",
+			}},
+		}, {
+			Name:     "library",
+			Help:     "Name of the library. Leave blank to access all libraries, using the first path component as the library name.",
+			Optional: true,
+		}, {
+			Name:       "library_key",
+			Help:       "Library password for encrypted libraries. Leave blank if the library isn't encrypted.",
+			Optional:   true,
+			IsPassword: true,
+		}},
+	})
+}
+
+// Fs represents a remote seafile server
+type Fs struct {
+	name         string       // name of this remote
+	root         string       // the path within the library we are working on
+	features     *fs.Features // optional features
+	endpoint     *url.URL     // URL of the host
+	srv          *rest.Client // the connection to the server
+	pacer        *pacer.Pacer // pacer for API calls
+	user         string       // username
+	pass         string       // password
+	libraryName  string       // name of the library - "" for the list of libraries
+	libraryKey   string       // password to unlock an encrypted library
+	fixedLibrary bool         // set if the library was set in the config
+	libraryMu    sync.Mutex   // protects libraryID
+	libraryID    string       // ID of the library if known
+}
+
+// Object describes a seafile object
+//
+// Will definitely have info but maybe not meta
+type Object struct {
+	fs          *Fs       // what this object is part of
+	remote      string    // The remote path
+	hasMetaData bool      // whether info below has been set
+	id          string    // ID of the object content
+	size        int64     // size of the object
+	modTime     time.Time // modification time of the object
+}
+
+// ------------------------------------------------------------
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	if f.fixedLibrary || f.libraryName == "" {
+		return f.root
+	}
+	return path.Join(f.libraryName, f.root)
+}
+
+// String converts this Fs to a string
+func (f *Fs) String() string {
+	if f.libraryName == "" {
+		return fmt.Sprintf("seafile %s", f.endpoint)
+	}
+	if f.root == "" {
+		return fmt.Sprintf("seafile library '%s'", f.libraryName)
+	}
+	return fmt.Sprintf("seafile library '%s' path '%s'", f.libraryName, f.root)
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// retryErrorCodes is a slice of error codes that we will retry
+var retryErrorCodes = []int{
+	429, // Too Many Requests.
+	500, // Internal Server Error
+	502, // Bad Gateway
+	503, // Service Unavailable
+	504, // Gateway Timeout
+	509, // Bandwidth Limit Exceeded
+}
+
+// shouldRetry returns a boolean as to whether this resp and err
+// deserve to be retried.  It returns the err as a convenience
+func shouldRetry(resp *http.Response, err error) (bool, error) {
+	return fserrors.RetryAfterHTTP(fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
+}
+
+// errorHandler parses a non 2xx error response into an error
+func errorHandler(resp *http.Response) error {
+	// Decode error response
+	errResponse := new(api.Error)
+	err := rest.DecodeJSON(resp, &errResponse)
+	if err != nil {
+		fs.Debugf(nil, "Couldn't decode error response: %v", err)
+	}
+	errResponse.Status = resp.Status
+	errResponse.StatusCode = resp.StatusCode
+	return errResponse
+}
+
+// isNotFound returns true if err is a seafile 404 error
+func isNotFound(err error) bool {
+	if apiErr, ok := errors.Cause(err).(*api.Error); ok {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// formOpts makes an Opts for a form encoded POST of values to path
+func formOpts(path string, values url.Values) rest.Opts {
+	return rest.Opts{
+		Method:      "POST",
+		Path:        path,
+		ContentType: "application/x-www-form-urlencoded",
+		Body:        strings.NewReader(values.Encode()),
+		Parameters:  url.Values{},
+	}
+}
+
+// splitLibrary splits a path into a library name and the path
+// within the library
+func splitLibrary(root string) (libraryName, libraryPath string) {
+	i := strings.IndexRune(root, '/')
+	if i < 0 {
+		return root, ""
+	}
+	return root[:i], strings.Trim(root[i+1:], "/")
+}
+
+// newFs makes an Fs from the config without authenticating it
+func newFs(name, root string) (*Fs, error) {
+	endpoint := config.FileGet(name, "url")
+	if endpoint == "" {
+		return nil, errors.New("url not set in config")
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't parse url")
+	}
+	pass := config.FileGet(name, "pass")
+	if pass != "" {
+		pass, err = obscure.Reveal(pass)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't decrypt password")
+		}
+	}
+	libraryKey := config.FileGet(name, "library_key")
+	if libraryKey != "" {
+		libraryKey, err = obscure.Reveal(libraryKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't decrypt library_key")
+		}
+	}
+	f := &Fs{
+		name:        name,
+		root:        strings.Trim(root, "/"),
+		endpoint:    u,
+		srv:         rest.NewClient(fshttp.NewClient(fs.Config)).SetRoot(u.String()),
+		pacer:       pacer.New().SetMinSleep(*seafilePacerMinSleep).SetMaxSleep(*seafilePacerMaxSleep).SetBurst(*seafilePacerBurst).SetDecayConstant(decayConstant).SetName(name),
+		user:        config.FileGet(name, "user"),
+		pass:        pass,
+		libraryName: config.FileGet(name, "library"),
+		libraryKey:  libraryKey,
+	}
+	f.fixedLibrary = f.libraryName != ""
+	if !f.fixedLibrary {
+		f.libraryName, f.root = splitLibrary(f.root)
+	}
+	f.srv.SetErrorHandler(errorHandler)
+	return f, nil
+}
+
+// NewFs constructs an Fs from the path, library:path
+func NewFs(name, root string) (fs.Fs, error) {
+	f, err := newFs(name, root)
+	if err != nil {
+		return nil, err
+	}
+	f.features = (&fs.Features{
+		CanHaveEmptyDirectories: true,
+		BucketBased:             !f.fixedLibrary,
+	}).Fill(f)
+
+	token := config.FileGet(name, configAuthToken)
+	if token == "" {
+		if config.FileGetBool(name, "2fa", false) {
+			return nil, errors.New("no auth token found for 2FA account - run \"rclone config\" to log in again")
+		}
+		token, err = f.getAuthToken("")
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.srv.SetHeader("Authorization", "Token "+token)
+
+	if f.libraryName != "" && f.root != "" {
+		// Check to see if the root actually an existing file
+		oldRoot := f.root
+		remote := path.Base(oldRoot)
+		f.root = path.Dir(oldRoot)
+		if f.root == "." {
+			f.root = ""
+		}
+		_, err := f.NewObject(remote)
+		if err != nil {
+			if errors.Cause(err) == fs.ErrorObjectNotFound || errors.Cause(err) == fs.ErrorNotAFile {
+				// File doesn't exist so return old f
+				f.root = oldRoot
+				return f, nil
+			}
+			return nil, err
+		}
+		// return an error with an fs which points to the parent
+		return f, fs.ErrorIsFile
+	}
+	return f, nil
+}
+
+// configure is called at the end of the config to ask for a 2FA
+// code if required and store the resulting auth token
+func configure(name string) {
+	if !config.FileGetBool(name, "2fa", false) {
+		config.FileDeleteKey(name, configAuthToken)
+		return
+	}
+	f, err := newFs(name, "")
+	if err != nil {
+		fs.Errorf(nil, "Failed to configure seafile: %v", err)
+		return
+	}
+	for {
+		fmt.Printf("Two-factor authentication code\ncode> ")
+		code := config.ReadLine()
+		if code == "" {
+			continue
+		}
+		token, err := f.getAuthToken(code)
+		if err != nil {
+			fmt.Printf("Failed to log in: %v\n", err)
+			continue
+		}
+		config.FileSet(name, configAuthToken, token)
+		return
+	}
+}
+
+// getAuthToken swaps the user name and password for an auth token
+//
+// otp is the 2FA code and should be "" if 2FA isn't in use
+func (f *Fs) getAuthToken(otp string) (token string, err error) {
+	if f.user == "" || f.pass == "" {
+		return "", errors.New("user and pass must be set in the config")
+	}
+	values := url.Values{}
+	values.Set("username", f.user)
+	values.Set("password", f.pass)
+	opts := formOpts("api2/auth-token/", values)
+	if otp != "" {
+		opts.ExtraHeaders = map[string]string{otpHeader: otp}
+	}
+	var resp *http.Response
+	var result api.AuthToken
+	err = f.pacer.Call(func() (bool, error) {
+		opts.Body = strings.NewReader(values.Encode())
+		resp, err = f.srv.CallJSON(&opts, nil, &result)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		if resp != nil && resp.Header.Get(otpHeader) == "required" {
+			return "", errors.New("two-factor authentication code required - set 2fa = true and run \"rclone config\"")
+		}
+		return "", errors.Wrap(err, "failed to get auth token")
+	}
+	if result.Token == "" {
+		return "", errors.New("no auth token returned")
+	}
+	return result.Token, nil
+}
+
+// listLibraries returns all the libraries the user has access to
+func (f *Fs) listLibraries() (libraries []api.Library, err error) {
+	opts := rest.Opts{
+		Method: "GET",
+		Path:   "api2/repos/",
+	}
+	var resp *http.Response
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.srv.CallJSON(&opts, nil, &libraries)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list libraries")
+	}
+	return libraries, nil
+}
+
+// unlockLibrary sends the library key for an encrypted library
+func (f *Fs) unlockLibrary(libraryID string) error {
+	if f.libraryKey == "" {
+		return errors.Errorf("library %q is encrypted - set library_key in the config", f.libraryName)
+	}
+	values := url.Values{}
+	values.Set("password", f.libraryKey)
+	opts := formOpts("api2/repos/"+libraryID+"/", values)
+	opts.NoResponse = true
+	return f.pacer.Call(func() (bool, error) {
+		opts.Body = strings.NewReader(values.Encode())
+		resp, err := f.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+}
+
+// getLibraryID finds the ID of the library we are working on,
+// unlocking it if it is encrypted.
+//
+// It returns fs.ErrorDirNotFound if the library doesn't exist
+func (f *Fs) getLibraryID() (string, error) {
+	f.libraryMu.Lock()
+	defer f.libraryMu.Unlock()
+	if f.libraryID != "" {
+		return f.libraryID, nil
+	}
+	if f.libraryName == "" {
+		return "", fs.ErrorListBucketRequired
+	}
+	libraries, err := f.listLibraries()
+	if err != nil {
+		return "", err
+	}
+	for _, library := range libraries {
+		if library.Name != f.libraryName {
+			continue
+		}
+		if library.Encrypted {
+			err = f.unlockLibrary(library.ID)
+			if err != nil {
+				return "", errors.Wrap(err, "couldn't unlock library")
+			}
+		}
+		f.libraryID = library.ID
+		return f.libraryID, nil
+	}
+	return "", fs.ErrorDirNotFound
+}
+
+// createLibrary makes the library, encrypting it with the
+// library key if set
+func (f *Fs) createLibrary() error {
+	f.libraryMu.Lock()
+	defer f.libraryMu.Unlock()
+	values := url.Values{}
+	values.Set("name", f.libraryName)
+	if f.libraryKey != "" {
+		values.Set("passwd", f.libraryKey)
+	}
+	opts := formOpts("api2/repos/", values)
+	var resp *http.Response
+	var result api.CreateLibrary
+	err := f.pacer.Call(func() (bool, error) {
+		opts.Body = strings.NewReader(values.Encode())
+		var err error
+		resp, err = f.srv.CallJSON(&opts, nil, &result)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "couldn't create library")
+	}
+	f.libraryID = result.ID
+	return nil
+}
+
+// deleteLibrary removes the library and everything in it
+func (f *Fs) deleteLibrary() error {
+	libraryID, err := f.getLibraryID()
+	if err != nil {
+		return err
+	}
+	f.libraryMu.Lock()
+	defer f.libraryMu.Unlock()
+	opts := rest.Opts{
+		Method:     "DELETE",
+		Path:       "api2/repos/" + libraryID + "/",
+		NoResponse: true,
+	}
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "couldn't delete library")
+	}
+	f.libraryID = ""
+	return nil
+}
+
+// libraryPath returns the absolute path of remote within the library
+func (f *Fs) libraryPath(remote string) string {
+	return "/" + path.Join(f.root, remote)
+}
+
+// splitPath splits an absolute library path into the parent
+// directory and the leaf
+func splitPath(p string) (dir, leaf string) {
+	dir, leaf = path.Split(p)
+	if dir != "/" {
+		dir = strings.TrimRight(dir, "/")
+	}
+	return dir, leaf
+}
+
+// listDirPath lists the absolute path p in the library
+//
+// It returns fs.ErrorDirNotFound if it doesn't exist
+func (f *Fs) listDirPath(libraryID, p string) (items []api.DirEntry, err error) {
+	opts := rest.Opts{
+		Method:     "GET",
+		Path:       "api2/repos/" + libraryID + "/dir/",
+		Parameters: url.Values{},
+	}
+	opts.Parameters.Set("p", p)
+	var resp *http.Response
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.srv.CallJSON(&opts, nil, &items)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fs.ErrorDirNotFound
+		}
+		return nil, errors.Wrap(err, "couldn't list directory")
+	}
+	return items, nil
+}
+
+// mkdirAll makes the absolute path p in the library along with any
+// parents which are missing
+func (f *Fs) mkdirAll(libraryID, p string) error {
+	if p == "/" {
+		return nil
+	}
+	_, err := f.listDirPath(libraryID, p)
+	if err == nil {
+		return nil
+	} else if err != fs.ErrorDirNotFound {
+		return err
+	}
+	parent, _ := splitPath(p)
+	err = f.mkdirAll(libraryID, parent)
+	if err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set("operation", "mkdir")
+	opts := formOpts("api/v2.1/repos/"+libraryID+"/dir/", values)
+	opts.Parameters.Set("p", p)
+	opts.NoResponse = true
+	err = f.pacer.Call(func() (bool, error) {
+		opts.Body = strings.NewReader(values.Encode())
+		resp, err := f.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "couldn't make directory")
+	}
+	return nil
+}
+
+// listLibrariesDir returns the libraries as directories
+func (f *Fs) listLibrariesDir(dir string) (entries fs.DirEntries, err error) {
+	if dir != "" {
+		return nil, fs.ErrorListBucketRequired
+	}
+	libraries, err := f.listLibraries()
+	if err != nil {
+		return nil, err
+	}
+	for _, library := range libraries {
+		d := fs.NewDir(library.Name, library.ModTime()).SetID(library.ID).SetSize(library.Size)
+		entries = append(entries, d)
+	}
+	return entries, nil
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
+	if f.libraryName == "" {
+		return f.listLibrariesDir(dir)
+	}
+	libraryID, err := f.getLibraryID()
+	if err != nil {
+		return nil, err
+	}
+	items, err := f.listDirPath(libraryID, f.libraryPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		item := &items[i]
+		remote := path.Join(dir, item.Name)
+		if item.IsDir() {
+			d := fs.NewDir(remote, item.ModTime()).SetID(item.ID)
+			entries = append(entries, d)
+		} else {
+			o, err := f.newObjectWithInfo(remote, item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, o)
+		}
+	}
+	return entries, nil
+}
+
+// Return an Object from a path
+//
+// If it can't be found it returns the error fs.ErrorObjectNotFound.
+func (f *Fs) newObjectWithInfo(remote string, info *api.DirEntry) (fs.Object, error) {
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	var err error
+	if info != nil {
+		// Set info
+		err = o.setMetaData(info)
+	} else {
+		err = o.readMetaData() // reads info and meta, returning an error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// NewObject finds the Object at remote.  If it can't be found
+// it returns the error fs.ErrorObjectNotFound.
+func (f *Fs) NewObject(remote string) (fs.Object, error) {
+	return f.newObjectWithInfo(remote, nil)
+}
+
+// Put the object into the library
+//
+// Copy the reader in to the new object which is returned
+//
+// The new object may have been created if an error is returned
+func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	// Temporary Object under construction
+	o := &Object{
+		fs:     f,
+		remote: src.Remote(),
+	}
+	return o, o.Update(in, src, options...)
+}
+
+// Mkdir creates the library and directory if they don't exist
+func (f *Fs) Mkdir(dir string) error {
+	if f.libraryName == "" {
+		if dir != "" {
+			return fs.ErrorListBucketRequired
+		}
+		return nil
+	}
+	libraryID, err := f.getLibraryID()
+	if err == fs.ErrorDirNotFound {
+		err = f.createLibrary()
+		if err != nil {
+			return err
+		}
+		libraryID, err = f.getLibraryID()
+	}
+	if err != nil {
+		return err
+	}
+	return f.mkdirAll(libraryID, f.libraryPath(dir))
+}
+
+// purgeCheck removes the directory dir, if check is set then it
+// refuses to do so if it has anything in
+//
+// If this is the root of a library which wasn't fixed in the config
+// then the library is removed
+func (f *Fs) purgeCheck(dir string, check bool) error {
+	if f.libraryName == "" {
+		if dir != "" {
+			return fs.ErrorListBucketRequired
+		}
+		return errors.New("can't purge the list of libraries")
+	}
+	libraryID, err := f.getLibraryID()
+	if err != nil {
+		return err
+	}
+	dirPath := f.libraryPath(dir)
+	if check {
+		items, err := f.listDirPath(libraryID, dirPath)
+		if err != nil {
+			return err
+		}
+		if len(items) != 0 {
+			return fs.ErrorDirectoryNotEmpty
+		}
+	}
+	if dirPath == "/" {
+		if f.fixedLibrary {
+			return errors.New("can't remove the root of the library")
+		}
+		return f.deleteLibrary()
+	}
+	opts := rest.Opts{
+		Method:     "DELETE",
+		Path:       "api2/repos/" + libraryID + "/dir/",
+		Parameters: url.Values{},
+		NoResponse: true,
+	}
+	opts.Parameters.Set("p", dirPath)
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "rmdir failed")
+	}
+	return nil
+}
+
+// Rmdir deletes the directory or the library if at the root
+//
+// Returns an error if it isn't empty
+func (f *Fs) Rmdir(dir string) error {
+	return f.purgeCheck(dir, true)
+}
+
+// Purge deletes all the files and the directory
+//
+// Optional interface: Only implement this if you have a way of
+// deleting all the files quicker than just running Remove() on the
+// result of List()
+func (f *Fs) Purge() error {
+	return f.purgeCheck("", false)
+}
+
+// Precision return the precision of this Fs
+func (f *Fs) Precision() time.Duration {
+	return fs.ModTimeNotSupported
+}
+
+// fileOp copies or moves srcPath in srcLibraryID into the directory
+// dstDir of libraryID.  op should be "copy" or "move".
+//
+// It returns the leaf name the server gave to the new object.
+func (f *Fs) fileOp(op string, srcLibraryID, srcPath, libraryID, dstDir string) (leaf string, err error) {
+	srcDir, srcLeaf := splitPath(srcPath)
+	values := url.Values{}
+	values.Set("file_names", srcLeaf)
+	values.Set("dst_repo", libraryID)
+	values.Set("dst_dir", dstDir)
+	opts := formOpts("api2/repos/"+srcLibraryID+"/fileops/"+op+"/", values)
+	opts.Parameters.Set("p", srcDir)
+	var resp *http.Response
+	var result []api.FileOpResult
+	err = f.pacer.Call(func() (bool, error) {
+		opts.Body = strings.NewReader(values.Encode())
+		resp, err = f.srv.CallJSON(&opts, nil, &result)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "%s failed", op)
+	}
+	if len(result) == 0 || result[0].Name == "" {
+		return srcLeaf, nil
+	}
+	return result[0].Name, nil
+}
+
+// rename renames file or directory p in libraryID to newName
+func (f *Fs) rename(libraryID, p, newName string, isDir bool) error {
+	kind := "file"
+	if isDir {
+		kind = "dir"
+	}
+	values := url.Values{}
+	values.Set("operation", "rename")
+	values.Set("newname", newName)
+	opts := formOpts("api2/repos/"+libraryID+"/"+kind+"/", values)
+	opts.Parameters.Set("p", p)
+	opts.NoResponse = true
+	err := f.pacer.Call(func() (bool, error) {
+		opts.Body = strings.NewReader(values.Encode())
+		resp, err := f.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "rename failed")
+	}
+	return nil
+}
+
+// copyOrMove does a server side copy or move of src to remote
+func (f *Fs) copyOrMove(op string, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't %s - not same remote type", op)
+		return nil, fs.ErrorCantCopy
+	}
+	srcLibraryID, err := srcObj.fs.getLibraryID()
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(remote)
+	if dir == "." {
+		dir = ""
+	}
+	err = f.Mkdir(dir)
+	if err != nil {
+		return nil, err
+	}
+	libraryID, err := f.getLibraryID()
+	if err != nil {
+		return nil, err
+	}
+	// Seafile renames rather than overwrites existing objects
+	// so remove the destination first if it exists
+	if dstObj, err := f.NewObject(remote); err == nil {
+		err = dstObj.Remove()
+		if err != nil {
+			return nil, err
+		}
+	}
+	srcPath := srcObj.fs.libraryPath(srcObj.remote)
+	dstDir, dstLeaf := splitPath(f.libraryPath(remote))
+	srcDir, _ := splitPath(srcPath)
+	if op == "move" && srcLibraryID == libraryID && srcDir == dstDir {
+		// Moving within the same directory is just a rename
+		err = f.rename(libraryID, srcPath, dstLeaf, false)
+	} else {
+		var leaf string
+		leaf, err = f.fileOp(op, srcLibraryID, srcPath, libraryID, dstDir)
+		if err == nil && leaf != dstLeaf {
+			err = f.rename(libraryID, path.Join(dstDir, leaf), dstLeaf, false)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f.NewObject(remote)
+}
+
+// Copy src to this remote using server side copy operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantCopy
+func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
+	return f.copyOrMove("copy", src, remote)
+}
+
+// Move src to this remote using server side move operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantMove
+func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
+	dst, err := f.copyOrMove("move", src, remote)
+	if err == fs.ErrorCantCopy {
+		err = fs.ErrorCantMove
+	}
+	return dst, err
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote
+// using server side move operations.
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantDirMove
+//
+// If destination exists then return fs.ErrorDirExists
+func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
+	srcFs, ok := src.(*Fs)
+	if !ok {
+		fs.Debugf(srcFs, "Can't move directory - not same remote type")
+		return fs.ErrorCantDirMove
+	}
+	srcPath := srcFs.libraryPath(srcRemote)
+	dstPath := f.libraryPath(dstRemote)
+	if srcPath == "/" || dstPath == "/" {
+		fs.Debugf(src, "DirMove error: Can't move library root")
+		return fs.ErrorCantDirMove
+	}
+	srcLibraryID, err := srcFs.getLibraryID()
+	if err != nil {
+		return err
+	}
+	dstDir, dstLeaf := splitPath(dstPath)
+	dir := path.Dir(dstRemote)
+	if dir == "." {
+		dir = ""
+	}
+	err = f.Mkdir(dir)
+	if err != nil {
+		return err
+	}
+	libraryID, err := f.getLibraryID()
+	if err != nil {
+		return err
+	}
+
+	// Check destination does not exist
+	_, err = f.listDirPath(libraryID, dstPath)
+	if err == nil {
+		return fs.ErrorDirExists
+	} else if err != fs.ErrorDirNotFound {
+		return err
+	}
+
+	srcDir, _ := splitPath(srcPath)
+	if srcLibraryID == libraryID && srcDir == dstDir {
+		return f.rename(libraryID, srcPath, dstLeaf, true)
+	}
+	leaf, err := f.fileOp("move", srcLibraryID, srcPath, libraryID, dstDir)
+	if err != nil {
+		return err
+	}
+	if leaf != dstLeaf {
+		return f.rename(libraryID, path.Join(dstDir, leaf), dstLeaf, true)
+	}
+	return nil
+}
+
+// cleanUpLibrary empties the trash of libraryID
+func (f *Fs) cleanUpLibrary(libraryID string) error {
+	opts := rest.Opts{
+		Method:     "DELETE",
+		Path:       "api/v2.1/repos/" + libraryID + "/trash/",
+		NoResponse: true,
+	}
+	return f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+}
+
+// CleanUp empties the trash of the library, or of all the
+// libraries if none is selected
+func (f *Fs) CleanUp() error {
+	if f.libraryName != "" {
+		libraryID, err := f.getLibraryID()
+		if err != nil {
+			return err
+		}
+		return f.cleanUpLibrary(libraryID)
+	}
+	libraries, err := f.listLibraries()
+	if err != nil {
+		return err
+	}
+	for _, library := range libraries {
+		if library.Permission != "rw" {
+			continue
+		}
+		err = f.cleanUpLibrary(library.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to clean up library %q", library.Name)
+		}
+	}
+	return nil
+}
+
+// About gets quota information
+func (f *Fs) About() (usage *fs.Usage, err error) {
+	opts := rest.Opts{
+		Method: "GET",
+		Path:   "api2/account/info/",
+	}
+	var resp *http.Response
+	var info api.AccountInfo
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.srv.CallJSON(&opts, nil, &info)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "about failed")
+	}
+	usage = &fs.Usage{
+		Used: fs.NewUsageValue(info.Usage), // bytes in use
+	}
+	if info.Total >= 0 {
+		usage.Total = fs.NewUsageValue(info.Total)             // quota of bytes that can be used
+		usage.Free = fs.NewUsageValue(info.Total - info.Usage) // bytes which can be uploaded before reaching the quota
+	}
+	return usage, nil
+}
+
+// Hashes returns the supported hash sets.
+func (f *Fs) Hashes() hash.Set {
+	return hash.Set(hash.None)
+}
+
+// ------------------------------------------------------------
+
+// Fs returns the parent Fs
+func (o *Object) Fs() fs.Info {
+	return o.fs
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// Hash returns the hash of an object - seafile doesn't support any
+func (o *Object) Hash(t hash.Type) (string, error) {
+	return "", hash.ErrUnsupported
+}
+
+// Size returns the size of an object in bytes
+func (o *Object) Size() int64 {
+	err := o.readMetaData()
+	if err != nil {
+		fs.Logf(o, "Failed to read metadata: %v", err)
+		return 0
+	}
+	return o.size
+}
+
+// setMetaData sets the metadata from info
+func (o *Object) setMetaData(info *api.DirEntry) (err error) {
+	if info.IsDir() {
+		return errors.Wrapf(fs.ErrorNotAFile, "%q is a folder", o.remote)
+	}
+	o.hasMetaData = true
+	o.id = info.ID
+	o.size = info.Size
+	o.modTime = info.ModTime()
+	return nil
+}
+
+// readMetaData gets the metadata if it hasn't already been fetched
+//
+// it also sets the info
+func (o *Object) readMetaData() (err error) {
+	if o.hasMetaData {
+		return nil
+	}
+	libraryID, err := o.fs.getLibraryID()
+	if err != nil {
+		if err == fs.ErrorDirNotFound {
+			return fs.ErrorObjectNotFound
+		}
+		return err
+	}
+	opts := rest.Opts{
+		Method:     "GET",
+		Path:       "api2/repos/" + libraryID + "/file/detail/",
+		Parameters: url.Values{},
+	}
+	opts.Parameters.Set("p", o.filePath())
+	var resp *http.Response
+	var info api.DirEntry
+	err = o.fs.pacer.Call(func() (bool, error) {
+		resp, err = o.fs.srv.CallJSON(&opts, nil, &info)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return fs.ErrorObjectNotFound
+		}
+		return errors.Wrap(err, "read metadata failed")
+	}
+	if info.Type == "" {
+		info.Type = api.EntryTypeFile
+	}
+	return o.setMetaData(&info)
+}
+
+// filePath returns the absolute path of the object within the library
+func (o *Object) filePath() string {
+	return o.fs.libraryPath(o.remote)
+}
+
+// ModTime returns the modification time of the object
+//
+// Seafile only keeps the time the object was uploaded
+func (o *Object) ModTime() time.Time {
+	err := o.readMetaData()
+	if err != nil {
+		fs.Logf(o, "Failed to read metadata: %v", err)
+		return time.Now()
+	}
+	return o.modTime
+}
+
+// SetModTime sets the modification time of the local fs object
+func (o *Object) SetModTime(modTime time.Time) error {
+	// Seafile doesn't have a way of doing this so returning this
+	// error will cause the file to be re-uploaded to set the time.
+	return fs.ErrorCantSetModTime
+}
+
+// Storable returns a boolean showing whether this object storable
+func (o *Object) Storable() bool {
+	return true
+}
+
+// getLink fetches a download or upload link for p
+//
+// kind should be "file" for a download link or "upload-link"
+func (o *Object) getLink(libraryID, kind, p string) (link string, err error) {
+	opts := rest.Opts{
+		Method:     "GET",
+		Path:       "api2/repos/" + libraryID + "/" + kind + "/",
+		Parameters: url.Values{},
+	}
+	opts.Parameters.Set("p", p)
+	if kind == "file" {
+		opts.Parameters.Set("reuse", "1")
+	}
+	var resp *http.Response
+	err = o.fs.pacer.Call(func() (bool, error) {
+		resp, err = o.fs.srv.CallJSON(&opts, nil, &link)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return "", fs.ErrorObjectNotFound
+		}
+		return "", err
+	}
+	if link == "" {
+		return "", errors.Errorf("empty %s link returned", kind)
+	}
+	return link, nil
+}
+
+// Open an object for read
+func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
+	libraryID, err := o.fs.getLibraryID()
+	if err != nil {
+		return nil, err
+	}
+	downloadURL, err := o.getLink(libraryID, "file", o.filePath())
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get download link")
+	}
+	var resp *http.Response
+	opts := rest.Opts{
+		Method:  "GET",
+		RootURL: downloadURL,
+		Options: options,
+	}
+	err = o.fs.pacer.Call(func() (bool, error) {
+		resp, err = o.fs.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, err
+}
+
+// Update the object with the contents of the io.Reader, modTime and size
+//
+// If existing is set then it updates the object rather than creating a new one
+//
+// The new object may have been created if an error is returned
+func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (err error) {
+	dir := path.Dir(o.remote)
+	if dir == "." {
+		dir = ""
+	}
+	err = o.fs.Mkdir(dir)
+	if err != nil {
+		return err
+	}
+	libraryID, err := o.fs.getLibraryID()
+	if err != nil {
+		return err
+	}
+	dir, leaf := splitPath(o.filePath())
+	uploadURL, err := o.getLink(libraryID, "upload-link", dir)
+	if err != nil {
+		return errors.Wrap(err, "couldn't get upload link")
+	}
+
+	// The upload link can only be used once so the upload can't
+	// be retried
+	var resp *http.Response
+	var result []api.FileUploaded
+	opts := rest.Opts{
+		Method:               "POST",
+		RootURL:              uploadURL,
+		Body:                 in,
+		MultipartParams:      url.Values{},
+		MultipartContentName: "file",
+		MultipartFileName:    leaf,
+		Parameters:           url.Values{},
+	}
+	opts.MultipartParams.Set("parent_dir", dir)
+	opts.MultipartParams.Set("replace", "1")
+	opts.Parameters.Set("ret-json", "1")
+	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
+		resp, err = o.fs.srv.CallJSON(&opts, nil, &result)
+		return shouldRetry(resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "upload failed")
+	}
+	if len(result) != 1 {
+		return errors.Errorf("failed to upload %v - not sure why", o)
+	}
+
+	// Read the metadata from the newly created object
+	o.hasMetaData = false
+	return o.readMetaData()
+}
+
+// Remove an object
+func (o *Object) Remove() error {
+	libraryID, err := o.fs.getLibraryID()
+	if err != nil {
+		return err
+	}
+	opts := rest.Opts{
+		Method:     "DELETE",
+		Path:       "api2/repos/" + libraryID + "/file/",
+		Parameters: url.Values{},
+		NoResponse: true,
+	}
+	opts.Parameters.Set("p", o.filePath())
+	return o.fs.pacer.Call(func() (bool, error) {
+		resp, err := o.fs.srv.Call(&opts)
+		return shouldRetry(resp, err)
+	})
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs         = (*Fs)(nil)
+	_ fs.Purger     = (*Fs)(nil)
+	_ fs.CleanUpper = (*Fs)(nil)
+	_ fs.Copier     = (*Fs)(nil)
+	_ fs.Mover      = (*Fs)(nil)
+	_ fs.DirMover   = (*Fs)(nil)
+	_ fs.Abouter    = (*Fs)(nil)
+	_ fs.Object     = (*Object)(nil)
+)
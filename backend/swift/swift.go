@@ -4,8 +4,10 @@ package swift
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"path"
 	"regexp"
 	"strconv"
@@ -21,6 +23,7 @@ import (
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/readers"
 	"github.com/ncw/swift"
 	"github.com/pkg/errors"
 )
@@ -29,11 +32,13 @@ import (
 const (
 	directoryMarkerContentType = "application/directory" // content type of directory marker objects
 	listChunks                 = 1000                    // chunk size to read directory listings
+	maxChunkSize               = 5 * 1024 * 1024 * 1024  // largest object swift accepts in a single PUT
 )
 
 // Globals
 var (
-	chunkSize = fs.SizeSuffix(5 * 1024 * 1024 * 1024)
+	chunkSize = fs.SizeSuffix(maxChunkSize)
+	useSLO    = flags.BoolP("swift-use-slo", "", false, "Upload large files as Static Large Objects instead of Dynamic Large Objects.")
 )
 
 // Register with Fs
@@ -122,6 +127,9 @@ func init() {
 				Help:  "Admin",
 				Value: "admin",
 			}},
+		}, {
+			Name: "segments_container",
+			Help: "Container to store the segments of large objects in - optional.\nDefaults to the container name with _segments appended.",
 		},
 		},
 	})
@@ -138,6 +146,7 @@ type Fs struct {
 	containerOKMu     sync.Mutex        // mutex to protect container OK
 	containerOK       bool              // true if we have created the container
 	segmentsContainer string            // container to store the segments (if any) in
+	segmentsPrefix    string            // prefix for the segments in segmentsContainer
 	noCheckContainer  bool              // don't check the container before creating it
 }
 
@@ -256,6 +265,9 @@ func NewFsWithConnection(name, root string, c *swift.Connection, noCheckContaine
 	if err != nil {
 		return nil, err
 	}
+	if chunkSize > maxChunkSize {
+		return nil, errors.Errorf("swift chunk size %v is too big - must be <= %v", chunkSize, fs.SizeSuffix(maxChunkSize))
+	}
 	f := &Fs{
 		name:              name,
 		c:                 c,
@@ -264,6 +276,13 @@ func NewFsWithConnection(name, root string, c *swift.Connection, noCheckContaine
 		root:              directory,
 		noCheckContainer:  noCheckContainer,
 	}
+	// If the user has chosen a segments container then it may be
+	// shared between containers so put the segments under the
+	// container name
+	if segmentsContainer := config.FileGet(name, "segments_container"); segmentsContainer != "" {
+		f.segmentsContainer = segmentsContainer
+		f.segmentsPrefix = container + "/"
+	}
 	f.features = (&fs.Features{
 		ReadMimeType:  true,
 		WriteMimeType: true,
@@ -629,6 +648,18 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 		fs.Debugf(src, "Can't copy - not same remote type")
 		return nil, fs.ErrorCantCopy
 	}
+	// Large objects can't be copied server side without either
+	// sharing the segments with the source or being limited to
+	// the maximum size of a single object, so copy them the slow
+	// way.
+	isLargeObject, err := srcObj.isLargeObject()
+	if err != nil {
+		return nil, err
+	}
+	if isLargeObject {
+		fs.Debugf(src, "Can't copy - large object")
+		return nil, fs.ErrorCantCopy
+	}
 	srcFs := srcObj.fs
 	_, err = f.c.ObjectCopy(srcFs.container, srcFs.root+srcObj.remote, f.container, f.root+remote, nil)
 	if err != nil {
@@ -667,15 +698,11 @@ func (o *Object) Hash(t hash.Type) (string, error) {
 	if t != hash.MD5 {
 		return "", hash.ErrUnsupported
 	}
-	isDynamicLargeObject, err := o.isDynamicLargeObject()
-	if err != nil {
-		return "", err
-	}
-	isStaticLargeObject, err := o.isStaticLargeObject()
+	isLargeObject, err := o.isLargeObject()
 	if err != nil {
 		return "", err
 	}
-	if isDynamicLargeObject || isStaticLargeObject {
+	if isLargeObject {
 		fs.Debugf(o, "Returning empty Md5sum for swift large object")
 		return "", nil
 	}
@@ -692,8 +719,8 @@ func (o *Object) hasHeader(header string) (bool, error) {
 		}
 		return false, err
 	}
-	_, isDynamicLargeObject := o.headers[header]
-	return isDynamicLargeObject, nil
+	_, hasHeader := o.headers[header]
+	return hasHeader, nil
 }
 
 // isDynamicLargeObject checks for X-Object-Manifest header
@@ -706,6 +733,18 @@ func (o *Object) isStaticLargeObject() (bool, error) {
 	return o.hasHeader("X-Static-Large-Object")
 }
 
+// isLargeObject checks whether o is a dynamic or a static large object
+func (o *Object) isLargeObject() (bool, error) {
+	isDynamicLargeObject, err := o.isDynamicLargeObject()
+	if err != nil {
+		return false, err
+	}
+	if isDynamicLargeObject {
+		return true, nil
+	}
+	return o.isStaticLargeObject()
+}
+
 // Size returns the size of an object in bytes
 func (o *Object) Size() int64 {
 	return o.info.Bytes
@@ -803,7 +842,7 @@ func min(x, y int64) int64 {
 //
 // if except is passed in then segments with that prefix won't be deleted
 func (o *Object) removeSegments(except string) error {
-	segmentsRoot := o.fs.root + o.remote + "/"
+	segmentsRoot := o.fs.segmentsPrefix + o.fs.root + o.remote + "/"
 	err := o.fs.listContainerRoot(o.fs.segmentsContainer, segmentsRoot, "", true, func(remote string, object *swift.Object, isDirectory bool) error {
 		if isDirectory {
 			return nil
@@ -844,8 +883,45 @@ func urlEncode(str string) string {
 	return buf.String()
 }
 
+// sloSegment is an entry in a static large object manifest
+type sloSegment struct {
+	Path string `json:"path"`
+	Etag string `json:"etag"`
+	Size int64  `json:"size_bytes"`
+}
+
+// putSLOManifest uploads a static large object manifest for the
+// segments passed in to o
+func (o *Object) putSLOManifest(segments []sloSegment, headers swift.Headers, contentType string) error {
+	manifest, err := json.Marshal(segments)
+	if err != nil {
+		return errors.Wrap(err, "failed to make SLO manifest")
+	}
+	headers["Content-Length"] = strconv.Itoa(len(manifest))
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	storageURL := func() (string, error) {
+		return o.fs.c.StorageUrl, nil
+	}
+	_, _, err = o.fs.c.Call(o.fs.c.StorageUrl, swift.RequestOpts{
+		Container:  o.fs.container,
+		ObjectName: o.fs.root + o.remote,
+		Operation:  "PUT",
+		Parameters: url.Values{"multipart-manifest": {"put"}},
+		Headers:    headers,
+		Body:       bytes.NewReader(manifest),
+		NoResponse: true,
+		OnReAuth:   storageURL,
+	})
+	return err
+}
+
 // updateChunks updates the existing object using chunks to a separate
 // container.  It returns a string which prefixes current segments.
+//
+// The object is uploaded as a dynamic large object unless
+// --swift-use-slo is set.
 func (o *Object) updateChunks(in0 io.Reader, headers swift.Headers, size int64, contentType string) (string, error) {
 	// Create the segmentsContainer if it doesn't exist
 	var err error
@@ -860,7 +936,8 @@ func (o *Object) updateChunks(in0 io.Reader, headers swift.Headers, size int64,
 	left := size
 	i := 0
 	uniquePrefix := fmt.Sprintf("%s/%d", swift.TimeToFloatString(time.Now()), size)
-	segmentsPath := fmt.Sprintf("%s%s/%s", o.fs.root, o.remote, uniquePrefix)
+	segmentsPath := fmt.Sprintf("%s%s%s/%s", o.fs.segmentsPrefix, o.fs.root, o.remote, uniquePrefix)
+	var segments []sloSegment
 	in := bufio.NewReader(in0)
 	for {
 		// can we read at least one byte?
@@ -877,15 +954,31 @@ func (o *Object) updateChunks(in0 io.Reader, headers swift.Headers, size int64,
 			headers["Content-Length"] = strconv.FormatInt(n, 10) // set Content-Length as we know it
 			left -= n
 		}
-		segmentReader := io.LimitReader(in, n)
+		segmentReader := readers.NewCountingReader(io.LimitReader(in, n))
 		segmentPath := fmt.Sprintf("%s/%08d", segmentsPath, i)
 		fs.Debugf(o, "Uploading segment file %q into %q", segmentPath, o.fs.segmentsContainer)
-		_, err := o.fs.c.ObjectPut(o.fs.segmentsContainer, segmentPath, segmentReader, true, "", "", headers)
+		segmentHeaders, err := o.fs.c.ObjectPut(o.fs.segmentsContainer, segmentPath, segmentReader, true, "", "", headers)
 		if err != nil {
 			return "", err
 		}
+		segments = append(segments, sloSegment{
+			Path: "/" + o.fs.segmentsContainer + "/" + segmentPath,
+			Etag: segmentHeaders["Etag"],
+			Size: int64(segmentReader.BytesRead()),
+		})
 		i++
 	}
+	delete(headers, "Content-Length")
+	if *useSLO {
+		if len(segments) == 0 {
+			// A manifest needs at least one segment so upload an
+			// empty object instead
+			headers["Content-Length"] = "0"
+			_, err = o.fs.c.ObjectPut(o.fs.container, o.fs.root+o.remote, bytes.NewReader(nil), true, "", contentType, headers)
+			return "", err
+		}
+		return uniquePrefix + "/", o.putSLOManifest(segments, headers, contentType)
+	}
 	// Upload the manifest
 	headers["X-Object-Manifest"] = urlEncode(fmt.Sprintf("%s/%s", o.fs.segmentsContainer, segmentsPath))
 	headers["Content-Length"] = "0" // set Content-Length as we know it
@@ -915,6 +1008,21 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 		return err
 	}
 
+	// Note the segments of a static large object before starting
+	// so they can be removed afterwards
+	isStaticLargeObject, err := o.isStaticLargeObject()
+	if err != nil {
+		return err
+	}
+	var oldSegmentsContainer string
+	var oldSegments []swift.Object
+	if isStaticLargeObject {
+		oldSegmentsContainer, oldSegments, err = o.fs.c.LargeObjectGetSegments(o.fs.container, o.fs.root+o.remote)
+		if err != nil {
+			return errors.Wrap(err, "failed to read segments of static large object")
+		}
+	}
+
 	// Set the mtime
 	m := swift.Metadata{}
 	m.SetModTime(modTime)
@@ -942,6 +1050,22 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 		}
 	}
 
+	// If file was a static large object then remove the segments
+	// from its manifest which aren't in use any more
+	if isStaticLargeObject {
+		currentSegments := o.fs.segmentsPrefix + o.fs.root + o.remote + "/" + uniquePrefix
+		for _, segment := range oldSegments {
+			if uniquePrefix != "" && oldSegmentsContainer == o.fs.segmentsContainer && strings.HasPrefix(segment.Name, currentSegments) {
+				continue
+			}
+			fs.Debugf(o, "Removing segment file %q in container %q", segment.Name, oldSegmentsContainer)
+			err = o.fs.c.ObjectDelete(oldSegmentsContainer, segment.Name)
+			if err != nil && err != swift.ObjectNotFound {
+				fs.Logf(o, "Failed to remove old segments - carrying on with upload: %v", err)
+			}
+		}
+	}
+
 	// Read the metadata from the newly created object
 	o.headers = nil // wipe old metadata
 	return o.readMetaData()
@@ -949,6 +1073,14 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 
 // Remove an object
 func (o *Object) Remove() error {
+	// Remove static large objects with their segments
+	isStaticLargeObject, err := o.isStaticLargeObject()
+	if err != nil {
+		return err
+	}
+	if isStaticLargeObject {
+		return o.fs.c.LargeObjectDelete(o.fs.container, o.fs.root+o.remote)
+	}
 	isDynamicLargeObject, err := o.isDynamicLargeObject()
 	if err != nil {
 		return err
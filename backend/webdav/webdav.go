@@ -31,6 +31,7 @@ import (
 	"github.com/ncw/rclone/backend/webdav/odrvcookie"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/config/obscure"
 	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/fshttp"
@@ -46,6 +47,14 @@ const (
 	decayConstant = 2 // bigger for slower decay, exponential
 )
 
+// Globals
+var (
+	// Flags
+	webdavPacerMinSleep = flags.DurationP("webdav-pacer-min-sleep", "", minSleep, "Minimum time to sleep between API calls.")
+	webdavPacerMaxSleep = flags.DurationP("webdav-pacer-max-sleep", "", maxSleep, "Maximum time to sleep between API calls when retrying.")
+	webdavPacerBurst    = flags.IntP("webdav-pacer-burst", "", 1, "Number of API calls to allow without sleeping.")
+)
+
 // Register with Fs
 func init() {
 	fs.Register(&fs.RegInfo{
@@ -154,7 +163,7 @@ var retryErrorCodes = []int{
 // shouldRetry returns a boolean as to whether this resp and err
 // deserve to be retried.  It returns the err as a convenience
 func shouldRetry(resp *http.Response, err error) (bool, error) {
-	return fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), err
+	return fserrors.RetryAfterHTTP(fserrors.ShouldRetry(err) || fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
 }
 
 // itemIsDir returns true if the item is a directory
@@ -274,7 +283,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		endpoint:    u,
 		endpointURL: u.String(),
 		srv:         rest.NewClient(fshttp.NewClient(fs.Config)).SetRoot(u.String()).SetUserPass(user, pass),
-		pacer:       pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer:       pacer.New().SetMinSleep(*webdavPacerMinSleep).SetMaxSleep(*webdavPacerMaxSleep).SetBurst(*webdavPacerBurst).SetDecayConstant(decayConstant).SetName(name),
 		user:        user,
 		pass:        pass,
 		precision:   fs.ModTimeNotSupported,
@@ -488,6 +488,32 @@ func (f *Fs) CleanUp() error {
 	return f.yd.EmptyTrash()
 }
 
+// About gets quota information
+func (f *Fs) About() (*fs.Usage, error) {
+	info, err := f.yd.NewDiskInfoRequest().Exec()
+	if err != nil {
+		return nil, errors.Wrap(err, "about failed")
+	}
+	return usageFromDiskInfo(info), nil
+}
+
+// usageFromDiskInfo converts the disk info into an fs.Usage
+//
+// Used can be bigger than Total if the quota has been reduced so Free
+// is worked out signed and doesn't go below 0.
+func usageFromDiskInfo(info *yandex.DiskInfoResponse) *fs.Usage {
+	free := int64(info.TotalSpace) - int64(info.UsedSpace)
+	if free < 0 {
+		free = 0
+	}
+	return &fs.Usage{
+		Total:   fs.NewUsageValue(int64(info.TotalSpace)), // quota of bytes that can be used
+		Used:    fs.NewUsageValue(int64(info.UsedSpace)),  // bytes in use
+		Trashed: fs.NewUsageValue(int64(info.TrashSize)),  // bytes in trash
+		Free:    fs.NewUsageValue(free),                   // bytes which can be uploaded before reaching the quota
+	}
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hash.MD5)
@@ -667,6 +693,7 @@ var (
 	_ fs.CleanUpper  = (*Fs)(nil)
 	_ fs.PutStreamer = (*Fs)(nil)
 	_ fs.ListRer     = (*Fs)(nil)
+	_ fs.Abouter     = (*Fs)(nil)
 	//_ fs.Copier = (*Fs)(nil)
 	_ fs.ListRer   = (*Fs)(nil)
 	_ fs.Object    = (*Object)(nil)
@@ -0,0 +1,62 @@
+package yandex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	yandex "github.com/ncw/rclone/backend/yandex/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends all requests to the test server at host
+type redirectTransport string
+
+func (host redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = "http"
+	req.URL.Host = string(host)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestUsageFromDiskInfo(t *testing.T) {
+	for _, test := range []struct {
+		total, used, trash uint64
+		wantFree           int64
+	}{
+		{total: 100, used: 30, trash: 5, wantFree: 70},
+		{total: 100, used: 100, trash: 0, wantFree: 0},
+		// Over quota
+		{total: 100, used: 150, trash: 0, wantFree: 0},
+		{total: 0, used: 1, trash: 0, wantFree: 0},
+	} {
+		usage := usageFromDiskInfo(&yandex.DiskInfoResponse{
+			TotalSpace: test.total,
+			UsedSpace:  test.used,
+			TrashSize:  test.trash,
+		})
+		require.NotNil(t, usage.Free)
+		assert.Equal(t, test.wantFree, *usage.Free, "%+v", test)
+		assert.Equal(t, int64(test.total), *usage.Total, "%+v", test)
+		assert.Equal(t, int64(test.used), *usage.Used, "%+v", test)
+		assert.Equal(t, int64(test.trash), *usage.Trashed, "%+v", test)
+	}
+}
+
+func TestAbout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/disk/", r.URL.Path)
+		assert.Equal(t, "OAuth token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"trash_size": 10, "total_space": 1000, "used_space": 1200, "system_folders": {}}`))
+	}))
+	defer server.Close()
+	f := &Fs{yd: yandex.NewClient("token", &http.Client{Transport: redirectTransport(server.Listener.Addr().String())})}
+
+	usage, err := f.About()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), *usage.Total)
+	assert.Equal(t, int64(1200), *usage.Used)
+	assert.Equal(t, int64(10), *usage.Trashed)
+	assert.Equal(t, int64(0), *usage.Free)
+}
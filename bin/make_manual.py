@@ -40,6 +40,7 @@ docs = [
     "onedrive.md",
     "qingstor.md",
     "swift.md",
+    "seafile.md",
     "pcloud.md",
     "sftp.md",
     "webdav.md",
@@ -61,6 +62,7 @@ commands_order = [
     "rclone_sync.md",
     "rclone_move.md",
     "rclone_delete.md",
+    "rclone_deletefile.md",
     "rclone_purge.md",
     "rclone_mkdir.md",
     "rclone_rmdir.md",
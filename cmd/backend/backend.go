@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	options    []string
+	jsonOutput bool
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	flagSet := commandDefinition.Flags()
+	flags.StringArrayVarP(flagSet, &options, "option", "o", options, "Option in the form name=value or name.")
+	flags.BoolVarP(flagSet, &jsonOutput, "json", "", jsonOutput, "Always output in JSON format.")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "backend <command> remote:path [opts] <args>",
+	Short: `Run a backend specific command.`,
+	Long: `
+This runs a backend specific command. The commands themselves (except
+for "help") are defined by the backends and you should see the backend
+docs for definitions.
+
+You can discover what commands a backend implements by using
+
+    rclone backend help remote:
+    rclone backend help <backendname>
+
+Pass options to the backend command with -o. This should be key=value
+or key, eg
+
+    rclone backend lifecycle b2:bucket -o daysFromHidingToDeleting=1
+
+Any arguments after the remote are passed to the command, eg
+
+    rclone backend hide b2:bucket path/to/file.txt
+
+The result of the command is shown as text if it is a string or a list
+of strings and is JSON encoded otherwise.  Use the --json flag to
+always see JSON.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 1e6, command, args)
+		name, remote := args[0], args[1]
+		cmd.Run(false, false, command, func() error {
+			if name == "help" {
+				return showHelp(remote)
+			}
+			opt, err := parseOptions(options)
+			if err != nil {
+				return err
+			}
+			f := cmd.NewFsDir([]string{remote})
+			doCommand := f.Features().Command
+			if doCommand == nil {
+				return errors.Errorf("%v: doesn't support backend commands", f)
+			}
+			out, err := doCommand(name, args[2:], opt)
+			if err == fs.ErrorCommandNotFound {
+				return errors.Errorf("%v: unknown backend command %q - try \"rclone backend help %s\"", f, name, remote)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "command %q failed", name)
+			}
+			return printResult(out)
+		})
+	},
+}
+
+// parseOptions parses the "-o name=value" options into a map
+//
+// An option without a value is given the value "true"
+func parseOptions(in []string) (map[string]string, error) {
+	opt := make(map[string]string, len(in))
+	for _, option := range in {
+		equals := strings.IndexRune(option, '=')
+		name, value := option, "true"
+		if equals >= 0 {
+			name, value = option[:equals], option[equals+1:]
+		}
+		if name == "" {
+			return nil, errors.Errorf("bad option %q", option)
+		}
+		opt[name] = value
+	}
+	return opt, nil
+}
+
+// printResult shows the output of a backend command to the user
+func printResult(out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	if !jsonOutput {
+		switch x := out.(type) {
+		case string:
+			fmt.Println(x)
+			return nil
+		case []string:
+			for _, line := range x {
+				fmt.Println(line)
+			}
+			return nil
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	err := enc.Encode(out)
+	if err != nil {
+		return errors.Wrap(err, "failed to write JSON")
+	}
+	return nil
+}
+
+// showHelp shows the backend commands for the remote or the backend
+// named
+func showHelp(remote string) error {
+	fsInfo, err := fs.Find(strings.TrimSuffix(remote, ":"))
+	if err != nil {
+		fsInfo, _, _, err = fs.ParseRemote(remote)
+		if err != nil {
+			return err
+		}
+	}
+	cmds := fsInfo.CommandHelp
+	if len(cmds) == 0 {
+		return errors.Errorf("%s backend has no commands", fsInfo.Name)
+	}
+	fmt.Printf("### Backend commands\n\n")
+	fmt.Printf("Here are the commands specific to the %s backend.\n\n", fsInfo.Name)
+	fmt.Printf("Run them with\n\n")
+	fmt.Printf("    rclone backend COMMAND remote:\n\n")
+	for _, c := range cmds {
+		fmt.Printf("#### %s\n\n", c.Name)
+		fmt.Printf("%s\n\n", c.Short)
+		fmt.Printf("    rclone backend %s remote: [options] [<arguments>+]\n\n", c.Name)
+		if c.Long != "" {
+			fmt.Printf("%s\n\n", strings.TrimSpace(c.Long))
+		}
+		if len(c.Opts) > 0 {
+			fmt.Printf("Options:\n\n")
+			keys := make([]string, 0, len(c.Opts))
+			for key := range c.Opts {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Printf("- %q: %s\n", key, c.Opts[key])
+			}
+			fmt.Printf("\n")
+		}
+	}
+	return nil
+}
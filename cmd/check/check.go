@@ -8,12 +8,14 @@ import (
 
 // Globals
 var (
-	download = false
+	download  = false
+	againstDB = false
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	commandDefintion.Flags().BoolVarP(&download, "download", "", download, "Check by downloading rather than with hash.")
+	commandDefintion.Flags().BoolVarP(&againstDB, "against-db", "", againstDB, "Check remote:path against the hashes recorded with --hash-db.")
 }
 
 var commandDefintion = &cobra.Command{
@@ -31,8 +33,24 @@ If you supply the --download flag, it will download the data from
 both remotes and check them against each other on the fly.  This can
 be useful for remotes that don't support hashes or if you really want
 to check all the data.
+
+If you supply the --against-db flag, it will check the hashes of the
+files in a single remote:path against those recorded in the local hash
+database when they were transferred with the --hash-db flag.  Any
+file whose hash has changed, but whose size and modification time
+haven't, is reported as it has probably been corrupted.
+
+    rclone check --against-db remote:path
 `,
 	Run: func(command *cobra.Command, args []string) {
+		if againstDB {
+			cmd.CheckArgs(1, 1, command, args)
+			f := cmd.NewFsSrc(args)
+			cmd.Run(false, false, command, func() error {
+				return operations.CheckAgainstDB(f)
+			})
+			return
+		}
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(false, false, command, func() error {
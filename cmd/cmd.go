@@ -62,6 +62,11 @@ const (
 	exitCodeNoRetryError
 	exitCodeFatalError
 	exitCodeTransferExceeded
+	exitCodeInterrupted
+	exitCodePermissionDenied
+	exitCodeQuotaExceeded
+	exitCodeRateLimited
+	exitCodeChecksumMismatch
 )
 
 // Root is the main rclone command
@@ -88,6 +93,7 @@ from various cloud storage systems and using file transfer services, such as:
   * Openstack Swift / Rackspace cloud files / Memset Memstore
   * pCloud
   * QingStor
+  * Seafile
   * SFTP
   * Webdav / Owncloud / Nextcloud
   * Yandex Disk
@@ -131,8 +137,10 @@ func init() {
 	configflags.AddFlags(pflag.CommandLine)
 	filterflags.AddFlags(pflag.CommandLine)
 	rcflags.AddFlags(pflag.CommandLine)
+	flags.SetValues(pflag.CommandLine, "stats-unit", "bits", "bytes")
 
 	Root.Run = runRoot
+	atexit.StopExitCode = exitCodeInterrupted
 	Root.Flags().BoolVarP(&version, "version", "V", false, "Print the version number")
 	cobra.OnInitialize(initConfig)
 }
@@ -332,6 +340,10 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	}
 	if err != nil {
 		log.Printf("Failed to %s: %v", cmd.Name(), err)
+		if err == atexit.ErrorStopped {
+			// show a summary of what was done before stopping
+			accounting.Stats.Log()
+		}
 		resolveExitCode(err)
 	}
 	if showStats && (accounting.Stats.Errored() || *statsInterval > 0) {
@@ -475,6 +487,7 @@ func resolveExitCode(err error) {
 	}
 
 	_, unwrapped := fserrors.Cause(err)
+	class := fserrors.Classify(err)
 
 	switch {
 	case unwrapped == fs.ErrorDirNotFound:
@@ -485,7 +498,19 @@ func resolveExitCode(err error) {
 		os.Exit(exitCodeUncategorizedError)
 	case unwrapped == accounting.ErrorMaxTransferLimitReached:
 		os.Exit(exitCodeTransferExceeded)
-	case fserrors.ShouldRetry(err):
+	case unwrapped == atexit.ErrorStopped:
+		os.Exit(exitCodeInterrupted)
+	case class == fserrors.ClassNotFound:
+		os.Exit(exitCodeFileNotFound)
+	case class == fserrors.ClassPermission:
+		os.Exit(exitCodePermissionDenied)
+	case class == fserrors.ClassQuota:
+		os.Exit(exitCodeQuotaExceeded)
+	case class == fserrors.ClassRateLimited:
+		os.Exit(exitCodeRateLimited)
+	case class == fserrors.ClassChecksum:
+		os.Exit(exitCodeChecksumMismatch)
+	case class == fserrors.ClassNetwork, fserrors.ShouldRetry(err):
 		os.Exit(exitCodeRetryError)
 	case fserrors.IsNoRetryError(err):
 		os.Exit(exitCodeNoRetryError)
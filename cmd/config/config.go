@@ -1,6 +1,8 @@
 package config
 
 import (
+	"strings"
+
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs/config"
 	"github.com/spf13/cobra"
@@ -17,6 +19,7 @@ func init() {
 	configCommand.AddCommand(configUpdateCommand)
 	configCommand.AddCommand(configDeleteCommand)
 	configCommand.AddCommand(configPasswordCommand)
+	configCommand.AddCommand(configReconnectCommand)
 }
 
 var configCommand = &cobra.Command{
@@ -139,3 +142,20 @@ For example to set password of a remote of name myremote you would do:
 		return config.PasswordRemote(args[0], args[1:])
 	},
 }
+
+var configReconnectCommand = &cobra.Command{
+	Use:   "reconnect remote:",
+	Short: `Re-authenticates user with remote.`,
+	Long: `
+This reconnects remote: passed in to the cloud storage system.
+
+This normally means going through the interactive oauth flow again,
+so use it to renew the authorization of a remote whose token has
+expired or been revoked.  The rest of the remote's config is left
+alone.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(1, 1, command, args)
+		return config.ReconnectRemote(strings.TrimSuffix(args[0], ":"))
+	},
+}
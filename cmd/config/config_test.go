@@ -0,0 +1,48 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigReconnectCommand(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "rclone-cmd-config.conf")
+	require.NoError(t, err)
+	path := tempFile.Name()
+	_, err = tempFile.WriteString("[remote]\ntype = cmd_config_test_reconnect\ntoken = old\n")
+	require.NoError(t, err)
+	require.NoError(t, tempFile.Close())
+	oldConfigPath := config.ConfigPath
+	config.ConfigPath = path
+	defer func() {
+		config.ConfigPath = oldConfigPath
+		require.NoError(t, os.Remove(path))
+		_ = os.Remove(path + ".lock")
+	}()
+	config.LoadConfig()
+
+	configured := ""
+	fs.Register(&fs.RegInfo{
+		Name: "cmd_config_test_reconnect",
+		Config: func(name string) {
+			configured = name
+		},
+	})
+
+	// The trailing : is optional
+	require.NoError(t, configReconnectCommand.RunE(configReconnectCommand, []string{"remote:"}))
+	assert.Equal(t, "remote", configured)
+	configured = ""
+	require.NoError(t, configReconnectCommand.RunE(configReconnectCommand, []string{"remote"}))
+	assert.Equal(t, "remote", configured)
+
+	err = configReconnectCommand.RunE(configReconnectCommand, []string{"missing:"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "couldn't find remote")
+}
@@ -93,7 +93,7 @@ func cryptCheck(fdst, fsrc fs.Fs) error {
 		if cryptHash != underlyingHash {
 			err = errors.Errorf("hashes differ (%s:%s) %q vs (%s:%s) %q", fdst.Name(), fdst.Root(), cryptHash, fsrc.Name(), fsrc.Root(), underlyingHash)
 			fs.CountError(err)
-			fs.Errorf(src, err.Error())
+			fs.Errorf(src, "%v", err)
 			return true, false
 		}
 		fs.Debugf(src, "OK")
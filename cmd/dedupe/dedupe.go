@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,7 @@ var (
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	commandDefintion.Flags().VarP(&dedupeMode, "dedupe-mode", "", "Dedupe mode interactive|skip|first|newest|oldest|rename.")
+	flags.SetValues(commandDefintion.Flags(), "dedupe-mode", "interactive", "skip", "first", "newest", "oldest", "largest", "rename")
 }
 
 var commandDefintion = &cobra.Command{
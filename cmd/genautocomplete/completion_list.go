@@ -0,0 +1,173 @@
+package genautocomplete
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/spf13/cobra"
+)
+
+// completionCacheAge is how long a directory listing made for
+// completion is used for before the remote is listed again
+const completionCacheAge = time.Minute
+
+func init() {
+	cmd.Root.AddCommand(completionListDefinition)
+}
+
+var completionListDefinition = &cobra.Command{
+	Use:   "completion-list remote:partial/path",
+	Short: `List the completions of a remote path.`,
+	Long: `
+This is used by the shell completion scripts made by genautocomplete
+to complete remote paths, eg remote:pho<TAB>.
+
+Given a partial path it prints the matching remote names if there is
+no ":" in it or the matching entries in the directory of the path if
+there is, one per line.  Directories are printed with a trailing "/".
+
+Directory listings are cached for a minute so repeatedly pressing TAB
+doesn't list the remote every time.
+`,
+	Hidden: true,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		for _, completion := range completeRemotePath(args[0]) {
+			fmt.Println(completion)
+		}
+	},
+}
+
+// completeRemotePath returns the completions of in which is a
+// partially typed remote name or remote path
+//
+// Errors aren't returned as there is nothing useful the shell can
+// do with them - it just gets no completions.
+func completeRemotePath(in string) (completions []string) {
+	colon := strings.IndexRune(in, ':')
+	if colon < 0 {
+		for _, name := range config.FileSections() {
+			if strings.HasPrefix(name, in) {
+				completions = append(completions, name+":")
+			}
+		}
+		sort.Strings(completions)
+		return completions
+	}
+	// Only complete configured remotes - this ignores local paths
+	// and windows drive letters
+	remote, partial := in[:colon+1], in[colon+1:]
+	if !isRemote(in[:colon]) {
+		return nil
+	}
+	dir, leaf := "", partial
+	if i := strings.LastIndex(partial, "/"); i >= 0 {
+		dir, leaf = partial[:i+1], partial[i+1:]
+	}
+	names, err := listCompletionDir(remote + dir)
+	if err != nil {
+		fs.Debugf(nil, "completion-list: %v", err)
+		return nil
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, leaf) {
+			completions = append(completions, remote+dir+name)
+		}
+	}
+	return completions
+}
+
+// isRemote returns true if name is a configured remote
+func isRemote(name string) bool {
+	for _, section := range config.FileSections() {
+		if section == name {
+			return true
+		}
+	}
+	return false
+}
+
+// completionCache is the on disk format of a cached directory listing
+type completionCache struct {
+	Time  time.Time // when the listing was made
+	Names []string  // names of the entries, directories with a trailing /
+}
+
+// completionCachePath returns the file the listing of the directory
+// fsString is cached in
+func completionCachePath(fsString string) string {
+	hash := md5.Sum([]byte(fsString))
+	return filepath.Join(config.CacheDir, "completion", hex.EncodeToString(hash[:])+".json")
+}
+
+// readCompletionCache returns the cached listing in cachePath if it
+// exists and is fresh enough
+func readCompletionCache(cachePath string) (names []string, ok bool) {
+	data, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+	var cache completionCache
+	err = json.Unmarshal(data, &cache)
+	if err != nil || time.Since(cache.Time) > completionCacheAge {
+		return nil, false
+	}
+	return cache.Names, true
+}
+
+// writeCompletionCache writes names into the cache at cachePath
+func writeCompletionCache(cachePath string, names []string) error {
+	data, err := json.Marshal(completionCache{Time: time.Now(), Names: names})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(cachePath), 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cachePath, data, 0600)
+}
+
+// listCompletionDir returns the names of the entries in the
+// directory fsString sorted, with a trailing "/" on the directories
+//
+// It uses a cached listing if there is a fresh enough one.
+func listCompletionDir(fsString string) (names []string, err error) {
+	cachePath := completionCachePath(fsString)
+	if names, ok := readCompletionCache(cachePath); ok {
+		return names, nil
+	}
+	f, err := fs.NewFs(fsString)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := f.List("")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := path.Base(entry.Remote())
+		if _, isDir := entry.(fs.Directory); isDir {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	err = writeCompletionCache(cachePath, names)
+	if err != nil {
+		fs.Debugf(nil, "completion-list: failed to write cache: %v", err)
+	}
+	return names, nil
+}
@@ -2,7 +2,9 @@ package genautocomplete
 
 import (
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func init() {
@@ -15,5 +17,37 @@ var completionDefinition = &cobra.Command{
 	Long: `
 Generates a shell completion script for rclone.
 Run with --help to list the supported shells.
+
+The bash and fish completions complete remote paths as well as the
+commands and flags, eg remote:pho<TAB>, by listing the remote, and
+they complete the values of flags which only take a few, eg
+--log-level.  The zsh completion only completes the commands.
 `,
 }
+
+// availableCommands returns the sub commands of c which should be
+// completed
+func availableCommands(c *cobra.Command) (commands []*cobra.Command) {
+	for _, child := range c.Commands() {
+		if !child.IsAvailableCommand() || child.Name() == "help" {
+			continue
+		}
+		commands = append(commands, child)
+	}
+	return commands
+}
+
+// visitCommands calls fn for c and all the available commands below
+// it
+func visitCommands(c *cobra.Command, fn func(c *cobra.Command)) {
+	fn(c)
+	for _, child := range availableCommands(c) {
+		visitCommands(child, fn)
+	}
+}
+
+// flagValues returns the values the flag can take as set by
+// flags.SetValues or nil if they aren't known
+func flagValues(flag *pflag.Flag) []string {
+	return flag.Annotations[flags.ValuesAnnotation]
+}
@@ -2,9 +2,11 @@ package genautocomplete
 
 import (
 	"log"
+	"strings"
 
 	"github.com/ncw/rclone/cmd"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func init() {
@@ -36,9 +38,49 @@ there.
 		if len(args) > 0 {
 			out = args[0]
 		}
+		addBashValues(cmd.Root)
 		err := cmd.Root.GenBashCompletionFile(out)
 		if err != nil {
 			log.Fatal(err)
 		}
 	},
 }
+
+// bashCompleteValues is a bash function which completes the current
+// word from the values passed in
+//
+// It also provides __custom_func which is called when nothing else
+// matches to complete remote paths with rclone completion-list.  This
+// sets cur to the whole word including any ":" so the remote: prefix
+// is trimmed back off the completions afterwards.
+const bashCompleteValues = `
+__rclone_complete_values()
+{
+    COMPREPLY=( $(compgen -W "$*" -- "$cur") )
+}
+
+__custom_func()
+{
+    _get_comp_words_by_ref -n : cur
+    local IFS=$'\n'
+    COMPREPLY=( $(rclone completion-list -- "$cur" 2>/dev/null) )
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == *[:/] ]] && [[ $(type -t compopt) = "builtin" ]]; then
+        compopt -o nospace
+    fi
+}
+`
+
+// addBashValues annotates the flags below root which have known
+// values so the bash completion script completes them
+func addBashValues(root *cobra.Command) {
+	root.BashCompletionFunction = bashCompleteValues
+	visitCommands(root, func(c *cobra.Command) {
+		c.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
+			values := flagValues(flag)
+			if len(values) == 0 {
+				return
+			}
+			flag.Annotations[cobra.BashCompCustom] = []string{"__rclone_complete_values " + strings.Join(values, " ")}
+		})
+	})
+}
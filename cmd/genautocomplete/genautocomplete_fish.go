@@ -0,0 +1,207 @@
+package genautocomplete
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	completionDefinition.AddCommand(fishCommandDefinition)
+}
+
+var fishCommandDefinition = &cobra.Command{
+	Use:   "fish [output_file]",
+	Short: `Output fish completion script for rclone.`,
+	Long: `
+Generates a fish autocompletion script for rclone.
+
+This writes to /etc/fish/completions/rclone.fish by default so will
+probably need to be run with sudo or as root, eg
+
+    sudo rclone genautocomplete fish
+
+Logout and login again to use the autocompletion scripts, or source
+them directly
+
+    . /etc/fish/completions/rclone.fish
+
+If you supply a command line argument the script will be written
+there.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(0, 1, command, args)
+		out := "/etc/fish/completions/rclone.fish"
+		if len(args) > 0 {
+			out = args[0]
+		}
+		outFile, err := os.Create(out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() { _ = outFile.Close() }()
+		err = genFishCompletion(outFile, cmd.Root)
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// fishHeader contains the functions used by the completions
+//
+// __rclone_words prints the words of the command line so far
+// without the flags and their values.  The flags which take a value
+// in the next word are in $__rclone_value_flags.
+const fishHeader = `# fish completion for rclone
+
+function __rclone_words
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l skip 0
+    for token in $tokens
+        if test $skip -eq 1
+            set skip 0
+            continue
+        end
+        switch $token
+            case '--*=*'
+                continue
+            case '-*'
+                if contains -- $token $__rclone_value_flags
+                    set skip 1
+                end
+                continue
+        end
+        echo $token
+    end
+end
+
+# true if the command line so far starts with the commands in argv
+function __rclone_seen_command
+    set -l words (__rclone_words)
+    test (count $words) -ge (count $argv); or return 1
+    for i in (seq (count $argv))
+        test "$words[$i]" = "$argv[$i]"; or return 1
+    end
+end
+
+# true if the command line so far is exactly the commands in argv
+function __rclone_at_command
+    __rclone_seen_command $argv; or return 1
+    test (count (__rclone_words)) -eq (count $argv)
+end
+
+# true if a command has been typed so remote paths can be completed
+function __rclone_has_command
+    test (count (__rclone_words)) -ge 1
+end
+
+`
+
+// fishQuote quotes s so it can be used in a fish script
+func fishQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `'`, `\'`, -1)
+	return "'" + s + "'"
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	if i := strings.IndexRune(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// takesValue returns true if flag needs a value
+func takesValue(flag *pflag.Flag) bool {
+	return flag.NoOptDefVal == ""
+}
+
+// commandPath returns the path to c from the root without the name
+// of the root
+func commandPath(c *cobra.Command) string {
+	if !c.HasParent() {
+		return ""
+	}
+	return strings.TrimPrefix(c.CommandPath(), c.Root().Name()+" ")
+}
+
+// writeFishFlag writes the completion for flag which is valid only
+// if condition is true (or always if condition is empty)
+func writeFishFlag(out *bytes.Buffer, root *cobra.Command, condition string, flag *pflag.Flag) {
+	fmt.Fprintf(out, "complete -c %s", root.Name())
+	if condition != "" {
+		fmt.Fprintf(out, " -n %s", fishQuote(condition))
+	}
+	fmt.Fprintf(out, " -l %s", flag.Name)
+	if flag.Shorthand != "" {
+		fmt.Fprintf(out, " -s %s", flag.Shorthand)
+	}
+	if values := flagValues(flag); len(values) > 0 {
+		fmt.Fprintf(out, " -x -a %s", fishQuote(strings.Join(values, " ")))
+	} else if takesValue(flag) {
+		fmt.Fprintf(out, " -r")
+	}
+	fmt.Fprintf(out, " -d %s\n", fishQuote(firstLine(flag.Usage)))
+}
+
+// genFishCompletion writes a fish completion script for the command
+// tree under root to w
+func genFishCompletion(w io.Writer, root *cobra.Command) error {
+	var out bytes.Buffer
+	out.WriteString(fishHeader)
+
+	// Make the list of the flags which take a value
+	var valueFlags []string
+	seen := map[string]bool{}
+	addValueFlag := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			valueFlags = append(valueFlags, name)
+		}
+	}
+	visitCommands(root, func(c *cobra.Command) {
+		c.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Hidden || !takesValue(flag) {
+				return
+			}
+			addValueFlag("--" + flag.Name)
+			if flag.Shorthand != "" {
+				addValueFlag("-" + flag.Shorthand)
+			}
+		})
+	})
+	fmt.Fprintf(&out, "set -g __rclone_value_flags %s\n", strings.Join(valueFlags, " "))
+
+	// Complete remote paths with completion-list
+	fmt.Fprintf(&out, "complete -c %s -n '__rclone_has_command' -a '(%s completion-list -- (commandline -ct) 2>/dev/null)'\n", root.Name(), root.Name())
+
+	visitCommands(root, func(c *cobra.Command) {
+		path := commandPath(c)
+		out.WriteString("\n")
+		for _, child := range availableCommands(c) {
+			fmt.Fprintf(&out, "complete -c %s -f -n %s -a %s -d %s\n", root.Name(), fishQuote(strings.TrimSpace("__rclone_at_command "+path)), child.Name(), fishQuote(firstLine(child.Short)))
+		}
+		condition := ""
+		if path != "" {
+			condition = "__rclone_seen_command " + path
+		}
+		c.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Hidden {
+				return
+			}
+			writeFishFlag(&out, root, condition, flag)
+		})
+	})
+
+	_, err := out.WriteTo(w)
+	return err
+}
@@ -3,9 +3,13 @@ package genautocomplete
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fs/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCompletionBash(t *testing.T) {
@@ -33,3 +37,52 @@ func TestCompletionZsh(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, string(bs))
 }
+
+func TestCompletionFish(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "completion_fish")
+	assert.NoError(t, err)
+	defer func() { _ = tempFile.Close() }()
+	defer func() { _ = os.Remove(tempFile.Name()) }()
+
+	fishCommandDefinition.Run(fishCommandDefinition, []string{tempFile.Name()})
+
+	bs, err := ioutil.ReadFile(tempFile.Name())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, string(bs))
+	assert.Contains(t, string(bs), "complete -c rclone -f -n '__rclone_at_command genautocomplete' -a fish")
+	assert.Contains(t, string(bs), "rclone completion-list")
+}
+
+func TestFishQuote(t *testing.T) {
+	assert.Equal(t, `'hello'`, fishQuote("hello"))
+	assert.Equal(t, `'it\'s a \\ backslash'`, fishQuote(`it's a \ backslash`))
+}
+
+func TestCompleteRemotePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "completion_list")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "photos", "2018"), 0777))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "phones"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "photo.jpg"), []byte("jpg"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "music.mp3"), []byte("mp3"), 0666))
+
+	oldCacheDir := config.CacheDir
+	config.CacheDir = filepath.Join(dir, "cache")
+	defer func() { config.CacheDir = oldCacheDir }()
+	require.NoError(t, os.Setenv("RCLONE_CONFIG_COMPLETIONTEST_TYPE", "local"))
+	defer func() { _ = os.Unsetenv("RCLONE_CONFIG_COMPLETIONTEST_TYPE") }()
+
+	root := "completiontest:" + filepath.ToSlash(dir) + "/"
+	assert.Equal(t, []string{"completiontest:"}, completeRemotePath("completion"))
+	assert.Equal(t, []string{root + "phones/", root + "photo.jpg", root + "photos/"}, completeRemotePath(root+"ph"))
+	assert.Equal(t, []string{root + "photos/2018/"}, completeRemotePath(root+"photos/"))
+	assert.Equal(t, []string(nil), completeRemotePath(root+"potato"))
+	assert.Equal(t, []string(nil), completeRemotePath("notaremote:ph"))
+
+	// Check the listing is cached
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "photos2"), 0777))
+	assert.Equal(t, []string{root + "phones/", root + "photo.jpg", root + "photos/"}, completeRemotePath(root+"ph"))
+	require.NoError(t, os.RemoveAll(config.CacheDir))
+	assert.Equal(t, []string{root + "phones/", root + "photo.jpg", root + "photos/", root + "photos2/"}, completeRemotePath(root+"ph"))
+}
@@ -18,6 +18,9 @@ var zshCommandDefinition = &cobra.Command{
 	Long: `
 Generates a zsh autocompletion script for rclone.
 
+This only completes the commands - use the bash or fish completions
+to complete flags, their values and remote paths too.
+
 This writes to /usr/share/zsh/vendor-completions/_rclone by default so will
 probably need to be run with sudo or as root, eg
 
@@ -4,12 +4,25 @@ import (
 	"fmt"
 
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var (
+	expire    = fs.DurationOff
+	listLinks = false
+	unlink    = false
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	cmdFlags := commandDefintion.Flags()
+	flags.FVarP(cmdFlags, &expire, "expire", "", "The amount of time that the link will be valid")
+	flags.BoolVarP(cmdFlags, &listLinks, "list", "", listLinks, "List the existing public links instead of creating one")
+	flags.BoolVarP(cmdFlags, &unlink, "unlink", "", unlink, "Remove the existing public links")
 }
 
 var commandDefintion = &cobra.Command{
@@ -25,12 +38,40 @@ If successful, the last line of the output will contain the link. Exact
 capabilities depend on the remote, but the link will always be created with
 the least constraints – e.g. no expiry, no password protection, accessible
 without account.
+
+Use the --expire flag to make the link stop working after a time, eg
+"--expire 1d".  Not all remotes support this.  For S3 the link is a
+presigned URL which always has an expiry, defaulting to the maximum of
+one week, and only files can be shared.
+
+Use the --list flag to show the public links which already exist for
+the file or folder, one per line, and the --unlink flag to remove them
+so it is no longer shared.  These are supported by Google Drive,
+Dropbox and OneDrive.
+
+    rclone link --list remote:path/to/file
+    rclone link --unlink remote:path/to/file
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc, remote := cmd.NewFsFile(args[0])
 		cmd.Run(false, false, command, func() error {
-			link, err := operations.PublicLink(fsrc, remote)
+			switch {
+			case listLinks && unlink:
+				return errors.New("can't use --list and --unlink together")
+			case listLinks:
+				links, err := operations.ListPublicLinks(fsrc, remote)
+				if err != nil {
+					return err
+				}
+				for _, link := range links {
+					fmt.Println(link)
+				}
+				return nil
+			case unlink:
+				return operations.RemovePublicLinks(fsrc, remote)
+			}
+			link, err := operations.PublicLink(fsrc, remote, expire)
 			if err != nil {
 				return err
 			}
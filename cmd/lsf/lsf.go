@@ -8,6 +8,7 @@ import (
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/cmd/ls/lshelp"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fs/walk"
@@ -27,13 +28,18 @@ var (
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
-	flags := commandDefintion.Flags()
-	flags.StringVarP(&format, "format", "F", "p", "Output format - see  help for details")
-	flags.StringVarP(&separator, "separator", "s", ";", "Separator for the items in the format.")
-	flags.BoolVarP(&dirSlash, "dir-slash", "d", true, "Append a slash to directory names.")
-	flags.VarP(&hashType, "hash", "", "Use this hash when `h` is used in the format MD5|SHA-1|DropboxHash")
-	flags.BoolVarP(&filesOnly, "files-only", "", false, "Only list files.")
-	flags.BoolVarP(&dirsOnly, "dirs-only", "", false, "Only list directories.")
+	flagSet := commandDefintion.Flags()
+	flagSet.StringVarP(&format, "format", "F", "p", "Output format - see  help for details")
+	flagSet.StringVarP(&separator, "separator", "s", ";", "Separator for the items in the format.")
+	flagSet.BoolVarP(&dirSlash, "dir-slash", "d", true, "Append a slash to directory names.")
+	flagSet.VarP(&hashType, "hash", "", "Use this hash when `h` is used in the format MD5|SHA-1|DropboxHash")
+	var hashNames []string
+	for _, ht := range hash.Supported.Array() {
+		hashNames = append(hashNames, ht.String())
+	}
+	flags.SetValues(flagSet, "hash", hashNames...)
+	flagSet.BoolVarP(&filesOnly, "files-only", "", false, "Only list files.")
+	flagSet.BoolVarP(&dirsOnly, "dirs-only", "", false, "Only list directories.")
 	commandDefintion.Flags().BoolVarP(&recurse, "recursive", "R", false, "Recurse into the listing.")
 }
 
@@ -63,6 +69,7 @@ output:
     s - size
     t - modification time
     h - hash
+    T - tier of storage if known, eg "STANDARD" or "GLACIER"
 
 So if you wanted the path, size and modification time, you would use
 --format "pst", or maybe --format "tsp" to put the path last.
@@ -138,6 +145,8 @@ func Lsf(fsrc fs.Fs, out io.Writer) error {
 			list.AddSize()
 		case 'h':
 			list.AddHash(hashType)
+		case 'T':
+			list.AddTier()
 		default:
 			return errors.Errorf("Unknown format character %q", char)
 		}
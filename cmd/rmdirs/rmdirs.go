@@ -24,6 +24,10 @@ it has nothing in.
 
 If you supply the --leave-root flag, it will not remove the root directory.
 
+Directories are removed from the bottom up.  If you use --max-depth
+then directories below that depth aren't looked in so they (and their
+parents) won't be removed.
+
 This is useful for tidying up remotes that rclone has left a lot of
 empty directories in.
 
@@ -0,0 +1,332 @@
+package selfupdate
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+var (
+	beta            = false
+	wantVersion     = ""
+	keyring         = ""
+	allowUnverified = false
+)
+
+// errNotFound is returned by fetch if the file doesn't exist
+var errNotFound = errors.New("not found")
+
+// errNoReleaseKey is returned by readKeyring if there is no key to
+// check the signature with
+var errNoReleaseKey = errors.New("this rclone was built without the release key - use --keyring to supply it or --allow-unverified to check the checksum only")
+
+// Where to download the releases and betas from
+var (
+	releaseURL = "https://downloads.rclone.org/"
+	betaURL    = "https://beta.rclone.org/"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.BoolVarP(cmdFlags, &beta, "beta", "", false, "Install the latest beta instead of the latest release.")
+	flags.StringVarP(cmdFlags, &wantVersion, "version", "", "", "Install this version instead of the latest, eg v1.41.")
+	flags.StringVarP(cmdFlags, &keyring, "keyring", "", "", "Armored PGP public key file to check the signature of the checksums with instead of the built in key.")
+	flags.BoolVarP(cmdFlags, &allowUnverified, "allow-unverified", "", false, "Install even if the checksum of the download can't be verified with a signature.")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "selfupdate",
+	Short: `Update the rclone binary.`,
+	Long: `
+This command downloads the latest release of rclone for the OS and
+architecture it is running on and replaces the running rclone binary
+with it.  This is for machines where rclone was installed from the
+zip file rather than with a package manager - use the package manager
+to update rclone if it was installed that way.
+
+Use --beta to install the latest beta instead and --version to install
+a particular version, eg
+
+    rclone selfupdate --version v1.41
+
+The download is checked against the SHA256SUMS file published with
+the release before it is installed, and the signature of the
+SHA256SUMS file is checked with the public key the releases are
+signed with.  This key is built in to rclone when it is built for a
+release.  Use --keyring to check it with the armored PGP public key in
+a file instead, eg
+
+    gpg --export --armor KEYID > rclone.asc
+    rclone selfupdate --keyring rclone.asc
+
+rclone refuses to install a download it can't verify like this.
+Betas aren't signed and may not have checksums, so to install one
+you need --allow-unverified too
+
+    rclone selfupdate --beta --allow-unverified
+
+With --allow-unverified the SHA256SUMS file is still used to check
+the download if there is one, even if it isn't signed or this rclone
+was built without the release key so its signature can't be checked.
+
+The new binary is written next to the old one and renamed over it so
+the binary is never left half written.  This means rclone must be able
+to write to the directory it is installed in so you may need to run
+it with sudo.
+
+With --dry-run the new binary is downloaded and checked but not
+installed.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(0, 0, command, args)
+		cmd.Run(false, false, command, func() error {
+			exe, err := os.Executable()
+			if err != nil {
+				return errors.Wrap(err, "couldn't find the rclone binary")
+			}
+			exe, err = filepath.EvalSymlinks(exe)
+			if err != nil {
+				return errors.Wrap(err, "couldn't find the rclone binary")
+			}
+			return selfUpdate(exe)
+		})
+	},
+}
+
+// osName returns the name the release uses for GOOS
+func osName() string {
+	if runtime.GOOS == "darwin" {
+		return "osx"
+	}
+	return runtime.GOOS
+}
+
+// fetch reads the whole of url into memory
+func fetch(url string) (data []byte, err error) {
+	resp, err := fshttp.NewClient(fs.Config).Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(resp.Body, &err)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.Wrapf(errNotFound, "failed to fetch %q", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch %q: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// findVersion returns the version to install and the URL it can be
+// found at
+//
+// Betas have a trailing β in their version but not in the
+// directory they are stored in.
+func findVersion() (version, versionURL string, err error) {
+	baseURL := releaseURL
+	if beta {
+		baseURL = betaURL
+	}
+	version = wantVersion
+	if version == "" {
+		data, err := fetch(baseURL + "version.txt")
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to find the latest version")
+		}
+		version = strings.TrimPrefix(strings.TrimSpace(string(data)), "rclone ")
+	} else if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version, baseURL + strings.TrimSuffix(version, "β") + "/", nil
+}
+
+// readKeyring returns the keys to check the signature of SHA256SUMS
+// with - the ones in --keyring if set or the built in release key
+func readKeyring() (openpgp.EntityList, error) {
+	keyData := []byte(releaseKey)
+	if keyring != "" {
+		var err error
+		keyData, err = ioutil.ReadFile(keyring)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read keyring")
+		}
+	} else if releaseKey == "" {
+		return nil, errNoReleaseKey
+	}
+	keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(keyData))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse keyring")
+	}
+	return keys, nil
+}
+
+// findChecksum reads the SHA256SUMS file at versionURL and returns
+// the checksum of fileName in it
+//
+// The signature of the file is checked with readKeyring.  If the
+// file is missing or isn't signed, or there is no key to check it
+// with, an error is returned unless --allow-unverified is set, in
+// which case the file is used without checking its signature and a
+// missing one gives an empty checksum.
+func findChecksum(versionURL, fileName string) (checksum string, err error) {
+	data, err := fetch(versionURL + "SHA256SUMS")
+	if err != nil {
+		if errors.Cause(err) != errNotFound {
+			return "", err
+		}
+		if !allowUnverified {
+			return "", errors.Wrap(err, "no checksums to verify the download with - use --allow-unverified to install anyway")
+		}
+		fs.Logf(nil, "Not checking the checksum as there isn't one: %v", err)
+		return "", nil
+	}
+	if block, _ := clearsign.Decode(data); block != nil {
+		keys, err := readKeyring()
+		if err == errNoReleaseKey && allowUnverified {
+			fs.Logf(nil, "Not checking the signature of SHA256SUMS as this rclone was built without the release key")
+		} else if err != nil {
+			return "", err
+		} else {
+			_, err = openpgp.CheckDetachedSignature(keys, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body)
+			if err != nil {
+				return "", errors.Wrap(err, "bad signature on SHA256SUMS")
+			}
+			fs.Infof(nil, "Signature of SHA256SUMS is OK")
+		}
+		data = block.Plaintext
+	} else if !allowUnverified {
+		return "", errors.New("SHA256SUMS isn't signed - use --allow-unverified to install anyway")
+	} else {
+		fs.Logf(nil, "Not checking the signature of SHA256SUMS as it isn't signed")
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == fileName {
+			return fields[0], nil
+		}
+	}
+	return "", errors.Errorf("no checksum for %q in SHA256SUMS", fileName)
+}
+
+// unpackBinary returns the rclone binary from the zip in data
+func unpackBinary(data []byte) ([]byte, error) {
+	binaryName := "rclone"
+	if runtime.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open zip")
+	}
+	for _, file := range zipReader.File {
+		if path.Base(file.Name) != binaryName {
+			continue
+		}
+		in, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		binary, err := ioutil.ReadAll(in)
+		closeErr := in.Close()
+		if err == nil {
+			err = closeErr
+		}
+		return binary, err
+	}
+	return nil, errors.Errorf("%q not found in zip", binaryName)
+}
+
+// replaceBinary atomically replaces the binary at exe with binary
+func replaceBinary(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	newExe := exe + ".new"
+	err = ioutil.WriteFile(newExe, binary, info.Mode().Perm())
+	if err != nil {
+		_ = os.Remove(newExe)
+		return errors.Wrap(err, "failed to write new binary")
+	}
+	if runtime.GOOS == "windows" {
+		// Windows can't overwrite a running binary but it can
+		// rename it out of the way
+		oldExe := exe + ".old"
+		_ = os.Remove(oldExe)
+		err = os.Rename(exe, oldExe)
+		if err != nil {
+			_ = os.Remove(newExe)
+			return errors.Wrap(err, "failed to move old binary")
+		}
+	}
+	err = os.Rename(newExe, exe)
+	if err != nil {
+		_ = os.Remove(newExe)
+		return errors.Wrap(err, "failed to replace binary")
+	}
+	return nil
+}
+
+// selfUpdate downloads the version asked for, checks it and
+// replaces the binary exe with it
+func selfUpdate(exe string) error {
+	version, versionURL, err := findVersion()
+	if err != nil {
+		return err
+	}
+	if wantVersion == "" && version == fs.Version {
+		fs.Logf(nil, "rclone is up to date at %s", version)
+		return nil
+	}
+	fileName := "rclone-" + version + "-" + osName() + "-" + runtime.GOARCH + ".zip"
+	checksum, err := findChecksum(versionURL, fileName)
+	if err != nil {
+		return err
+	}
+	fs.Infof(nil, "Downloading %s", versionURL+fileName)
+	data, err := fetch(versionURL + fileName)
+	if err != nil {
+		return err
+	}
+	if checksum != "" {
+		hash := sha256.Sum256(data)
+		if got := hex.EncodeToString(hash[:]); got != strings.ToLower(checksum) {
+			return errors.Errorf("checksum mismatch for %s: expecting %s got %s", fileName, checksum, got)
+		}
+		fs.Infof(nil, "Checksum of %s is OK", fileName)
+	}
+	binary, err := unpackBinary(data)
+	if err != nil {
+		return err
+	}
+	if fs.Config.DryRun {
+		fs.Logf(nil, "Not replacing %s with %s as --dry-run is set", exe, version)
+		return nil
+	}
+	err = replaceBinary(exe, binary)
+	if err != nil {
+		return err
+	}
+	fs.Logf(nil, "Successfully updated rclone from %s to %s", fs.Version, version)
+	return nil
+}
@@ -0,0 +1,224 @@
+package selfupdate
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+// makeZip makes a release zip containing binary
+func makeZip(t *testing.T, version string, binary []byte) []byte {
+	binaryName := "rclone"
+	if runtime.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	dir := "rclone-" + version + "-" + osName() + "-" + runtime.GOARCH + "/"
+	for name, contents := range map[string][]byte{
+		"README.txt": []byte("read me"),
+		binaryName:   binary,
+	} {
+		w, err := zipWriter.Create(dir + name)
+		require.NoError(t, err)
+		_, err = w.Write(contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+	return buf.Bytes()
+}
+
+// sign clear signs data with entity
+func sign(t *testing.T, entity *openpgp.Entity, data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := clearsign.Encode(&buf, entity.PrivateKey, nil)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// armoredKey returns the armored public key of entity
+func armoredKey(t *testing.T, entity *openpgp.Entity) string {
+	var buf bytes.Buffer
+	armorWriter, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(armorWriter))
+	require.NoError(t, armorWriter.Close())
+	return buf.String()
+}
+
+// newEntity makes a PGP key to sign with
+func newEntity(t *testing.T, name string) *openpgp.Entity {
+	entity, err := openpgp.NewEntity(name, "test", name+"@example.com", nil)
+	require.NoError(t, err)
+	// Serializing the private key signs the identities which the
+	// public key needs
+	require.NoError(t, entity.SerializePrivate(ioutil.Discard, nil))
+	return entity
+}
+
+func TestSelfUpdate(t *testing.T) {
+	const version = "v1.99"
+	newBinary := []byte("new rclone binary")
+	entity := newEntity(t, "rclone")
+	files := map[string][]byte{
+		"/version.txt":      []byte("rclone " + version + "\n"),
+		"/beta/version.txt": []byte("rclone v1.99-001-gabcdefβ\n"),
+	}
+	// addRelease adds a release to dir returning its unsigned SHA256SUMS
+	addRelease := func(dir, version string) []byte {
+		zipData := makeZip(t, version, newBinary)
+		hash := sha256.Sum256(zipData)
+		fileName := "rclone-" + version + "-" + osName() + "-" + runtime.GOARCH + ".zip"
+		sums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(hash[:]), fileName))
+		files[dir+strings.TrimSuffix(version, "β")+"/"+fileName] = zipData
+		files[dir+strings.TrimSuffix(version, "β")+"/SHA256SUMS"] = sign(t, entity, sums)
+		return sums
+	}
+	sums := addRelease("/", version)
+	addRelease("/", "v1.98")
+	badSums := addRelease("/", "v1.97")
+	files["/v1.97/SHA256SUMS"] = sign(t, entity, append([]byte(strings.Repeat("0", 64)), badSums[64:]...))
+	addRelease("/", "v1.96")
+	delete(files, "/v1.96/SHA256SUMS")
+	addRelease("/beta/", "v1.99-001-gabcdefβ")
+	delete(files, "/beta/v1.99-001-gabcdef/SHA256SUMS")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	oldReleaseURL, oldBetaURL, oldBeta, oldWantVersion, oldKeyring, oldAllowUnverified, oldReleaseKey := releaseURL, betaURL, beta, wantVersion, keyring, allowUnverified, releaseKey
+	releaseURL = server.URL + "/"
+	betaURL = server.URL + "/beta/"
+	releaseKey = armoredKey(t, entity)
+	defer func() {
+		releaseURL, betaURL, beta, wantVersion, keyring, allowUnverified, releaseKey = oldReleaseURL, oldBetaURL, oldBeta, oldWantVersion, oldKeyring, oldAllowUnverified, oldReleaseKey
+	}()
+
+	dir, err := ioutil.TempDir("", "selfupdate")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	exe := filepath.Join(dir, "rclone")
+	reset := func() {
+		require.NoError(t, ioutil.WriteFile(exe, []byte("old rclone binary"), 0755))
+	}
+	check := func(want []byte) {
+		got, err := ioutil.ReadFile(exe)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+	checkFails := func(wantErr string) {
+		reset()
+		err := selfUpdate(exe)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), wantErr)
+		check([]byte("old rclone binary"))
+	}
+
+	// Update to the latest checking the signature with the
+	// built in key
+	reset()
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+	_, err = os.Stat(exe + ".new")
+	assert.True(t, os.IsNotExist(err))
+
+	// Update to a pinned version
+	reset()
+	wantVersion = "1.98"
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+
+	// Bad checksum
+	wantVersion = "v1.97"
+	checkFails("checksum mismatch")
+
+	// No checksums for a release
+	wantVersion = "v1.96"
+	checkFails("use --allow-unverified")
+
+	// ...unless allowed
+	allowUnverified = true
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+	allowUnverified = false
+
+	// Dry run
+	reset()
+	wantVersion = ""
+	fs.Config.DryRun = true
+	require.NoError(t, selfUpdate(exe))
+	fs.Config.DryRun = false
+	check([]byte("old rclone binary"))
+
+	// Signed by someone else
+	other := newEntity(t, "other")
+	files["/"+version+"/SHA256SUMS"] = sign(t, other, sums)
+	checkFails("bad signature")
+
+	// ...whose key is supplied with --keyring
+	keyringFile := filepath.Join(dir, "other.asc")
+	require.NoError(t, ioutil.WriteFile(keyringFile, []byte(armoredKey(t, other)), 0600))
+	keyring = keyringFile
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+	keyring = ""
+
+	// No built in key
+	releaseKey = ""
+	checkFails("built without the release key")
+
+	// ...checks just the checksum with --allow-unverified
+	allowUnverified = true
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+	files["/"+version+"/SHA256SUMS"] = sign(t, other, append([]byte(strings.Repeat("0", 64)), sums[64:]...))
+	checkFails("checksum mismatch")
+	allowUnverified = false
+	releaseKey = armoredKey(t, entity)
+
+	// Not signed
+	files["/"+version+"/SHA256SUMS"] = sums
+	checkFails("isn't signed")
+
+	// ...unless allowed, but the checksum is still checked
+	allowUnverified = true
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+	files["/"+version+"/SHA256SUMS"] = append([]byte(strings.Repeat("0", 64)), sums[64:]...)
+	checkFails("checksum mismatch")
+	allowUnverified = false
+
+	// A beta without checksums needs --allow-unverified
+	beta = true
+	checkFails("use --allow-unverified")
+	allowUnverified = true
+	require.NoError(t, selfUpdate(exe))
+	check(newBinary)
+}
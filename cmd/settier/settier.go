@@ -0,0 +1,55 @@
+package settier
+
+import (
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "settier tier remote:path",
+	Short: `Changes storage class/tier of objects in remote.`,
+	Long: `
+rclone settier changes storage tier or class at remote if supported.
+Few cloud storage services provides different storage classes on
+objects, for example AWS S3 has STANDARD, STANDARD_IA, ONEZONE_IA and
+GLACIER, Google Cloud Storage has MULTI_REGIONAL, REGIONAL, NEARLINE
+and COLDLINE and Azure Blob storage has Hot, Cool and Archive.
+
+Objects which already have the requested tier are skipped.  Use
+"rclone lsf --format pT" to see the current tier of each object.
+
+Note that certain tier changes make objects not available to access
+immediately.  For example tiering to GLACIER makes the objects
+inaccessible until they are restored.
+
+You can use it to tier a single object
+
+    rclone settier STANDARD_IA remote:path/file
+
+Or use rclone filters to set tier on only specific files
+
+    rclone --include "*.txt" settier ONEZONE_IA remote:path/dir
+
+Or move everything which hasn't been modified for 90 days to GLACIER
+
+    rclone --min-age 90d settier GLACIER remote:path/dir
+
+Or just provide a remote directory and all files in the directory
+will be tiered
+
+    rclone settier GLACIER remote:path/dir
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		tier := args[0]
+		fsrc := cmd.NewFsSrc(args[1:])
+		cmd.Run(false, false, command, func() error {
+			return operations.SetTier(fsrc, tier)
+		})
+	},
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/cmd/test"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/object"
@@ -26,14 +27,18 @@ var (
 	checkControl       bool
 	checkLength        bool
 	checkStreaming     bool
+	checkModTime       bool
+	checkDuplicates    bool
 )
 
 func init() {
-	cmd.Root.AddCommand(commandDefintion)
+	test.Command.AddCommand(commandDefintion)
 	commandDefintion.Flags().BoolVarP(&checkNormalization, "check-normalization", "", true, "Check UTF-8 Normalization.")
 	commandDefintion.Flags().BoolVarP(&checkControl, "check-control", "", true, "Check control characters.")
 	commandDefintion.Flags().BoolVarP(&checkLength, "check-length", "", true, "Check max filename length.")
 	commandDefintion.Flags().BoolVarP(&checkStreaming, "check-streaming", "", true, "Check uploads with indeterminate file size.")
+	commandDefintion.Flags().BoolVarP(&checkModTime, "check-modtime", "", true, "Check the precision of modification times.")
+	commandDefintion.Flags().BoolVarP(&checkDuplicates, "check-duplicates", "", true, "Check whether duplicate file names can be written.")
 }
 
 var commandDefintion = &cobra.Command{
@@ -43,8 +48,20 @@ var commandDefintion = &cobra.Command{
 to write to the paths passed in and how long they can be.  It can take some
 time.  It will write test files into the remote:path passed in.  It outputs
 a bit of go code for each one.
+
+It checks
+
+  * which control characters can be used in file names
+  * the maximum length of a file name
+  * whether unnormalized UTF-8 file names can be written and read
+  * whether files of unknown size can be uploaded (streaming)
+  * the precision of modification times as stored by the remote
+  * whether two files with the same name can be written (duplicates)
+
+Each check can be turned off with its --check-xxx=false flag.
+
+**NB** this can create undeletable files and other hazards - use with care
 `,
-	Hidden: true,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1E6, command, args)
 		for i := range args {
@@ -65,6 +82,8 @@ type results struct {
 	canReadUnnormalized  bool
 	canReadRenormalized  bool
 	canStream            bool
+	modTimePrecision     time.Duration
+	canWriteDuplicates   bool
 }
 
 func newResults(f fs.Fs) *results {
@@ -100,6 +119,12 @@ func (r *results) Print() {
 	if checkStreaming {
 		fmt.Printf("canStream = %v\n", r.canStream)
 	}
+	if checkModTime {
+		fmt.Printf("modTimePrecision = %v // declared %v\n", r.modTimePrecision, r.f.Precision())
+	}
+	if checkDuplicates {
+		fmt.Printf("canWriteDuplicates = %v\n", r.canWriteDuplicates)
+	}
 }
 
 // writeFile writes a file with some random contents
@@ -138,6 +163,7 @@ func (r *results) checkChar(c rune) {
 	escape := false
 	if err != nil {
 		fs.Infof(r.f, "Couldn't write file 0x%02X", c)
+		escape = true
 	} else {
 		fs.Infof(r.f, "OK writing file 0x%02X", c)
 	}
@@ -246,6 +272,91 @@ func (r *results) checkStreaming() {
 	r.canStream = true
 }
 
+// modTimePrecisions are the precisions checkModTimePrecision can find
+var modTimePrecisions = []time.Duration{
+	time.Nanosecond,
+	time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	time.Minute,
+	time.Hour,
+}
+
+// find the precision of the modification times the remote keeps by
+// writing a file and reading it back
+//
+// The time written has an odd number of seconds so a remote which
+// truncates to 2 seconds (eg FAT) is out by more than a second.
+func (r *results) checkModTimePrecision() {
+	const remote = "checkModTimeTest"
+	modTime := time.Date(2001, 2, 3, 4, 5, 7, 123456789, time.UTC)
+	contents := "the time is precisely now"
+	src := object.NewStaticObjectInfo(remote, modTime, int64(len(contents)), true, nil, r.f)
+	_, err := r.f.Put(bytes.NewBufferString(contents), src)
+	if err != nil {
+		fs.Infof(r.f, "Couldn't write file to check modification times: %v", err)
+		r.modTimePrecision = fs.ModTimeNotSupported
+		return
+	}
+	obj, err := r.f.NewObject(remote)
+	if err != nil {
+		fs.Infof(r.f, "Couldn't read file to check modification times: %v", err)
+		r.modTimePrecision = fs.ModTimeNotSupported
+		return
+	}
+	dt := obj.ModTime().Sub(modTime)
+	r.remove(obj)
+	if dt < 0 {
+		dt = -dt
+	}
+	fs.Infof(r.f, "Modification time read back is %v out", dt)
+	r.modTimePrecision = fs.ModTimeNotSupported
+	for _, precision := range modTimePrecisions {
+		if dt < precision {
+			r.modTimePrecision = precision
+			break
+		}
+	}
+}
+
+// remove the test file o logging any error
+func (r *results) remove(o fs.Object) {
+	err := o.Remove()
+	if err != nil {
+		fs.Infof(r.f, "Couldn't remove test file %q: %v", o.Remote(), err)
+	}
+}
+
+// check whether a second file with the same name makes a duplicate
+// rather than overwriting the first
+func (r *results) checkDuplicateNames() {
+	const remote = "checkDuplicatesTest"
+	for i := 0; i < 2; i++ {
+		_, err := r.writeFile(remote)
+		if err != nil {
+			fs.Infof(r.f, "Couldn't write file to check duplicates: %v", err)
+			return
+		}
+	}
+	entries, err := r.f.List("")
+	if err != nil {
+		fs.Infof(r.f, "Couldn't list to check duplicates: %v", err)
+		return
+	}
+	count := 0
+	for _, entry := range entries {
+		if o, ok := entry.(fs.Object); ok && o.Remote() == remote {
+			count++
+			r.remove(o)
+		}
+	}
+	fs.Infof(r.f, "Found %d files called %q after writing 2", count, remote)
+	r.canWriteDuplicates = count > 1
+}
+
 func readInfo(f fs.Fs) error {
 	err := f.Mkdir("")
 	if err != nil {
@@ -264,6 +375,12 @@ func readInfo(f fs.Fs) error {
 	if checkStreaming {
 		r.checkStreaming()
 	}
+	if checkModTime {
+		r.checkModTimePrecision()
+	}
+	if checkDuplicates {
+		r.checkDuplicateNames()
+	}
 	r.Print()
 	return nil
 }
@@ -0,0 +1,87 @@
+package info
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+// truncatingFs is an fs.Fs whose objects read back their
+// modification times truncated to precision
+type truncatingFs struct {
+	fs.Fs
+	precision time.Duration
+}
+
+// NewObject finds the Object at remote
+func (f *truncatingFs) NewObject(remote string) (fs.Object, error) {
+	o, err := f.Fs.NewObject(remote)
+	if err != nil {
+		return nil, err
+	}
+	return truncatingObject{Object: o, precision: f.precision}, nil
+}
+
+// truncatingObject is an fs.Object whose modification time is
+// truncated to precision
+type truncatingObject struct {
+	fs.Object
+	precision time.Duration
+}
+
+// ModTime returns the truncated modification time
+func (o truncatingObject) ModTime() time.Time {
+	return o.Object.ModTime().Truncate(o.precision)
+}
+
+// checkEmpty checks the test files have been tidied up
+func checkEmpty(t *testing.T, f fs.Fs) {
+	entries, err := f.List("")
+	require.NoError(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestCheckModTimePrecision(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Precision() > time.Microsecond {
+		t.Skip("remote isn't precise enough")
+	}
+
+	for _, precision := range []time.Duration{
+		time.Microsecond,
+		time.Millisecond,
+		10 * time.Millisecond,
+		100 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		time.Minute,
+		time.Hour,
+	} {
+		res := newResults(&truncatingFs{Fs: r.Fremote, precision: precision})
+		res.checkModTimePrecision()
+		assert.Equal(t, precision, res.modTimePrecision)
+		checkEmpty(t, r.Fremote)
+	}
+}
+
+func TestCheckDuplicateNames(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	res := newResults(r.Fremote)
+	res.checkDuplicateNames()
+	assert.False(t, res.canWriteDuplicates)
+	checkEmpty(t, r.Fremote)
+}
@@ -0,0 +1,168 @@
+// Package makefiles builds a directory structure with the required
+// number of files in of the required size.
+package makefiles
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/cmd/test"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags
+	numberOfFiles            = 1000
+	averageFilesPerDirectory = 10
+	maxDepth                 = 10
+	minFileSize              = fs.SizeSuffix(0)
+	maxFileSize              = fs.SizeSuffix(100)
+	minFileNameLength        = 4
+	maxFileNameLength        = 12
+	seed                     = int64(1)
+)
+
+func init() {
+	test.Command.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.IntVarP(cmdFlags, &numberOfFiles, "files", "", numberOfFiles, "Number of files to create")
+	flags.IntVarP(cmdFlags, &averageFilesPerDirectory, "files-per-directory", "", averageFilesPerDirectory, "Average number of files per directory")
+	flags.IntVarP(cmdFlags, &maxDepth, "max-dir-depth", "", maxDepth, "Maximum depth of directory hierarchy")
+	flags.FVarP(cmdFlags, &minFileSize, "min-file-size", "", "Minimum size of file to create")
+	flags.FVarP(cmdFlags, &maxFileSize, "max-file-size", "", "Maximum size of files to create")
+	flags.IntVarP(cmdFlags, &minFileNameLength, "min-name-length", "", minFileNameLength, "Minimum size of file names")
+	flags.IntVarP(cmdFlags, &maxFileNameLength, "max-name-length", "", maxFileNameLength, "Maximum size of file names")
+	flags.IntVar64P(cmdFlags, &seed, "seed", "", seed, "Seed for the random number generator (0 for random)")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "makefiles <dir>",
+	Short: `Make a random file hierarchy in <dir>`,
+	Long: `
+This makes a tree of directories under the local directory <dir>
+containing random files with random names, for testing.  Copy or sync
+it to a remote and check it with rclone check to see whether the
+remote can be trusted with a big sync, eg
+
+    rclone test makefiles --files 10000 /tmp/testfiles
+    rclone copy /tmp/testfiles remote:testfiles
+    rclone check /tmp/testfiles remote:testfiles
+
+The same --seed makes the same tree each time.  Use --seed 0 to make a
+different tree each time.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+			fs.Logf(nil, "Using random seed = %d", seed)
+		}
+		err := makeFiles(args[0], rand.New(rand.NewSource(seed)))
+		if err != nil {
+			log.Fatalf("Failed to make files: %v", err)
+		}
+	},
+}
+
+// fileNameChars are the characters used in the random file names
+const fileNameChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// dir is a directory in the tree being made
+type dir struct {
+	path  string
+	depth int
+}
+
+// randomName makes a random file name which isn't already used
+func randomName(r *rand.Rand, used map[string]struct{}) string {
+	for {
+		length := minFileNameLength
+		if maxFileNameLength > minFileNameLength {
+			length += r.Intn(maxFileNameLength - minFileNameLength + 1)
+		}
+		name := make([]byte, length)
+		for i := range name {
+			name[i] = fileNameChars[r.Intn(len(fileNameChars))]
+		}
+		if _, found := used[string(name)]; !found {
+			used[string(name)] = struct{}{}
+			return string(name)
+		}
+	}
+}
+
+// randomSize returns a random file size between the limits
+func randomSize(r *rand.Rand) int64 {
+	size := int64(minFileSize)
+	if maxFileSize > minFileSize {
+		size += r.Int63n(int64(maxFileSize-minFileSize) + 1)
+	}
+	return size
+}
+
+// writeFile writes a file of size random bytes at path
+func writeFile(r *rand.Rand, path string, size int64) (err error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fs.CheckClose(out, &err)
+	_, err = io.CopyN(out, r, size)
+	return err
+}
+
+// makeFiles makes the random file hierarchy in root using r
+func makeFiles(root string, r *rand.Rand) error {
+	if minFileNameLength < 1 || maxFileNameLength < minFileNameLength {
+		return errors.New("need 1 <= --min-name-length <= --max-name-length")
+	}
+	if maxFileSize < minFileSize {
+		return errors.New("need --min-file-size <= --max-file-size")
+	}
+	if averageFilesPerDirectory < 1 {
+		return errors.New("need --files-per-directory >= 1")
+	}
+	err := os.MkdirAll(root, 0777)
+	if err != nil {
+		return err
+	}
+	used := map[string]struct{}{}
+	dirs := []dir{{path: root}}
+	var totalBytes int64
+	start := time.Now()
+	for i := 0; i < numberOfFiles; i++ {
+		// Add a new directory every averageFilesPerDirectory files
+		// on average under a random existing directory
+		if r.Intn(averageFilesPerDirectory) == 0 {
+			parent := dirs[r.Intn(len(dirs))]
+			if parent.depth < maxDepth {
+				newDir := dir{
+					path:  filepath.Join(parent.path, randomName(r, used)),
+					depth: parent.depth + 1,
+				}
+				err = os.Mkdir(newDir.path, 0777)
+				if err != nil {
+					return err
+				}
+				dirs = append(dirs, newDir)
+			}
+		}
+		parent := dirs[r.Intn(len(dirs))]
+		size := randomSize(r)
+		err = writeFile(r, filepath.Join(parent.path, randomName(r, used)), size)
+		if err != nil {
+			return err
+		}
+		totalBytes += size
+	}
+	fs.Logf(nil, "Made %d files (%v) in %d directories in %v", numberOfFiles, fs.SizeSuffix(totalBytes), len(dirs), time.Since(start))
+	return nil
+}
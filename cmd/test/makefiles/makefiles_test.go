@@ -0,0 +1,58 @@
+package makefiles
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listTree returns the relative paths and sizes of the files under root
+func listTree(t *testing.T, root string) map[string]int64 {
+	files := map[string]int64{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if !info.IsDir() {
+			rel, err := filepath.Rel(root, path)
+			require.NoError(t, err)
+			files[rel] = info.Size()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestMakeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "makefiles")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	oldNumberOfFiles := numberOfFiles
+	numberOfFiles = 100
+	defer func() { numberOfFiles = oldNumberOfFiles }()
+
+	one := filepath.Join(dir, "one")
+	require.NoError(t, makeFiles(one, rand.New(rand.NewSource(1))))
+	files := listTree(t, one)
+	assert.Equal(t, numberOfFiles, len(files))
+	for path, size := range files {
+		name := filepath.Base(path)
+		assert.True(t, len(name) >= minFileNameLength && len(name) <= maxFileNameLength, path)
+		assert.True(t, size >= int64(minFileSize) && size <= int64(maxFileSize), path)
+	}
+
+	// The same seed makes the same tree
+	two := filepath.Join(dir, "two")
+	require.NoError(t, makeFiles(two, rand.New(rand.NewSource(1))))
+	assert.Equal(t, files, listTree(t, two))
+
+	oldMinFileNameLength := minFileNameLength
+	minFileNameLength = maxFileNameLength + 1
+	defer func() { minFileNameLength = oldMinFileNameLength }()
+	assert.Error(t, makeFiles(filepath.Join(dir, "three"), rand.New(rand.NewSource(1))))
+}
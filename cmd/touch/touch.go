@@ -23,12 +23,21 @@ func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	flags := commandDefintion.Flags()
 	flags.BoolVarP(&notCreateNewFile, "no-create", "C", false, "Do not create the file if it does not exist.")
-	flags.StringVarP(&timeAsArgument, "timestamp", "t", "", "Change the modification times to the specified time instead of the current time of day. The argument is of the form 'YYMMDD' (ex. 17.10.30) or 'YYYY-MM-DDTHH:MM:SS' (ex. 2006-01-02T15:04:05)")
+	flags.StringVarP(&timeAsArgument, "timestamp", "t", "", "Change the modification times to the specified time instead of the current time of day. The argument is of the form 'YYMMDD' (ex. 171030) or 'YYYY-MM-DDTHH:MM:SS' (ex. 2006-01-02T15:04:05)")
 }
 
 var commandDefintion = &cobra.Command{
 	Use:   "touch remote:path",
 	Short: `Create new file or change file modification time.`,
+	Long: `
+Set the modification time of the file at remote:path to the current
+time, or the time given with --timestamp, creating an empty file there
+if it doesn't exist.
+
+Use --no-create to only change existing files.  The time of an
+existing file is set using the remote's ability to set modification
+times so this fails on remotes which can't do that.
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc, srcFileName := cmd.NewFsDstFile(args)
@@ -38,7 +47,11 @@ var commandDefintion = &cobra.Command{
 	},
 }
 
-//Touch create new file or change file modification time.
+// Touch creates a new empty file or changes the modification time
+// of an existing one.
+//
+// The modification time is set with the backend's SetModTime so
+// this returns an error if the backend can't do that.
 func Touch(fsrc fs.Fs, srcFileName string) error {
 	timeAtr := time.Now()
 	if timeAsArgument != "" {
@@ -53,16 +66,22 @@ func Touch(fsrc fs.Fs, srcFileName string) error {
 		timeAtr = timeAtrFromFlags
 	}
 	file, err := fsrc.NewObject(srcFileName)
-	if err != nil {
-		if !notCreateNewFile {
-			var buffer []byte
-			src := object.NewStaticObjectInfo(srcFileName, timeAtr, int64(len(buffer)), true, nil, fsrc)
-			_, err = fsrc.Put(bytes.NewBuffer(buffer), src)
-			if err != nil {
-				return err
-			}
+	if err == fs.ErrorObjectNotFound {
+		if notCreateNewFile {
+			return nil
+		}
+		var buffer []byte
+		src := object.NewStaticObjectInfo(srcFileName, timeAtr, int64(len(buffer)), true, nil, fsrc)
+		_, err = fsrc.Put(bytes.NewBuffer(buffer), src)
+		if err != nil {
+			return errors.Wrap(err, "touch: couldn't create file")
 		}
 		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "touch: couldn't read file")
+	}
+	if fsrc.Precision() == fs.ModTimeNotSupported {
+		return errors.Wrapf(fs.ErrorCantSetModTime, "touch: %v doesn't support modification times", fsrc)
 	}
 	err = file.SetModTime(timeAtr)
 	if err != nil {
@@ -6,6 +6,8 @@ import (
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fstest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	_ "github.com/ncw/rclone/backend/local"
@@ -117,3 +119,58 @@ func TestTouchCreateMultipleDirAndFile(t *testing.T) {
 	file1 := fstest.NewItem("a/b/c.txt", "", t1)
 	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1}, []string{"a", "a/b"}, fs.ModTimeNotSupported)
 }
+
+// errorFs is an fs.Fs which fails to read objects with err
+type errorFs struct {
+	fs.Fs
+	err error
+}
+
+// NewObject returns the error
+func (f *errorFs) NewObject(remote string) (fs.Object, error) {
+	return nil, f.err
+}
+
+func TestTouchReadError(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	err := Touch(&errorFs{Fs: r.Fremote, err: errors.New("boom")}, "newFile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "touch: couldn't read file: boom")
+	fstest.CheckItems(t, r.Fremote)
+}
+
+func TestTouchCreateObjectNotFound(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	timeAsArgument = "121212"
+	err := Touch(&errorFs{Fs: r.Fremote, err: fs.ErrorObjectNotFound}, "newFile")
+	require.NoError(t, err)
+	checkFile(t, r.Fremote, "newFile", "")
+}
+
+// noModTimeFs is an fs.Fs which doesn't support modification times
+type noModTimeFs struct {
+	fs.Fs
+}
+
+// Precision returns fs.ModTimeNotSupported
+func (f *noModTimeFs) Precision() time.Duration {
+	return fs.ModTimeNotSupported
+}
+
+func TestTouchModTimeNotSupported(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteObject("a", "aaa", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	timeAsArgument = "121212"
+	err := Touch(&noModTimeFs{Fs: r.Fremote}, "a")
+	require.Error(t, err)
+	assert.Equal(t, fs.ErrorCantSetModTime, errors.Cause(err))
+	fstest.CheckItems(t, r.Fremote, file1)
+}
@@ -58,7 +58,7 @@ func init() {
 	flags.BoolVarP(&opts.DirSort, "dirsfirst", "", false, "List directories before files (-U disables).")
 	flags.StringVarP(&sort, "sort", "", "", "Select sort: name,version,size,mtime,ctime.")
 	// Graphics
-	flags.BoolVarP(&opts.NoIndent, "noindent", "i", false, "Don't print indentation lines.")
+	flags.BoolVarP(&opts.NoIndent, "noindent", "", false, "Don't print indentation lines.")
 	flags.BoolVarP(&opts.Colorize, "color", "C", false, "Turn colorization on always.")
 }
 
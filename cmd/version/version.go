@@ -1,19 +1,228 @@
 package version
 
 import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var (
+	check = false
+)
+
+// Where to find the latest release and beta versions
+var (
+	releaseURL = "https://downloads.rclone.org/"
+	betaURL    = "https://beta.rclone.org/"
+)
+
+// exitCodeOutdated is returned by version --check if there is a
+// newer version available
+const exitCodeOutdated = 2
+
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
+	flags.BoolVarP(commandDefinition.Flags(), &check, "check", "", false, "Check for new version.")
 }
 
 var commandDefinition = &cobra.Command{
 	Use:   "version",
 	Short: `Show the version number.`,
+	Long: `
+Show the version number, the go version and the architecture.
+
+Eg
+
+    $ rclone version
+    rclone v1.41
+    - os/arch: linux/amd64
+    - go version: go1.10
+
+If you supply the --check flag, then it will do an online check to
+compare your version with the latest release and the latest beta.
+
+    $ rclone version --check
+    yours:  1.41
+    latest: 1.42          (released 2018-06-16)
+      upgrade: https://downloads.rclone.org/v1.42
+    beta:   1.42.5        (released 2018-06-17)
+
+If you are running a beta then it is compared against the latest beta
+as well as the latest release.
+
+The exit code is 0 if your version is up to date and 2 if there is a
+newer version you should upgrade to, so this can be used for
+monitoring.  If the check couldn't be done the exit code is 1.
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(0, 0, command, args)
-		cmd.ShowVersion()
+		if !check {
+			cmd.ShowVersion()
+			return
+		}
+		outdated, err := checkVersion(os.Stdout, fs.Version)
+		if err != nil {
+			log.Fatalf("Failed to check version: %v", err)
+		}
+		if outdated {
+			os.Exit(exitCodeOutdated)
+		}
 	},
 }
+
+// version is a parsed rclone version
+//
+// A release like v1.41 is [1, 41] and a beta like
+// v1.41-012-g1234abcd which is 12 commits after v1.41 is [1, 41, 12]
+type version []int
+
+// cleanVersion removes the decoration from a version string so
+// "rclone v1.41-012-g1234abcdβ" becomes "v1.41-012-g1234abcd"
+func cleanVersion(in string) string {
+	in = strings.TrimSpace(in)
+	in = strings.TrimPrefix(in, "rclone ")
+	in = strings.TrimSuffix(in, "β")
+	return in
+}
+
+// newVersion parses a version string as produced by the build
+func newVersion(in string) (v version, err error) {
+	parts := strings.Split(strings.TrimPrefix(cleanVersion(in), "v"), "-")
+	for _, part := range strings.Split(parts[0], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, errors.Errorf("invalid version %q", in)
+		}
+		v = append(v, n)
+	}
+	// Add the number of commits since the release for betas
+	if len(parts) > 1 {
+		if n, err := strconv.Atoi(parts[1]); err == nil {
+			v = append(v, n)
+		}
+	}
+	return v, nil
+}
+
+// String converts v to a string
+func (v version) String() string {
+	var out []string
+	for _, n := range v {
+		out = append(out, strconv.Itoa(n))
+	}
+	return strings.Join(out, ".")
+}
+
+// cmp compares two versions returning >0, <0 or 0
+func (v version) cmp(o version) int {
+	for i := 0; i < len(v) || i < len(o); i++ {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		if a != b {
+			return a - b
+		}
+	}
+	return 0
+}
+
+// isBeta returns true if v is a beta rather than a release
+func (v version) isBeta() bool {
+	return len(v) > 2
+}
+
+// isGit returns true if the version string is from a build which
+// isn't a release or a beta, so comparisons may be wrong
+func isGit(in string) bool {
+	parts := strings.Split(cleanVersion(in), "-")
+	if len(parts) == 2 && parts[1] == "DEV" {
+		return true
+	}
+	// A beta is vX.YY-NNN-gCOMMIT - anything after that is a branch
+	return len(parts) > 3
+}
+
+// getVersion gets the version by reading version.txt from the
+// download repository passed in
+func getVersion(baseURL string) (v version, vs string, date time.Time, err error) {
+	resp, err := fshttp.NewClient(fs.Config).Get(baseURL + "version.txt")
+	if err != nil {
+		return v, vs, date, err
+	}
+	defer fs.CheckClose(resp.Body, &err)
+	if resp.StatusCode != http.StatusOK {
+		return v, vs, date, errors.New(resp.Status)
+	}
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return v, vs, date, err
+	}
+	vs = cleanVersion(string(bodyBytes))
+	date, err = http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return v, vs, date, err
+	}
+	v, err = newVersion(vs)
+	return v, vs, date, err
+}
+
+// checkVersion writes a comparison of the current version against
+// the latest release and beta to out
+//
+// It returns true if there is a newer version the user should
+// upgrade to.  A beta is only compared against the latest beta if
+// the current version is a beta too.
+func checkVersion(out io.Writer, current string) (outdated bool, err error) {
+	vCurrent, err := newVersion(current)
+	if err != nil {
+		return false, err
+	}
+	const timeFormat = "2006-01-02"
+
+	printVersion := func(what, baseURL string, compare bool) error {
+		v, vs, t, err := getVersion(baseURL)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get rclone %s version", what)
+		}
+		_, _ = fmt.Fprintf(out, "%-8s%-13v %20s\n",
+			what+":",
+			v,
+			"(released "+t.Format(timeFormat)+")",
+		)
+		if compare && v.cmp(vCurrent) > 0 {
+			_, _ = fmt.Fprintf(out, "  upgrade: %s\n", baseURL+vs)
+			outdated = true
+		}
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "yours:  %-13s\n", vCurrent)
+	err = printVersion("latest", releaseURL, true)
+	if err != nil {
+		return false, err
+	}
+	err = printVersion("beta", betaURL, vCurrent.isBeta())
+	if err != nil {
+		return false, err
+	}
+	if isGit(current) {
+		_, _ = fmt.Fprintln(out, "Your version is compiled from git so comparisons may be wrong.")
+	}
+	return outdated, nil
+}
@@ -1,13 +1,19 @@
 package version
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVersionWorksWithoutAccessibleConfigFile(t *testing.T) {
@@ -41,3 +47,110 @@ func TestVersionWorksWithoutAccessibleConfigFile(t *testing.T) {
 		assert.NoError(t, cmd.Root.Execute())
 	})
 }
+
+func TestNewVersion(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    version
+		wantErr bool
+		isGit   bool
+	}{
+		{"v1.41", version{1, 41}, false, false},
+		{"rclone v1.41\n", version{1, 41}, false, false},
+		{"v1.41-DEV", version{1, 41}, false, true},
+		{"v1.41-012-g1234abcd", version{1, 41, 12}, false, false},
+		{"rclone v1.41-012-g1234abcdβ", version{1, 41, 12}, false, false},
+		{"v1.41-012-g1234abcd-fix-potato", version{1, 41, 12}, false, true},
+		{"1.42", version{1, 42}, false, false},
+		{"potato", nil, true, false},
+	} {
+		what := fmt.Sprintf("in=%q", test.in)
+		got, err := newVersion(test.in)
+		if test.wantErr {
+			assert.Error(t, err, what)
+			continue
+		}
+		require.NoError(t, err, what)
+		assert.Equal(t, test.want, got, what)
+		assert.Equal(t, test.isGit, isGit(test.in), what)
+	}
+}
+
+func TestVersionCmp(t *testing.T) {
+	for _, test := range []struct {
+		a, b version
+		want int
+	}{
+		{version{1, 41}, version{1, 41}, 0},
+		{version{1, 41}, version{1, 42}, -1},
+		{version{1, 42}, version{1, 41}, 1},
+		{version{1, 41}, version{1, 41, 12}, -1},
+		{version{1, 41, 12}, version{1, 41, 5}, 1},
+		{version{1, 42}, version{1, 41, 12}, 1},
+		{version{2}, version{1, 99}, 1},
+	} {
+		got := test.a.cmp(test.b)
+		if got > 0 {
+			got = 1
+		} else if got < 0 {
+			got = -1
+		}
+		assert.Equal(t, test.want, got, fmt.Sprintf("%v cmp %v", test.a, test.b))
+	}
+	assert.Equal(t, "1.41.12", version{1, 41, 12}.String())
+	assert.True(t, version{1, 41, 12}.isBeta())
+	assert.False(t, version{1, 41}.isBeta())
+}
+
+func TestCheckVersion(t *testing.T) {
+	serve := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/version.txt" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Last-Modified", "Sat, 16 Jun 2018 10:00:00 GMT")
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	release := serve("rclone v1.42\n")
+	defer release.Close()
+	beta := serve("rclone v1.42-005-g56e1e820β\n")
+	defer beta.Close()
+
+	oldReleaseURL, oldBetaURL := releaseURL, betaURL
+	releaseURL, betaURL = release.URL+"/", beta.URL+"/"
+	defer func() {
+		releaseURL, betaURL = oldReleaseURL, oldBetaURL
+	}()
+
+	for _, test := range []struct {
+		current  string
+		outdated bool
+		upgrade  string
+	}{
+		{"v1.42", false, ""},
+		{"v1.41", true, release.URL + "/v1.42"},
+		{"v1.42-003-gabcdef01", true, beta.URL + "/v1.42-005-g56e1e820"},
+		{"v1.42-005-g56e1e820", false, ""},
+		{"v1.43", false, ""},
+	} {
+		var out bytes.Buffer
+		outdated, err := checkVersion(&out, test.current)
+		require.NoError(t, err, test.current)
+		assert.Equal(t, test.outdated, outdated, test.current)
+		assert.Contains(t, out.String(), "(released 2018-06-16)", test.current)
+		if test.upgrade != "" {
+			assert.Contains(t, out.String(), "  upgrade: "+test.upgrade+"\n", test.current)
+		} else {
+			assert.False(t, strings.Contains(out.String(), "upgrade:"), test.current)
+		}
+	}
+
+	_, err := checkVersion(ioutil.Discard, "potato")
+	assert.Error(t, err)
+
+	betaURL = release.URL + "/missing/"
+	_, err = checkVersion(ioutil.Discard, "v1.42")
+	assert.Error(t, err)
+}
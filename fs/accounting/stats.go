@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/lib/pacer"
 )
 
 var (
@@ -25,6 +27,7 @@ type StatsInfo struct {
 	mu           sync.RWMutex
 	bytes        int64
 	errors       int64
+	errorClasses map[fserrors.Class]int64
 	lastError    error
 	checks       int64
 	checking     *stringSet
@@ -38,6 +41,7 @@ type StatsInfo struct {
 // NewStats cretates an initialised StatsInfo
 func NewStats() *StatsInfo {
 	return &StatsInfo{
+		errorClasses: make(map[fserrors.Class]int64),
 		checking:     newStringSet(fs.Config.Checkers),
 		transferring: newStringSet(fs.Config.Transfers),
 		start:        time.Now(),
@@ -64,13 +68,13 @@ func (s *StatsInfo) String() string {
 
 	fmt.Fprintf(buf, `
 Transferred:   %10s (%s)
-Errors:        %10d
+Errors:        %10d%s
 Checks:        %10d
 Transferred:   %10d
 Elapsed time:  %10v
 `,
 		fs.SizeSuffix(s.bytes).Unit("Bytes"), fs.SizeSuffix(speed).Unit(strings.Title(fs.Config.DataRateUnit)+"/s"),
-		s.errors,
+		s.errors, s.errorClassesString(),
 		s.checks,
 		s.transfers,
 		dtRounded)
@@ -79,6 +83,12 @@ Elapsed time:  %10v
 	// here to prevent deadlock on GetBytes
 	s.mu.RUnlock()
 
+	if pacers := pacer.AllStats(); len(pacers) > 0 {
+		fmt.Fprintf(buf, "Pacers:\n")
+		for _, st := range pacers {
+			fmt.Fprintf(buf, " * %s: sleep %v, %d low level retries, %d rate limited\n", st.Name, st.SleepTime, st.Retries, st.RateLimited)
+		}
+	}
 	if !s.checking.empty() {
 		fmt.Fprintf(buf, "Checking:\n%s\n", s.checking)
 	}
@@ -88,6 +98,24 @@ Elapsed time:  %10v
 	return buf.String()
 }
 
+// errorClassesString returns the counts of the classes of errors
+// seen, eg " (quota exceeded: 2, network: 1)", or "" if there are
+// none.
+//
+// Call with s.mu held
+func (s *StatsInfo) errorClassesString() string {
+	var out []string
+	for _, class := range fserrors.Classes {
+		if n := s.errorClasses[class]; n > 0 {
+			out = append(out, fmt.Sprintf("%v: %d", class, n))
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(out, ", ") + ")"
+}
+
 // Log outputs the StatsInfo to the log
 func (s *StatsInfo) Log() {
 	fs.LogLevelPrintf(fs.Config.StatsLogLevel, nil, "%v\n", s)
@@ -138,10 +166,11 @@ func (s *StatsInfo) Deletes(deletes int64) int64 {
 
 // ResetCounters sets the counters (bytes, checks, errors, transfers) to 0
 func (s *StatsInfo) ResetCounters() {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.bytes = 0
 	s.errors = 0
+	s.errorClasses = make(map[fserrors.Class]int64)
 	s.checks = 0
 	s.transfers = 0
 	s.deletes = 0
@@ -149,9 +178,10 @@ func (s *StatsInfo) ResetCounters() {
 
 // ResetErrors sets the errors count to 0
 func (s *StatsInfo) ResetErrors() {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.errors = 0
+	s.errorClasses = make(map[fserrors.Class]int64)
 }
 
 // Errored returns whether there have been any errors
@@ -166,9 +196,17 @@ func (s *StatsInfo) Error(err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.errors++
+	s.errorClasses[fserrors.Classify(err)]++
 	s.lastError = err
 }
 
+// GetErrorClass reads the number of errors of class seen
+func (s *StatsInfo) GetErrorClass(class fserrors.Class) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.errorClasses[class]
+}
+
 // Checking adds a check into the stats
 func (s *StatsInfo) Checking(remote string) {
 	s.checking.add(remote)
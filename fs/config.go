@@ -32,12 +32,14 @@ type ConfigInfo struct {
 	LogLevel              LogLevel
 	StatsLogLevel         LogLevel
 	DryRun                bool
+	Interactive           bool
 	CheckSum              bool
 	SizeOnly              bool
 	IgnoreTimes           bool
 	IgnoreExisting        bool
 	IgnoreErrors          bool
 	ModifyWindow          time.Duration
+	ModifyWindowSet       bool
 	Checkers              int
 	Transfers             int
 	ConnectTimeout        time.Duration // Connect timeout
@@ -53,7 +55,10 @@ type ConfigInfo struct {
 	MaxDepth              int
 	IgnoreSize            bool
 	IgnoreChecksum        bool
+	Verify                bool // Re-read and check the hash of each file after transfer
 	NoUpdateModTime       bool
+	NoTraverse            bool // Look up each destination file rather than listing the destination
+	NoCheckDest           bool // Don't check the destination at all - just upload
 	DataRateUnit          string
 	BackupDir             string
 	Suffix                string
@@ -72,6 +77,12 @@ type ConfigInfo struct {
 	AskPassword           bool
 	UseServerModTime      bool
 	MaxTransfer           SizeSuffix
+	StopTimeout           time.Duration
+	DestLock              bool
+	DestLockWait          time.Duration
+	HashDB                bool
+	UploadHeaders         []*HTTPOption
+	DownloadHeaders       []*HTTPOption
 }
 
 // NewConfig creates a new config with everything set to the default
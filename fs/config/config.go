@@ -21,6 +21,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/ncw/rclone/fs/config/obscure"
 	"github.com/ncw/rclone/fs/driveletter"
 	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/ncw/rclone/lib/lockfile"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/nacl/secretbox"
 	"golang.org/x/text/unicode/norm"
@@ -74,6 +76,14 @@ var (
 	// Key to use for password en/decryption.
 	// When nil, no encryption will be used for saving.
 	configKey []byte
+
+	// saveMu serialises SetValueAndSave so concurrent updates,
+	// eg of refreshed tokens, don't overwrite each other
+	saveMu sync.Mutex
+
+	// configLockWait is how long to wait for another rclone to
+	// finish updating the config file
+	configLockWait = 10 * time.Second
 )
 
 func init() {
@@ -430,14 +440,22 @@ func saveConfig() error {
 		fs.Errorf(nil, "Failed to set permissions on config file: %v", err)
 	}
 
-	if err = os.Rename(ConfigPath, ConfigPath+".old"); err != nil && !os.IsNotExist(err) {
-		return errors.Errorf("Failed to move previous config to backup location: %v", err)
+	// Rename the new config over the old one so anything reading
+	// it sees either the old or the new config, never a partial
+	// one.  Windows can't rename over an existing file so move the
+	// old one out of the way first there.
+	if runtime.GOOS == "windows" {
+		if err = os.Rename(ConfigPath, ConfigPath+".old"); err != nil && !os.IsNotExist(err) {
+			return errors.Errorf("Failed to move previous config to backup location: %v", err)
+		}
 	}
 	if err = os.Rename(f.Name(), ConfigPath); err != nil {
 		return errors.Errorf("Failed to move newly written config from %s to final location: %v", f.Name(), err)
 	}
-	if err := os.Remove(ConfigPath + ".old"); err != nil && !os.IsNotExist(err) {
-		fs.Errorf(nil, "Failed to remove backup config file: %v", err)
+	if runtime.GOOS == "windows" {
+		if err := os.Remove(ConfigPath + ".old"); err != nil && !os.IsNotExist(err) {
+			fs.Errorf(nil, "Failed to remove backup config file: %v", err)
+		}
 	}
 	return nil
 }
@@ -462,6 +480,7 @@ func SaveConfig() {
 // value in the config file.  It loads the old config file in from
 // disk first and overwrites the given value only.
 func SetValueAndSave(name, key, value string) (err error) {
+	defer lockConfig()()
 	// Set the value in config in case we fail to reload it
 	getConfigData().SetValue(name, key, value)
 	// Reload the config file
@@ -486,6 +505,49 @@ func SetValueAndSave(name, key, value string) (err error) {
 	return nil
 }
 
+// lockConfig takes the config lock, returning a function to release
+// it.
+//
+// This stops other goroutines and, using a lock file next to the
+// config file, other rclone processes from updating the config file
+// at the same time.  If the lock file can't be taken only the other
+// goroutines are locked out.
+func lockConfig() (unlock func()) {
+	saveMu.Lock()
+	lock, err := lockfile.New(ConfigPath+".lock", "rclone config", configLockWait)
+	if err != nil {
+		fs.Debugf(nil, "Failed to lock config file: %v", err)
+	}
+	return func() {
+		if lock != nil {
+			if err := lock.Unlock(); err != nil {
+				fs.Debugf(nil, "Failed to unlock config file: %v", err)
+			}
+		}
+		saveMu.Unlock()
+	}
+}
+
+// FileGetFresh gets the config key under section like FileGet but
+// re-reads the config file from disk first, under the config lock,
+// so it sees changes other rclone processes have saved, eg refreshed
+// tokens.
+//
+// If the config file can't be re-read the value in memory is used.
+func FileGetFresh(section, key string, defaultVal ...string) string {
+	unlock := lockConfig()
+	reloadedConfigFile, err := loadConfigFile()
+	if err == nil {
+		if _, err = reloadedConfigFile.GetSection(section); err == nil {
+			configFile = reloadedConfigFile
+		}
+	} else if err != errorConfigFileNotFound {
+		fs.Debugf(nil, "Failed to re-read config file: %v", err)
+	}
+	unlock()
+	return FileGet(section, key, defaultVal...)
+}
+
 // ShowRemotes shows an overview of the config file
 func ShowRemotes() {
 	remotes := getConfigData().GetSectionList()
@@ -917,6 +979,21 @@ func EditRemote(ri *fs.RegInfo, name string) {
 	RemoteConfig(name)
 }
 
+// ReconnectRemote redoes the authorization of the remote called name
+// in place, leaving the rest of its config alone
+func ReconnectRemote(name string) error {
+	if _, err := getConfigData().GetSection(name); err != nil {
+		return errors.Errorf("couldn't find remote %q in config file", name)
+	}
+	ri := MustFindByName(name)
+	if ri.Config == nil {
+		return errors.Errorf("%s remotes don't need reconnecting", ri.Name)
+	}
+	ri.Config(name)
+	SaveConfig()
+	return nil
+}
+
 // DeleteRemote gets the user to delete a remote
 func DeleteRemote(name string) {
 	getConfigData().DeleteSection(name)
@@ -81,6 +81,93 @@ func TestCRUD(t *testing.T) {
 	assert.Equal(t, []string{}, configFile.GetSectionList())
 }
 
+// setupTestConfig points the config at a temporary file containing
+// contents, returning a function to undo it
+func setupTestConfig(t *testing.T, contents string) (path string, undo func()) {
+	tempFile, err := ioutil.TempFile("", "rclone-test.conf")
+	require.NoError(t, err)
+	path = tempFile.Name()
+	_, err = tempFile.WriteString(contents)
+	require.NoError(t, err)
+	require.NoError(t, tempFile.Close())
+
+	oldConfigPath := ConfigPath
+	oldConfigFile := configFile
+	ConfigPath = path
+	configFile = nil
+	configKey = nil // reset password
+	LoadConfig()
+	return path, func() {
+		ConfigPath = oldConfigPath
+		configFile = oldConfigFile
+		configKey = nil // reset password
+		assert.NoError(t, os.Remove(path))
+		_ = os.Remove(path + ".lock")
+	}
+}
+
+func TestFileGetFresh(t *testing.T) {
+	path, undo := setupTestConfig(t, "[remote]\ntype = local\ntoken = old\n")
+	defer undo()
+	assert.Equal(t, "old", FileGet("remote", "token"))
+
+	// Another rclone updates the config file
+	require.NoError(t, ioutil.WriteFile(path, []byte("[remote]\ntype = local\ntoken = new\n"), 0600))
+	assert.Equal(t, "old", FileGet("remote", "token"))
+	assert.Equal(t, "new", FileGetFresh("remote", "token"))
+	assert.Equal(t, "new", FileGet("remote", "token"))
+
+	// The remote has been removed from the file so the value in
+	// memory is kept
+	require.NoError(t, ioutil.WriteFile(path, []byte("[other]\ntype = local\n"), 0600))
+	assert.Equal(t, "new", FileGetFresh("remote", "token"))
+}
+
+func TestSetValueAndSaveEncrypted(t *testing.T) {
+	path, undo := setupTestConfig(t, "[remote]\ntype = local\ntoken = old\n")
+	defer undo()
+	require.NoError(t, setConfigPassword("asdf"))
+
+	require.NoError(t, SetValueAndSave("remote", "token", "new"))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "RCLONE_ENCRYPT_V0:")
+	assert.NotContains(t, string(data), "token")
+	_, err = os.Stat(path + ".old")
+	assert.True(t, os.IsNotExist(err))
+
+	// Check it can be read back
+	configFile = nil
+	assert.Equal(t, "new", FileGetFresh("remote", "token"))
+}
+
+func TestReconnectRemote(t *testing.T) {
+	_, undo := setupTestConfig(t, "[remote]\ntype = config_test_reconnect\ntoken = old\n\n[plain]\ntype = config_test_noconfig\n")
+	defer undo()
+	configured := ""
+	fs.Register(&fs.RegInfo{
+		Name: "config_test_reconnect",
+		Config: func(name string) {
+			configured = name
+			require.NoError(t, SetValueAndSave(name, ConfigToken, "new"))
+		},
+	})
+	fs.Register(&fs.RegInfo{Name: "config_test_noconfig"})
+
+	require.NoError(t, ReconnectRemote("remote"))
+	assert.Equal(t, "remote", configured)
+	assert.Equal(t, "new", FileGetFresh("remote", ConfigToken))
+	assert.Equal(t, "config_test_reconnect", FileGet("remote", "type"))
+
+	err := ReconnectRemote("plain")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "don't need reconnecting")
+
+	err = ReconnectRemote("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "couldn't find remote")
+}
+
 // Test some error cases
 func TestReveal(t *testing.T) {
 	for _, test := range []struct {
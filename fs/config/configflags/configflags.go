@@ -26,7 +26,8 @@ var (
 	deleteAfter     bool
 	bindAddr        string
 	disableFeatures string
-	noTraverse      bool
+	uploadHeaders   []string
+	downloadHeaders []string
 )
 
 // AddFlags adds the non filing system specific flags to the command
@@ -45,6 +46,7 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreExisting, "ignore-existing", "", fs.Config.IgnoreExisting, "Skip all files that exist on destination")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreErrors, "ignore-errors", "", fs.Config.IgnoreErrors, "delete even if there are I/O errors")
 	flags.BoolVarP(flagSet, &fs.Config.DryRun, "dry-run", "n", fs.Config.DryRun, "Do a trial run with no permanent changes")
+	flags.BoolVarP(flagSet, &fs.Config.Interactive, "interactive", "i", fs.Config.Interactive, "Ask before doing anything which changes a remote")
 	flags.DurationVarP(flagSet, &fs.Config.ConnectTimeout, "contimeout", "", fs.Config.ConnectTimeout, "Connect timeout")
 	flags.DurationVarP(flagSet, &fs.Config.Timeout, "timeout", "", fs.Config.Timeout, "IO idle timeout")
 	flags.BoolVarP(flagSet, &dumpHeaders, "dump-headers", "", false, "Dump HTTP bodies - may contain sensitive info")
@@ -63,7 +65,9 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.IntVarP(flagSet, &fs.Config.MaxDepth, "max-depth", "", fs.Config.MaxDepth, "If set limits the recursion depth to this.")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreSize, "ignore-size", "", false, "Ignore size when skipping use mod-time or checksum.")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreChecksum, "ignore-checksum", "", fs.Config.IgnoreChecksum, "Skip post copy check of checksums.")
-	flags.BoolVarP(flagSet, &noTraverse, "no-traverse", "", noTraverse, "Obsolete - does nothing.")
+	flags.BoolVarP(flagSet, &fs.Config.Verify, "verify", "", fs.Config.Verify, "Re-read each file after transfer and check its hash, downloading it if necessary.")
+	flags.BoolVarP(flagSet, &fs.Config.NoTraverse, "no-traverse", "", fs.Config.NoTraverse, "Don't traverse destination file system on copy.")
+	flags.BoolVarP(flagSet, &fs.Config.NoCheckDest, "no-check-dest", "", fs.Config.NoCheckDest, "Don't check the destination, copy regardless.")
 	flags.BoolVarP(flagSet, &fs.Config.NoUpdateModTime, "no-update-modtime", "", fs.Config.NoUpdateModTime, "Don't update destination mod-time if files identical.")
 	flags.StringVarP(flagSet, &fs.Config.BackupDir, "backup-dir", "", fs.Config.BackupDir, "Make backups into hierarchy based in DIR.")
 	flags.StringVarP(flagSet, &fs.Config.Suffix, "suffix", "", fs.Config.Suffix, "Suffix for use with --backup-dir.")
@@ -78,11 +82,38 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.IntVarP(flagSet, &fs.Config.StatsFileNameLength, "stats-file-name-length", "", fs.Config.StatsFileNameLength, "Max file name length in stats. 0 for no limit")
 	flags.FVarP(flagSet, &fs.Config.LogLevel, "log-level", "", "Log level DEBUG|INFO|NOTICE|ERROR")
 	flags.FVarP(flagSet, &fs.Config.StatsLogLevel, "stats-log-level", "", "Log level to show --stats output DEBUG|INFO|NOTICE|ERROR")
+	flags.SetValues(flagSet, "log-level", "DEBUG", "INFO", "NOTICE", "ERROR")
+	flags.SetValues(flagSet, "stats-log-level", "DEBUG", "INFO", "NOTICE", "ERROR")
 	flags.FVarP(flagSet, &fs.Config.BwLimit, "bwlimit", "", "Bandwidth limit in kBytes/s, or use suffix b|k|M|G or a full timetable.")
 	flags.FVarP(flagSet, &fs.Config.BufferSize, "buffer-size", "", "Buffer size when copying files.")
 	flags.FVarP(flagSet, &fs.Config.StreamingUploadCutoff, "streaming-upload-cutoff", "", "Cutoff for switching to chunked upload if file size is unknown. Upload starts after reaching cutoff or when file ends.")
 	flags.FVarP(flagSet, &fs.Config.Dump, "dump", "", "List of items to dump from: "+fs.DumpFlagsList)
+	flags.SetValues(flagSet, "dump", strings.Split(fs.DumpFlagsList, ",")...)
 	flags.FVarP(flagSet, &fs.Config.MaxTransfer, "max-transfer", "", "Maximum size of data to transfer.")
+	flags.BoolVarP(flagSet, &fs.Config.DestLock, "dest-lock", "", fs.Config.DestLock, "Lock the destination so only one sync, copy or move can use it at once.")
+	flags.DurationVarP(flagSet, &fs.Config.DestLockWait, "dest-lock-wait", "", fs.Config.DestLockWait, "Time to wait for the --dest-lock before giving up (0 to give up straight away).")
+	flags.BoolVarP(flagSet, &fs.Config.HashDB, "hash-db", "", fs.Config.HashDB, "Record the hashes of files transferred to check with \"check --against-db\".")
+	flags.DurationVarP(flagSet, &fs.Config.StopTimeout, "stop-timeout", "", fs.Config.StopTimeout, "Max time to wait for transfers to finish after an interrupt (0 for no limit).")
+	flags.StringArrayVarP(flagSet, &uploadHeaders, "header-upload", "", nil, "Set HTTP header for upload transactions")
+	flags.StringArrayVarP(flagSet, &downloadHeaders, "header-download", "", nil, "Set HTTP header for download transactions")
+}
+
+// parseHeaders converts the strings passed in via the header flags
+// into HTTPOptions
+func parseHeaders(flagName string, headers []string) []*fs.HTTPOption {
+	opts := []*fs.HTTPOption{}
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) == 1 {
+			log.Fatalf("--%s: Failed to parse %q as an HTTP header. Expecting a string like: 'Content-Encoding: gzip'", flagName, header)
+		}
+		option := &fs.HTTPOption{
+			Key:   strings.TrimSpace(parts[0]),
+			Value: strings.TrimSpace(parts[1]),
+		}
+		opts = append(opts, option)
+	}
+	return opts
 }
 
 // SetFlags converts any flags into config which weren't straight foward
@@ -108,8 +139,12 @@ func SetFlags() {
 		}
 	}
 
-	if noTraverse {
-		fs.Logf(nil, "--no-traverse is obsolete and no longer needed - please remove")
+	// --modify-window is explicit if it was set on the command line
+	// or its default was changed with RCLONE_MODIFY_WINDOW
+	if modifyWindowFlag := pflag.Lookup("modify-window"); modifyWindowFlag != nil {
+		if modifyWindowFlag.Changed || fs.Config.ModifyWindow != fs.NewConfig().ModifyWindow {
+			fs.Config.ModifyWindowSet = true
+		}
 	}
 
 	if dumpHeaders {
@@ -161,6 +196,13 @@ func SetFlags() {
 		fs.Config.DisableFeatures = strings.Split(disableFeatures, ",")
 	}
 
+	if len(uploadHeaders) != 0 {
+		fs.Config.UploadHeaders = parseHeaders("header-upload", uploadHeaders)
+	}
+	if len(downloadHeaders) != 0 {
+		fs.Config.DownloadHeaders = parseHeaders("header-download", downloadHeaders)
+	}
+
 	// Make the config file absolute
 	configPath, err := filepath.Abs(config.ConfigPath)
 	if err == nil {
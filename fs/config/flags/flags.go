@@ -200,3 +200,16 @@ func CountVarP(flags *pflag.FlagSet, p *int, name, shorthand string, usage strin
 	flags.CountVarP(p, name, shorthand, usage)
 	setDefaultFromEnv(name)
 }
+
+// ValuesAnnotation is the flag annotation holding the values a flag
+// can take.  It is used to complete the values in the shell.
+const ValuesAnnotation = "rclone_annotation_values"
+
+// SetValues records the values that the flag called name can take so
+// they can be offered by shell completion
+func SetValues(flags *pflag.FlagSet, name string, values ...string) {
+	err := flags.SetAnnotation(name, ValuesAnnotation, values)
+	if err != nil {
+		log.Fatalf("Couldn't set values for flag %q: %v", name, err)
+	}
+}
@@ -61,6 +61,10 @@ var (
 	ErrorDirectoryNotEmpty           = errors.New("directory not empty")
 	ErrorImmutableModified           = errors.New("immutable file modified")
 	ErrorPermissionDenied            = errors.New("permission denied")
+	ErrorNotImplemented              = errors.New("optional feature not implemented")
+	ErrorCantShareDirectories        = errors.New("this backend can't share directories with link")
+	ErrorCommandNotFound             = errors.New("command not found")
+	ErrorNameCollision               = errors.New("name collides with another entry")
 )
 
 // RegInfo provides information about a filesystem
@@ -77,6 +81,17 @@ type RegInfo struct {
 	Config func(string) `json:"-"`
 	// Options for the Fs configuration
 	Options []Option
+	// The backend commands this Fs supports, used for help
+	CommandHelp []CommandHelp
+}
+
+// CommandHelp describes a single backend command which can be run
+// with "rclone backend"
+type CommandHelp struct {
+	Name  string            // Name of the command, eg "link"
+	Short string            // Single line description
+	Long  string            // Long multi-line description
+	Opts  map[string]string // maps option name to a single line help
 }
 
 // Option is describes an option for the config wizard
@@ -246,6 +261,20 @@ type MimeTyper interface {
 	MimeType() string
 }
 
+// SetTierer is an optional interface for Object
+type SetTierer interface {
+	// SetTier changes the storage class or tier of the Object if
+	// the remote supports multiple storage classes
+	SetTier(tier string) error
+}
+
+// GetTierer is an optional interface for Object
+type GetTierer interface {
+	// GetTier returns the storage class or tier of the Object, or
+	// "" if it isn't known
+	GetTier() string
+}
+
 // ObjectUnWrapper is an optional interface for Object
 type ObjectUnWrapper interface {
 	// UnWrap returns the Object that this Object is wrapping or
@@ -290,6 +319,9 @@ type Features struct {
 	WriteMimeType           bool // can set the mime type of objects
 	CanHaveEmptyDirectories bool // can have empty directories
 	BucketBased             bool // is bucket based (like s3, swift etc)
+	SetTier                 bool // allows set tier functionality on objects
+	GetTier                 bool // allows to retrieve storage tier of objects
+	ServerSideAcrossConfigs bool // can server side copy between different remotes of the same type
 
 	// Purge all files in the root and the root directory
 	//
@@ -305,7 +337,8 @@ type Features struct {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// ServerSideAcrossConfigs is set and src is the same type of Fs
 	//
 	// If it isn't possible then return fs.ErrorCantCopy
 	Copy func(src Object, remote string) (Object, error)
@@ -316,7 +349,8 @@ type Features struct {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// ServerSideAcrossConfigs is set and src is the same type of Fs
 	//
 	// If it isn't possible then return fs.ErrorCantMove
 	Move func(src Object, remote string) (Object, error)
@@ -350,7 +384,17 @@ type Features struct {
 	DirCacheFlush func()
 
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
-	PublicLink func(remote string) (string, error)
+	//
+	// The link should stop working after expire if the remote
+	// supports it - DurationOff means no expiry
+	PublicLink func(remote string, expire Duration) (string, error)
+
+	// ListPublicLinks returns the public links to the remote path
+	ListPublicLinks func(remote string) ([]string, error)
+
+	// RemovePublicLinks removes all the public links to the remote
+	// path so it is no longer shared
+	RemovePublicLinks func(remote string) error
 
 	// Put in to the remote path with the modTime given of the given size
 	//
@@ -399,6 +443,21 @@ type Features struct {
 
 	// About gets quota information from the Fs
 	About func() (*Usage, error)
+
+	// Command the backend to run a named command
+	//
+	// The command run is name, args may be used to read
+	// arguments from and opt may be used to read optional
+	// arguments from.
+	//
+	// The result should be capable of being JSON encoded.  If it
+	// is a string or a []string it will be shown to the user,
+	// otherwise it will be JSON encoded and shown to the user
+	// like that.
+	//
+	// If the command isn't known then it should return
+	// ErrorCommandNotFound.
+	Command func(name string, arg []string, opt map[string]string) (interface{}, error)
 }
 
 // Disable nil's out the named feature.  If it isn't found then it
@@ -473,6 +532,10 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(PublicLinker); ok {
 		ft.PublicLink = do.PublicLink
 	}
+	if do, ok := f.(PublicLinkManager); ok {
+		ft.ListPublicLinks = do.ListPublicLinks
+		ft.RemovePublicLinks = do.RemovePublicLinks
+	}
 	if do, ok := f.(PutUncheckeder); ok {
 		ft.PutUnchecked = do.PutUnchecked
 	}
@@ -491,6 +554,9 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(Abouter); ok {
 		ft.About = do.About
 	}
+	if do, ok := f.(Commander); ok {
+		ft.Command = do.Command
+	}
 	return ft.DisableList(Config.DisableFeatures)
 }
 
@@ -508,6 +574,9 @@ func (ft *Features) Mask(f Fs) *Features {
 	ft.WriteMimeType = ft.WriteMimeType && mask.WriteMimeType
 	ft.CanHaveEmptyDirectories = ft.CanHaveEmptyDirectories && mask.CanHaveEmptyDirectories
 	ft.BucketBased = ft.BucketBased && mask.BucketBased
+	ft.SetTier = ft.SetTier && mask.SetTier
+	ft.GetTier = ft.GetTier && mask.GetTier
+	ft.ServerSideAcrossConfigs = ft.ServerSideAcrossConfigs && mask.ServerSideAcrossConfigs
 	if mask.Purge == nil {
 		ft.Purge = nil
 	}
@@ -547,6 +616,9 @@ func (ft *Features) Mask(f Fs) *Features {
 	if mask.About == nil {
 		ft.About = nil
 	}
+	if mask.Command == nil {
+		ft.Command = nil
+	}
 	return ft.DisableList(Config.DisableFeatures)
 }
 
@@ -593,7 +665,8 @@ type Copier interface {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// ServerSideAcrossConfigs is set and src is the same type of Fs
 	//
 	// If it isn't possible then return fs.ErrorCantCopy
 	Copy(src Object, remote string) (Object, error)
@@ -607,7 +680,8 @@ type Mover interface {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// ServerSideAcrossConfigs is set and src is the same type of Fs
 	//
 	// If it isn't possible then return fs.ErrorCantMove
 	Move(src Object, remote string) (Object, error)
@@ -681,7 +755,23 @@ type PutStreamer interface {
 // PublicLinker is an optional interface for Fs
 type PublicLinker interface {
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
-	PublicLink(remote string) (string, error)
+	//
+	// The link should stop working after expire if the remote
+	// supports it - DurationOff means no expiry
+	PublicLink(remote string, expire Duration) (string, error)
+}
+
+// PublicLinkManager is an optional interface for Fs which can also
+// list and remove the public links made with PublicLink
+type PublicLinkManager interface {
+	PublicLinker
+
+	// ListPublicLinks returns the public links to the remote path
+	ListPublicLinks(remote string) ([]string, error)
+
+	// RemovePublicLinks removes all the public links to the remote
+	// path so it is no longer shared
+	RemovePublicLinks(remote string) error
 }
 
 // MergeDirser is an option interface for Fs
@@ -740,6 +830,24 @@ type Abouter interface {
 	About() (*Usage, error)
 }
 
+// Commander is an optional interface for Fs
+type Commander interface {
+	// Command the backend to run a named command
+	//
+	// The command run is name, args may be used to read
+	// arguments from and opt may be used to read optional
+	// arguments from.
+	//
+	// The result should be capable of being JSON encoded.  If it
+	// is a string or a []string it will be shown to the user,
+	// otherwise it will be JSON encoded and shown to the user
+	// like that.
+	//
+	// If the command isn't known then it should return
+	// ErrorCommandNotFound.
+	Command(name string, arg []string, opt map[string]string) (interface{}, error)
+}
+
 // ObjectsChan is a channel of Objects
 type ObjectsChan chan Object
 
@@ -859,9 +967,19 @@ func FileExists(fs Fs, remote string) (bool, error) {
 // CalculateModifyWindow works out modify window for Fses passed in -
 // sets Config.ModifyWindow
 //
-// This is the largest modify window of all the fses in use, and the
-// user configured value
+// This is the largest modify window of all the fses in use, unless
+// the user set it explicitly with --modify-window in which case that
+// is used.
 func CalculateModifyWindow(fss ...Fs) {
+	var first Fs
+	if len(fss) > 0 {
+		first = fss[0]
+	}
+	if Config.ModifyWindowSet {
+		Infof(first, "Modify window is %s (set with --modify-window)", Config.ModifyWindow)
+		return
+	}
+	Config.ModifyWindow = time.Nanosecond
 	for _, f := range fss {
 		if f != nil {
 			precision := f.Precision()
@@ -874,5 +992,5 @@ func CalculateModifyWindow(fss ...Fs) {
 			}
 		}
 	}
-	Infof(fss[0], "Modify window is %s", Config.ModifyWindow)
+	Infof(first, "Modify window is %s", Config.ModifyWindow)
 }
@@ -3,6 +3,7 @@ package fs
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,3 +53,53 @@ func TestFeaturesDisableList(t *testing.T) {
 	assert.False(t, ft.CaseInsensitive)
 	assert.False(t, ft.DuplicateFiles)
 }
+
+// precisionFs is an Fs with just enough implemented to test
+// CalculateModifyWindow
+type precisionFs struct {
+	Fs
+	precision time.Duration
+}
+
+func (f precisionFs) Precision() time.Duration { return f.precision }
+func (f precisionFs) String() string           { return "precisionFs" }
+
+func TestCalculateModifyWindow(t *testing.T) {
+	oldModifyWindow, oldModifyWindowSet := Config.ModifyWindow, Config.ModifyWindowSet
+	defer func() {
+		Config.ModifyWindow, Config.ModifyWindowSet = oldModifyWindow, oldModifyWindowSet
+	}()
+	var (
+		local = precisionFs{precision: time.Nanosecond}
+		sftp  = precisionFs{precision: time.Second}
+		fat   = precisionFs{precision: 2 * time.Second}
+		none  = precisionFs{precision: ModTimeNotSupported}
+	)
+
+	Config.ModifyWindowSet = false
+	for _, test := range []struct {
+		fss  []Fs
+		want time.Duration
+	}{
+		{[]Fs{local}, time.Nanosecond},
+		{[]Fs{local, sftp}, time.Second},
+		{[]Fs{fat, local}, 2 * time.Second},
+		{[]Fs{local, nil}, time.Nanosecond},
+		{[]Fs{local, none, fat}, ModTimeNotSupported},
+	} {
+		Config.ModifyWindow = time.Hour // check it isn't accumulated
+		CalculateModifyWindow(test.fss...)
+		assert.Equal(t, test.want, Config.ModifyWindow)
+	}
+
+	Config.ModifyWindowSet = true
+	Config.ModifyWindow = time.Millisecond
+	CalculateModifyWindow(local, sftp)
+	assert.Equal(t, time.Millisecond, Config.ModifyWindow)
+
+	// Check it doesn't panic with no Fs
+	CalculateModifyWindow()
+	Config.ModifyWindowSet = false
+	CalculateModifyWindow()
+	assert.Equal(t, time.Nanosecond, Config.ModifyWindow)
+}
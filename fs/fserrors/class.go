@@ -0,0 +1,144 @@
+package fserrors
+
+import (
+	"os"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// Class is the broad kind of an error
+//
+// These are used to give different exit codes and to count the
+// errors in the stats so that scripts can react differently to, say,
+// a full quota and a transient network error.
+type Class int
+
+// Error classes
+const (
+	ClassOther       Class = iota // not otherwise classified
+	ClassNotFound                 // file or directory not found
+	ClassPermission               // permission denied
+	ClassQuota                    // quota exceeded or out of space
+	ClassRateLimited              // rate limited by the remote
+	ClassNetwork                  // networking error
+	ClassChecksum                 // checksum or size mismatch after a transfer
+)
+
+// Classes is all the Class values in order
+var Classes = []Class{ClassOther, ClassNotFound, ClassPermission, ClassQuota, ClassRateLimited, ClassNetwork, ClassChecksum}
+
+var classNames = map[Class]string{
+	ClassOther:       "other",
+	ClassNotFound:    "not found",
+	ClassPermission:  "permission denied",
+	ClassQuota:       "quota exceeded",
+	ClassRateLimited: "rate limited",
+	ClassNetwork:     "network",
+	ClassChecksum:    "checksum mismatch",
+}
+
+// String turns a Class into a string
+func (c Class) String() string {
+	if name, ok := classNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Classer is an optional interface for error as to which Class it
+// is in.
+type Classer interface {
+	error
+	ErrorClass() Class
+}
+
+// wrappedClassError is an error wrapped so it will satisfy the
+// Classer interface
+type wrappedClassError struct {
+	error
+	class Class
+}
+
+// ErrorClass interface
+func (err wrappedClassError) ErrorClass() Class {
+	return err.class
+}
+
+// Cause returns the underlying error so errors.Cause can see through
+// the classification
+func (err wrappedClassError) Cause() error {
+	return err.error
+}
+
+// Check interface
+var _ Classer = wrappedClassError{(error)(nil), ClassOther}
+
+// ClassError makes an error which is classified as class
+func ClassError(err error, class Class) error {
+	if err == nil {
+		return nil
+	}
+	return wrappedClassError{error: err, class: class}
+}
+
+// Errors which indicate the quota is full or the disk is out of space
+//
+// These are added to in retriable_errors*.go
+var quotaErrors []error
+
+// Classify works out which Class err is in
+//
+// Errors made with ClassError or RateLimitError are classified as
+// asked.  Otherwise err is classified by looking at its cause.
+func Classify(err error) Class {
+	retriable := false
+	for err != nil {
+		if c, ok := err.(Classer); ok {
+			return c.ErrorClass()
+		}
+		if r, ok := err.(RateLimiter); ok && r.RateLimited() {
+			return ClassRateLimited
+		}
+		// Look inside the errors from this package
+		switch x := err.(type) {
+		case wrappedRetryError:
+			err = x.error
+			continue
+		case wrappedNoRetryError:
+			err = x.error
+			continue
+		case wrappedFatalError:
+			err = x.error
+			continue
+		case wrappedRetryAfterError:
+			err = x.error
+			continue
+		}
+		// Unwrap one level at a time so a Classer further down
+		// the chain isn't skipped
+		causeRetriable, _ := Cause(err)
+		retriable = retriable || causeRetriable
+		next := unwrap(err)
+		if next == nil || next == err {
+			break
+		}
+		err = next
+	}
+	if err == nil {
+		return ClassOther
+	}
+	for _, quotaErr := range quotaErrors {
+		if err == quotaErr {
+			return ClassQuota
+		}
+	}
+	switch {
+	case err == fs.ErrorObjectNotFound || err == fs.ErrorDirNotFound || os.IsNotExist(err):
+		return ClassNotFound
+	case err == fs.ErrorPermissionDenied || os.IsPermission(err):
+		return ClassPermission
+	case retriable || ShouldRetry(err):
+		return ClassNetwork
+	}
+	return ClassOther
+}
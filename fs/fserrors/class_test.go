@@ -0,0 +1,59 @@
+package fserrors
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassString(t *testing.T) {
+	assert.Equal(t, "other", ClassOther.String())
+	assert.Equal(t, "quota exceeded", ClassQuota.String())
+	assert.Equal(t, "unknown", Class(-1).String())
+	for _, class := range Classes {
+		assert.NotEqual(t, "unknown", class.String())
+	}
+}
+
+func TestClassify(t *testing.T) {
+	potato := errors.New("potato")
+	for i, test := range []struct {
+		err  error
+		want Class
+	}{
+		{nil, ClassOther},
+		{potato, ClassOther},
+		{ClassError(potato, ClassQuota), ClassQuota},
+		{errors.Wrap(ClassError(potato, ClassPermission), "wrapped"), ClassPermission},
+		{RateLimitError(potato), ClassRateLimited},
+		{RetryError(RateLimitError(potato)), ClassRateLimited},
+		{FatalError(ClassError(potato, ClassChecksum)), ClassChecksum},
+		{NoRetryError(errors.Wrap(fs.ErrorObjectNotFound, "wrapped")), ClassNotFound},
+		{fs.ErrorDirNotFound, ClassNotFound},
+		{fs.ErrorPermissionDenied, ClassPermission},
+		{&os.PathError{Op: "open", Path: "/potato", Err: syscall.ENOENT}, ClassNotFound},
+		{&os.PathError{Op: "open", Path: "/potato", Err: syscall.EACCES}, ClassPermission},
+		{&os.PathError{Op: "write", Path: "/potato", Err: syscall.ENOSPC}, ClassQuota},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, ClassNetwork},
+		{errors.Wrap(RetryAfterError(syscall.ECONNREFUSED, 0), "wrapped"), ClassNetwork},
+	} {
+		assert.Equal(t, test.want, Classify(test.err), fmt.Sprintf("test #%d: %v", i, test.err))
+	}
+	assert.Nil(t, ClassError(nil, ClassQuota))
+}
+
+func TestClassErrorCause(t *testing.T) {
+	potato := errors.New("potato")
+	err := errors.Wrap(ClassError(potato, ClassQuota), "wrapped")
+	assert.Equal(t, potato, errors.Cause(err))
+	_, cause := Cause(err)
+	assert.Equal(t, potato, cause)
+	assert.False(t, IsFatalError(err))
+	assert.True(t, IsRetryError(ClassError(RetryError(potato), ClassNetwork)))
+}
@@ -6,7 +6,9 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -69,9 +71,10 @@ func IsRetryError(err error) bool {
 	if err == nil {
 		return false
 	}
-	_, err = Cause(err)
-	if r, ok := err.(Retrier); ok {
-		return r.Retry()
+	for ; err != nil; err = unwrap(err) {
+		if r, ok := err.(Retrier); ok {
+			return r.Retry()
+		}
 	}
 	return false
 }
@@ -114,9 +117,10 @@ func IsFatalError(err error) bool {
 	if err == nil {
 		return false
 	}
-	_, err = Cause(err)
-	if r, ok := err.(Fataler); ok {
-		return r.Fatal()
+	for ; err != nil; err = unwrap(err) {
+		if r, ok := err.(Fataler); ok {
+			return r.Fatal()
+		}
 	}
 	return false
 }
@@ -159,9 +163,121 @@ func IsNoRetryError(err error) bool {
 	if err == nil {
 		return false
 	}
-	_, err = Cause(err)
-	if r, ok := err.(NoRetrier); ok {
-		return r.NoRetry()
+	for ; err != nil; err = unwrap(err) {
+		if r, ok := err.(NoRetrier); ok {
+			return r.NoRetry()
+		}
+	}
+	return false
+}
+
+// RetryAfter is an optional interface for error as to the time the
+// operation should be retried at.
+//
+// The pacer waits until this time before retrying the operation.
+type RetryAfter interface {
+	error
+	RetryAfter() time.Time
+}
+
+// wrappedRetryAfterError is an error wrapped so it will satisfy the
+// RetryAfter interface
+type wrappedRetryAfterError struct {
+	error
+	retryAfter time.Time
+}
+
+// RetryAfter interface
+func (err wrappedRetryAfterError) RetryAfter() time.Time {
+	return err.retryAfter
+}
+
+// Cause returns the wrapped error so errors.Cause can see it
+func (err wrappedRetryAfterError) Cause() error {
+	return err.error
+}
+
+// Check interface
+var _ RetryAfter = wrappedRetryAfterError{(error)(nil), time.Time{}}
+
+// RetryAfterError makes an error which indicates the operation
+// shouldn't be retried until d has passed.
+func RetryAfterError(err error, d time.Duration) error {
+	if err == nil {
+		err = errors.New("needs retry")
+	}
+	return wrappedRetryAfterError{error: err, retryAfter: time.Now().Add(d)}
+}
+
+// RetryAfterErrorTime returns the time that err says the operation
+// should be retried at, or the zero time if it doesn't conform to the
+// RetryAfter interface.
+func RetryAfterErrorTime(err error) time.Time {
+	if err == nil {
+		return time.Time{}
+	}
+	for ; err != nil; err = unwrap(err) {
+		if r, ok := err.(RetryAfter); ok {
+			return r.RetryAfter()
+		}
+	}
+	return time.Time{}
+}
+
+// RateLimiter is an optional interface for error as to whether the
+// operation failed because the remote is rate limiting us.
+//
+// The pacer slows down all the calls to the remote when it sees one
+// of these.
+type RateLimiter interface {
+	error
+	RateLimited() bool
+}
+
+// wrappedRateLimitError is an error wrapped so it will satisfy the
+// RateLimiter interface and return true
+type wrappedRateLimitError struct {
+	error
+}
+
+// RateLimited interface
+func (err wrappedRateLimitError) RateLimited() bool {
+	return true
+}
+
+// RetryAfter interface - this passes on the time of any RetryAfter
+// error that was wrapped
+func (err wrappedRateLimitError) RetryAfter() time.Time {
+	return RetryAfterErrorTime(err.error)
+}
+
+// Check interface
+var (
+	_ RateLimiter = wrappedRateLimitError{(error)(nil)}
+	_ RetryAfter  = wrappedRateLimitError{(error)(nil)}
+)
+
+// RateLimitError makes an error which indicates the operation failed
+// because the remote is rate limiting us.
+//
+// If err is a RetryAfter error then the returned error is too.
+func RateLimitError(err error) error {
+	if err == nil {
+		err = errors.New("rate limited")
+	}
+	return wrappedRateLimitError{err}
+}
+
+// IsRateLimitError returns true if err conforms to the RateLimiter
+// interface and calling the RateLimited method returns true.
+func IsRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for ; err != nil; err = unwrap(err) {
+		if r, ok := err.(RateLimiter); ok {
+			return r.RateLimited()
+		}
 	}
 	return false
 }
@@ -171,7 +287,7 @@ func IsNoRetryError(err error) bool {
 // errors had a Timeout() or Temporary() method which returned true.
 func Cause(cause error) (retriable bool, err error) {
 	err = cause
-	for prev := err; err != nil; prev = err {
+	for err != nil {
 		// Check for net error Timeout()
 		if x, ok := err.(interface {
 			Timeout() bool
@@ -187,35 +303,46 @@ func Cause(cause error) (retriable bool, err error) {
 		}
 
 		// Unwrap 1 level if possible
-		err = errors.Cause(err)
-		if err == prev {
-			// Unpack any struct or *struct with a field
-			// of name Err which satisfies the error
-			// interface.  This includes *url.Error,
-			// *net.OpError, *os.SyscallError and many
-			// others in the stdlib
-			errType := reflect.TypeOf(err)
-			errValue := reflect.ValueOf(err)
-			if errType.Kind() == reflect.Ptr {
-				errType = errType.Elem()
-				errValue = errValue.Elem()
-			}
-			if errType.Kind() == reflect.Struct {
-				if errField := errValue.FieldByName("Err"); errField.IsValid() {
-					errFieldValue := errField.Interface()
-					if newErr, ok := errFieldValue.(error); ok {
-						err = newErr
-					}
-				}
-			}
-		}
-		if err == prev {
+		next := unwrap(err)
+		if next == nil || next == err {
 			break
 		}
+		err = next
 	}
 	return retriable, err
 }
 
+// unwrap returns the error err wraps or nil if it doesn't wrap one
+//
+// This unwraps errors with a Cause method, as made by errors.Wrap,
+// and any struct or *struct with a field of name Err which satisfies
+// the error interface.  This includes *url.Error, *net.OpError,
+// *os.SyscallError and many others in the stdlib.
+func unwrap(err error) error {
+	if x, ok := err.(interface {
+		Cause() error
+	}); ok {
+		return x.Cause()
+	}
+	errType := reflect.TypeOf(err)
+	errValue := reflect.ValueOf(err)
+	if errType.Kind() == reflect.Ptr {
+		if errValue.IsNil() {
+			return nil
+		}
+		errType = errType.Elem()
+		errValue = errValue.Elem()
+	}
+	if errType.Kind() == reflect.Struct {
+		if errField := errValue.FieldByName("Err"); errField.IsValid() {
+			if newErr, ok := errField.Interface().(error); ok {
+				return newErr
+			}
+		}
+	}
+	return nil
+}
+
 // retriableErrorStrings is a list of phrases which when we find it
 // in an an error, we know it is a networking error which should be
 // retried.
@@ -284,3 +411,61 @@ func ShouldRetryHTTP(resp *http.Response, retryErrorCodes []int) bool {
 	}
 	return false
 }
+
+// retryAfterHeader is the HTTP header used to say when to retry
+const retryAfterHeader = "Retry-After"
+
+// parseRetryAfter parses the value of a Retry-After header which is
+// either a number of seconds or an HTTP date.
+//
+// It returns false if it couldn't be parsed.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// RetryAfterHTTP returns retry and err, wrapping err so that the
+// pacer waits for the time given in the Retry-After header of resp
+// before retrying.
+//
+// err is only wrapped if retry is set and resp has a valid
+// Retry-After header, so it is safe to use in shouldRetry functions
+// like this
+//
+//     return fserrors.RetryAfterHTTP(fserrors.ShouldRetryHTTP(resp, retryErrorCodes), resp, err)
+func RetryAfterHTTP(retry bool, resp *http.Response, err error) (bool, error) {
+	if resp == nil {
+		return retry, err
+	}
+	return RetryAfterHeader(retry, resp.Header, err)
+}
+
+// RetryAfterHeader is like RetryAfterHTTP but for when only the
+// headers of the response are available, eg from an SDK error.
+func RetryAfterHeader(retry bool, header http.Header, err error) (bool, error) {
+	if !retry || header == nil {
+		return retry, err
+	}
+	d, ok := parseRetryAfter(header.Get(retryAfterHeader), time.Now())
+	if !ok {
+		return retry, err
+	}
+	return retry, RetryAfterError(err, d)
+}
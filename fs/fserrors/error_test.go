@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -118,3 +120,94 @@ func TestShouldRetry(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("test #%d: %v", i, test.err))
 	}
 }
+
+func TestRetryAfterError(t *testing.T) {
+	assert.True(t, RetryAfterErrorTime(nil).IsZero())
+	assert.True(t, RetryAfterErrorTime(errors.New("potato")).IsZero())
+
+	start := time.Now()
+	err := RetryAfterError(errors.New("potato"), 5*time.Second)
+	assert.Equal(t, "potato", err.Error())
+	retryAfter := RetryAfterErrorTime(err)
+	assert.True(t, retryAfter.After(start.Add(4*time.Second)))
+
+	wrapped := errors.Wrap(err, "wrapped")
+	assert.Equal(t, retryAfter, RetryAfterErrorTime(wrapped))
+
+	// The original error can be got at
+	origErr := errors.New("potato")
+	err = RetryAfterError(origErr, 5*time.Second)
+	assert.Equal(t, origErr, errors.Cause(err))
+	_, cause := Cause(errors.Wrap(err, "wrapped"))
+	assert.Equal(t, origErr, cause)
+	assert.Equal(t, ClassOther, Classify(err))
+}
+
+func TestRateLimitError(t *testing.T) {
+	assert.False(t, IsRateLimitError(nil))
+	assert.False(t, IsRateLimitError(errors.New("potato")))
+
+	err := RateLimitError(errors.New("potato"))
+	assert.Equal(t, "potato", err.Error())
+	assert.True(t, IsRateLimitError(err))
+	assert.True(t, IsRateLimitError(errors.Wrap(err, "wrapped")))
+	assert.True(t, RetryAfterErrorTime(err).IsZero())
+
+	// RetryAfter is passed through
+	retryAfterErr := RetryAfterError(errors.New("potato"), 5*time.Second)
+	err = RateLimitError(retryAfterErr)
+	assert.True(t, IsRateLimitError(err))
+	assert.Equal(t, RetryAfterErrorTime(retryAfterErr), RetryAfterErrorTime(err))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"potato", 0, false},
+		{"-1", 0, false},
+		{"0", 0, true},
+		{"120", 120 * time.Second, true},
+		{" 7 ", 7 * time.Second, true},
+		{"Fri, 01 Jun 2018 12:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 01 Jun 2018 11:00:00 GMT", 0, true},
+	} {
+		got, gotOK := parseRetryAfter(test.in, now)
+		assert.Equal(t, test.wantOK, gotOK, test.in)
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
+
+func TestRetryAfterHTTP(t *testing.T) {
+	resp := &http.Response{StatusCode: 429, Header: http.Header{}}
+	origErr := errors.New("potato")
+
+	retry, err := RetryAfterHTTP(true, resp, origErr)
+	assert.True(t, retry)
+	assert.Equal(t, origErr, err)
+
+	resp.Header.Set("Retry-After", "10")
+	retry, err = RetryAfterHTTP(false, resp, origErr)
+	assert.False(t, retry)
+	assert.Equal(t, origErr, err)
+
+	retry, err = RetryAfterHTTP(true, resp, origErr)
+	assert.True(t, retry)
+	assert.False(t, RetryAfterErrorTime(err).IsZero())
+
+	retry, err = RetryAfterHTTP(true, nil, origErr)
+	assert.True(t, retry)
+	assert.Equal(t, origErr, err)
+
+	retry, err = RetryAfterHeader(true, http.Header{"Retry-After": {"10"}}, origErr)
+	assert.True(t, retry)
+	assert.False(t, RetryAfterErrorTime(err).IsZero())
+
+	retry, err = RetryAfterHeader(true, nil, origErr)
+	assert.True(t, retry)
+	assert.Equal(t, origErr, err)
+}
@@ -18,4 +18,8 @@ func init() {
 		syscall.EWOULDBLOCK,
 		syscall.ECONNRESET,
 	)
+	quotaErrors = append(quotaErrors,
+		syscall.ENOSPC,
+		syscall.EDQUOT,
+	)
 }
@@ -12,6 +12,9 @@ const (
 	WSATRY_AGAIN      syscall.Errno = 11002
 	WSAENETRESET      syscall.Errno = 10052
 	WSAETIMEDOUT      syscall.Errno = 10060
+
+	ERROR_HANDLE_DISK_FULL syscall.Errno = 39
+	ERROR_DISK_FULL        syscall.Errno = 112
 )
 
 func init() {
@@ -28,4 +31,8 @@ func init() {
 		syscall.ERROR_NETNAME_DELETED,
 		syscall.ERROR_BROKEN_PIPE,
 	)
+	quotaErrors = append(quotaErrors,
+		ERROR_HANDLE_DISK_FULL,
+		ERROR_DISK_FULL,
+	)
 }
@@ -0,0 +1,100 @@
+// Package hashdb keeps a database of the hashes of the files rclone
+// has transferred so they can be checked for corruption later.
+package hashdb
+
+import (
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/lib/atexit"
+)
+
+// Entry is what is stored for each object in the database
+type Entry struct {
+	Size     int64             `json:"size"`
+	ModTime  time.Time         `json:"modTime"`
+	Hashes   map[string]string `json:"hashes"`
+	Recorded time.Time         `json:"recorded"`
+}
+
+// Hash returns the recorded hash of type ht or "" if there isn't one
+func (e *Entry) Hash(ht hash.Type) string {
+	return e.Hashes[ht.String()]
+}
+
+// key returns the bucket and key to store o under
+//
+// The bucket is the name of the remote and the key the path of the
+// object within it.
+func key(o fs.ObjectInfo) (bucket, key []byte) {
+	f := o.Fs()
+	return []byte(f.Name()), []byte(path.Join(f.Root(), o.Remote()))
+}
+
+var (
+	defaultMu         sync.Mutex
+	defaultDB         *DB
+	defaultErr        error
+	defaultRegistered bool
+)
+
+// Path returns the path of the database used by --hash-db
+func Path() string {
+	return filepath.Join(config.CacheDir, "hashdb.bolt")
+}
+
+// Default returns the database used by --hash-db, opening it the
+// first time it is called.
+func Default() (*DB, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultDB == nil && defaultErr == nil {
+		defaultDB, defaultErr = Open(Path())
+		if defaultErr != nil {
+			fs.Errorf(nil, "--hash-db: %v", defaultErr)
+		} else if !defaultRegistered {
+			defaultRegistered = true
+			atexit.Register(func() {
+				_ = CloseDefault()
+			})
+		}
+	}
+	return defaultDB, defaultErr
+}
+
+// CloseDefault closes the database used by --hash-db if it is open
+//
+// The next call to Default opens it again, at Path as it is then.
+func CloseDefault() (err error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultDB != nil {
+		err = defaultDB.Close()
+	}
+	defaultDB, defaultErr = nil, nil
+	return err
+}
+
+// Record stores the hash sum of type ht for o in the --hash-db
+// database if it is in use.
+//
+// If the database can't be opened an error is logged once and
+// nothing is recorded.
+func Record(ht hash.Type, sum string, o fs.ObjectInfo) {
+	if !fs.Config.HashDB || ht == hash.None || sum == "" {
+		return
+	}
+	db, err := Default()
+	if err != nil {
+		return
+	}
+	err = db.Put(o, map[hash.Type]string{ht: sum})
+	if err != nil {
+		fs.Errorf(o, "Failed to record hash in --hash-db: %v", err)
+	}
+}
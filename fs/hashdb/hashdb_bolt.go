@@ -0,0 +1,128 @@
+// +build !plan9
+
+package hashdb
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/pkg/errors"
+)
+
+// openTimeout is how long to wait for another rclone to finish with
+// the database.  The database is only locked for the length of each
+// transaction so this should be plenty.
+const openTimeout = 30 * time.Second
+
+// DB is a database of hashes
+//
+// The bolt database is opened for each transaction rather than being
+// held open, as bolt locks the file while it is open, so that several
+// rclones can use the same database at once.
+type DB struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Open the database at dbPath making it if necessary
+func Open(dbPath string) (*DB, error) {
+	err := os.MkdirAll(filepath.Dir(dbPath), 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make hash database directory")
+	}
+	db := &DB{path: dbPath}
+	// Check the database can be opened
+	err = db.view(func(tx *bolt.Tx) error { return nil })
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Close the database
+func (db *DB) Close() error {
+	return nil
+}
+
+// with opens the bolt database and calls fn with it
+func (db *DB) with(fn func(*bolt.DB) error) (err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	bdb, err := bolt.Open(db.path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return errors.Wrapf(err, "failed to open hash database %q", db.path)
+	}
+	defer func() {
+		closeErr := bdb.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	return fn(bdb)
+}
+
+// update runs fn in a read-write transaction
+func (db *DB) update(fn func(*bolt.Tx) error) error {
+	return db.with(func(bdb *bolt.DB) error {
+		return bdb.Update(fn)
+	})
+}
+
+// view runs fn in a read only transaction
+func (db *DB) view(fn func(*bolt.Tx) error) error {
+	return db.with(func(bdb *bolt.DB) error {
+		return bdb.View(fn)
+	})
+}
+
+// Put records the hashes of o
+func (db *DB) Put(o fs.ObjectInfo, hashes map[hash.Type]string) error {
+	entry := Entry{
+		Size:     o.Size(),
+		ModTime:  o.ModTime(),
+		Hashes:   make(map[string]string, len(hashes)),
+		Recorded: time.Now(),
+	}
+	for ht, sum := range hashes {
+		entry.Hashes[ht.String()] = sum
+	}
+	value, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+	bucketName, keyName := key(o)
+	return db.update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(keyName, value)
+	})
+}
+
+// Get returns the Entry for o or nil if there isn't one
+func (db *DB) Get(o fs.ObjectInfo) (entry *Entry, err error) {
+	bucketName, keyName := key(o)
+	err = db.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if bucket == nil {
+			return nil
+		}
+		value := bucket.Get(keyName)
+		if value == nil {
+			return nil
+		}
+		entry = new(Entry)
+		return json.Unmarshal(value, entry)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %v from hash database", o)
+	}
+	return entry, nil
+}
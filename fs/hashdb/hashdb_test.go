@@ -0,0 +1,102 @@
+// +build !plan9
+
+package hashdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testInfo is a minimal fs.Info to make the keys from
+type testInfo struct {
+	name, root string
+}
+
+func (i testInfo) Name() string             { return i.name }
+func (i testInfo) Root() string             { return i.root }
+func (i testInfo) String() string           { return i.name + ":" + i.root }
+func (i testInfo) Precision() time.Duration { return time.Second }
+func (i testInfo) Hashes() hash.Set         { return hash.Set(hash.MD5) }
+func (i testInfo) Features() *fs.Features   { return &fs.Features{} }
+
+func TestPutGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-hashdb-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	db, err := Open(filepath.Join(dir, "hashdb.bolt"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	modTime := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	o := object.NewStaticObjectInfo("file.txt", modTime, 42, true, nil, testInfo{"remote", "path"})
+
+	// Not there yet
+	entry, err := db.Get(o)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	require.NoError(t, db.Put(o, map[hash.Type]string{hash.MD5: "0123456789abcdef"}))
+	entry, err = db.Get(o)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, int64(42), entry.Size)
+	assert.True(t, modTime.Equal(entry.ModTime))
+	assert.Equal(t, "0123456789abcdef", entry.Hash(hash.MD5))
+	assert.Equal(t, "", entry.Hash(hash.SHA1))
+	assert.False(t, entry.Recorded.IsZero())
+
+	// Same path on a different remote isn't found
+	o2 := object.NewStaticObjectInfo("file.txt", modTime, 42, true, nil, testInfo{"other", "path"})
+	entry, err = db.Get(o2)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// Same path reached from a different root is found
+	o3 := object.NewStaticObjectInfo("path/file.txt", modTime, 42, true, nil, testInfo{"remote", ""})
+	entry, err = db.Get(o3)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "0123456789abcdef", entry.Hash(hash.MD5))
+}
+
+func TestConcurrentDBs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-hashdb-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	dbPath := filepath.Join(dir, "hashdb.bolt")
+
+	// Two users of the same database don't lock each other out
+	db1, err := Open(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db1.Close())
+	}()
+	db2, err := Open(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db2.Close())
+	}()
+
+	o := object.NewStaticObjectInfo("file.txt", time.Now(), 42, true, nil, testInfo{"remote", "path"})
+	require.NoError(t, db1.Put(o, map[hash.Type]string{hash.MD5: "0123456789abcdef"}))
+	entry, err := db2.Get(o)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "0123456789abcdef", entry.Hash(hash.MD5))
+}
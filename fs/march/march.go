@@ -10,8 +10,10 @@ import (
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/list"
 	"github.com/ncw/rclone/fs/walk"
+	"github.com/pkg/errors"
 	"golang.org/x/text/unicode/norm"
 )
 
@@ -24,6 +26,12 @@ type March struct {
 	fsrc     fs.Fs
 	dir      string
 	callback Marcher
+	// NoTraverse stops the destination being listed.  Instead each
+	// source file is looked up in the destination individually.
+	NoTraverse bool
+	// NoCheckDest stops the destination being looked at at all so
+	// every source file is reported with SrcOnly.
+	NoCheckDest bool
 	// internal state
 	srcListDir listDirFn // function to call to list a directory in the src
 	dstListDir listDirFn // function to call to list a directory in the dst
@@ -285,7 +293,7 @@ func matchListings(srcListEntries, dstListEntries fs.DirEntries, transforms []ma
 		if src != nil && iSrc > 0 {
 			prev := srcList[iSrc-1].name
 			if srcName == prev {
-				fs.Logf(src, "Duplicate %s found in source - ignoring", fs.DirEntryType(src))
+				reportCollision(src, srcList[iSrc-1].entry)
 				iDst-- // ignore the src and retry the dst
 				continue
 			} else if srcName < prev {
@@ -328,6 +336,69 @@ func matchListings(srcListEntries, dstListEntries fs.DirEntries, transforms []ma
 	return
 }
 
+// reportCollision reports that src can't be transferred because its
+// name collides with prev which is being transferred instead.
+//
+// This happens with duplicate names in the source (eg on Google
+// Drive), with names which are the same once unicode normalized (eg
+// "é" as one code point and as "e" plus a combining accent) or with
+// names differing only in case when the destination is case
+// insensitive (eg "Foo" and "foo").  The error is counted but not
+// retried, since a retry won't fix it.
+func reportCollision(src, prev fs.DirEntry) {
+	what := "case insensitive name"
+	switch {
+	case src.Remote() == prev.Remote():
+		what = "duplicate name"
+	case norm.NFC.String(src.Remote()) == norm.NFC.String(prev.Remote()):
+		what = "unicode normalized name"
+	}
+	err := errors.Wrapf(fs.ErrorNameCollision, "%s %q", what, prev.Remote())
+	fs.Errorf(src, "Not transferring %s: %v", fs.DirEntryType(src), err)
+	fs.CountError(fserrors.NoRetryError(err))
+}
+
+// lookupDst finds the objects in the destination which correspond
+// to the objects in srcList by calling NewObject on each one.
+//
+// Objects which don't exist in the destination are left out and
+// directories are ignored.
+func (m *March) lookupDst(srcList fs.DirEntries) (dstList fs.DirEntries) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		tokens = make(chan struct{}, fs.Config.Checkers)
+	)
+	for _, entry := range srcList {
+		src, ok := entry.(fs.Object)
+		if !ok {
+			continue
+		}
+		if m.aborting() {
+			break
+		}
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-tokens }()
+			dst, err := m.fdst.NewObject(src.Remote())
+			if err == fs.ErrorObjectNotFound {
+				return
+			} else if err != nil {
+				fs.Errorf(src, "error reading destination object: %v", err)
+				fs.CountError(err)
+				return
+			}
+			mu.Lock()
+			dstList = append(dstList, dst)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return dstList
+}
+
 // processJob processes a listDirJob listing the source and
 // destination directories, comparing them and returning a slice of
 // more jobs
@@ -348,7 +419,7 @@ func (m *March) processJob(job listDirJob) (jobs []listDirJob) {
 			srcList, srcListErr = m.srcListDir(job.srcRemote)
 		}()
 	}
-	if !job.noDst {
+	if !job.noDst && !m.NoTraverse && !m.NoCheckDest {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -371,6 +442,11 @@ func (m *March) processJob(job listDirJob) (jobs []listDirJob) {
 		return nil
 	}
 
+	// Look up the source files in the destination if not listing it
+	if m.NoTraverse && !m.NoCheckDest {
+		dstList = m.lookupDst(srcList)
+	}
+
 	// Work out what to do and do it
 	srcOnly, dstOnly, matches := matchListings(srcList, dstList, m.transforms)
 	for _, src := range srcOnly {
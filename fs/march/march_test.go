@@ -7,8 +7,10 @@ import (
 	"testing"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/unicode/norm"
 )
 
 func TestNewMatchEntries(t *testing.T) {
@@ -169,3 +171,38 @@ func TestMatchListings(t *testing.T) {
 		assert.Equal(t, test.matches, matches, test.what)
 	}
 }
+
+func TestReportCollision(t *testing.T) {
+	var errs []error
+	oldCountError := fs.CountError
+	fs.CountError = func(err error) { errs = append(errs, err) }
+	defer func() { fs.CountError = oldCountError }()
+
+	var (
+		a = mockobject.Object("a")
+		A = mockobject.Object("A")
+	)
+	srcOnly, _, _ := matchListings(fs.DirEntries{a, A}, nil, []matchTransformFn{strings.ToLower})
+	assert.Equal(t, fs.DirEntries{A}, srcOnly)
+	assert.Len(t, errs, 1)
+	assert.True(t, fserrors.IsNoRetryError(errs[0]))
+	assert.Contains(t, errs[0].Error(), `case insensitive name "A"`)
+	assert.Contains(t, errs[0].Error(), fs.ErrorNameCollision.Error())
+
+	errs = nil
+	reportCollision(a, a)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), `duplicate name "a"`)
+
+	// "é" as NFC and NFD
+	var (
+		nfc = mockobject.Object("\u00e9")
+		nfd = mockobject.Object("e\u0301")
+	)
+	errs = nil
+	srcOnly, _, _ = matchListings(fs.DirEntries{nfc, nfd}, nil, []matchTransformFn{norm.NFC.String})
+	assert.Len(t, srcOnly, 1)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "unicode normalized name")
+	assert.NotContains(t, errs[0].Error(), "case insensitive")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"path"
 	"sort"
 	"strconv"
@@ -17,11 +18,14 @@ import (
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/hashdb"
 	"github.com/ncw/rclone/fs/march"
 	"github.com/ncw/rclone/fs/object"
 	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/atexit"
 	"github.com/ncw/rclone/lib/readers"
 	"github.com/pkg/errors"
 )
@@ -159,9 +163,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 
 	// mod time differs but hash is the same to reset mod time if required
 	if !fs.Config.NoUpdateModTime {
-		if fs.Config.DryRun {
-			fs.Logf(src, "Not updating modification time as --dry-run")
-		} else {
+		if !SkipDestructive(src, "update modification time") {
 			// Size and hash the same but mtime different
 			// Error if objects are treated as immutable
 			if fs.Config.Immutable {
@@ -234,17 +236,89 @@ func (o *overrideRemoteObject) MimeType() string {
 // Check interface is satisfied
 var _ fs.MimeTyper = (*overrideRemoteObject)(nil)
 
+// addHeaderOptions appends the headers set with --header-upload or
+// --header-download to options
+func addHeaderOptions(options []fs.OpenOption, headers []*fs.HTTPOption) []fs.OpenOption {
+	for _, header := range headers {
+		options = append(options, header)
+	}
+	return options
+}
+
+// SkipDestructive should be called before doing anything which
+// changes a remote.  It returns true if the operation should be
+// skipped, logging why.
+//
+// It checks --dry-run, and if --interactive is set it asks the user
+// whether to go ahead.  subject is the object or directory being
+// changed and action a short phrase describing what is to be done -
+// they should make sense as "action subject?", eg "delete file.txt?".
+func SkipDestructive(subject interface{}, action string) (skip bool) {
+	var flag string
+	switch {
+	case fs.Config.DryRun:
+		flag = "--dry-run"
+		skip = true
+	case fs.Config.Interactive:
+		flag = "--interactive"
+		skip = !confirmInteractive(subject, action)
+	default:
+		return false
+	}
+	if skip {
+		fs.Logf(subject, "Skipped %s as %s is set", action, flag)
+	}
+	return skip
+}
+
+var (
+	interactiveMu   sync.Mutex          // only ask one question at once
+	interactiveSkip = map[string]bool{} // actions to skip without asking
+)
+
+// confirmInteractive asks the user whether action should be done to
+// subject, returning true if it should
+func confirmInteractive(subject interface{}, action string) bool {
+	interactiveMu.Lock()
+	defer interactiveMu.Unlock()
+	if interactiveSkip[action] {
+		return false
+	}
+	fmt.Printf("rclone: %s \"%v\"?\n", action, subject)
+	switch config.Command([]string{
+		"yYes, this is OK",
+		"nNo, skip this",
+		fmt.Sprintf("sSkip all %s operations with no more questions", action),
+		"qQuit rclone now",
+	}) {
+	case 'y':
+		return true
+	case 's':
+		interactiveSkip[action] = true
+	case 'q':
+		fs.Logf(nil, "Quitting as asked to by the user")
+		atexit.Run()
+		os.Exit(atexit.StopExitCode)
+	}
+	return false
+}
+
 // Copy src object to dst or f if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
 // It returns the destination object if possible.  Note that this may
 // be nil.
 func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
-	newDst = dst
-	if fs.Config.DryRun {
-		fs.Logf(src, "Not copying as --dry-run")
-		return newDst, nil
+	if SkipDestructive(src, "copy") {
+		return dst, nil
 	}
+	return copyObject(f, dst, remote, src)
+}
+
+// copyObject does the work for Copy without checking --dry-run or
+// --interactive
+func copyObject(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+	newDst = dst
 	maxTries := fs.Config.LowLevelRetries
 	tries := 0
 	doUpdate := dst != nil
@@ -259,12 +333,14 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		}
 	}
 	hashOption := &fs.HashesOption{Hashes: common}
+	downloadOptions := addHeaderOptions([]fs.OpenOption{hashOption}, fs.Config.DownloadHeaders)
+	uploadOptions := addHeaderOptions([]fs.OpenOption{hashOption}, fs.Config.UploadHeaders)
 	var actionTaken string
 	for {
 		// Try server side copy first - if has optional interface and
 		// is same underlying remote
 		actionTaken = "Copied (server side copy)"
-		if doCopy := f.Features().Copy; doCopy != nil && SameConfig(src.Fs(), f) {
+		if doCopy := f.Features().Copy; doCopy != nil && canServerSide(src.Fs(), f) {
 			newDst, err = doCopy(src, remote)
 			if err == nil {
 				dst = newDst
@@ -275,7 +351,7 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		// If can't server side copy, do it manually
 		if err == fs.ErrorCantCopy {
 			var in0 io.ReadCloser
-			in0, err = src.Open(hashOption)
+			in0, err = src.Open(downloadOptions...)
 			if err != nil {
 				err = errors.Wrap(err, "failed to open source object")
 			} else {
@@ -287,10 +363,10 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 				}
 				if doUpdate {
 					actionTaken = "Copied (replaced existing)"
-					err = dst.Update(in, wrappedSrc, hashOption)
+					err = dst.Update(in, wrappedSrc, uploadOptions...)
 				} else {
 					actionTaken = "Copied (new)"
-					dst, err = f.Put(in, wrappedSrc, hashOption)
+					dst, err = f.Put(in, wrappedSrc, uploadOptions...)
 				}
 				closeErr := in.Close()
 				if err == nil {
@@ -319,7 +395,7 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 
 	// Verify sizes are the same after transfer
 	if sizeDiffers(src, dst) {
-		err = errors.Errorf("corrupted on transfer: sizes differ %d vs %d", src.Size(), dst.Size())
+		err = fserrors.ClassError(errors.Errorf("corrupted on transfer: sizes differ %d vs %d", src.Size(), dst.Size()), fserrors.ClassChecksum)
 		fs.Errorf(dst, "%v", err)
 		fs.CountError(err)
 		removeFailedCopy(dst)
@@ -329,20 +405,19 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 	// Verify hashes are the same after transfer - ignoring blank hashes
 	// TODO(klauspost): This could be extended, so we always create a hash type matching
 	// the destination, and calculate it while sending.
+	var srcSum, dstSum string
 	if hashType != hash.None {
-		var srcSum string
 		srcSum, err = src.Hash(hashType)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(src, "Failed to read src hash: %v", err)
 		} else if srcSum != "" {
-			var dstSum string
 			dstSum, err = dst.Hash(hashType)
 			if err != nil {
 				fs.CountError(err)
 				fs.Errorf(dst, "Failed to read hash: %v", err)
 			} else if !fs.Config.IgnoreChecksum && !hash.Equals(srcSum, dstSum) {
-				err = errors.Errorf("corrupted on transfer: %v hash differ %q vs %q", hashType, srcSum, dstSum)
+				err = fserrors.ClassError(errors.Errorf("corrupted on transfer: %v hash differ %q vs %q", hashType, srcSum, dstSum), fserrors.ClassChecksum)
 				fs.Errorf(dst, "%v", err)
 				fs.CountError(err)
 				removeFailedCopy(dst)
@@ -351,26 +426,115 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		}
 	}
 
+	// Re-read the destination and check it if required
+	if fs.Config.Verify {
+		err = verifyCopy(f, remote, src)
+		if err != nil {
+			err = errors.Wrap(err, "failed to verify copy")
+			fs.Errorf(dst, "%v", err)
+			fs.CountError(err)
+			removeFailedCopy(dst)
+			return newDst, err
+		}
+	}
+
+	// Remember the hash of both ends for checking later
+	if err == nil && fs.Config.HashDB {
+		recordHash(src, hashType, srcSum)
+		recordHash(dst, hashType, dstSum)
+	}
+
 	fs.Infof(src, actionTaken)
 	return newDst, err
 }
 
+// objectHash returns the hash of type ht of o, downloading o and
+// hashing it if its remote doesn't supply one
+func objectHash(o fs.Object, ht hash.Type) (string, error) {
+	if o.Fs().Hashes().Contains(ht) {
+		sum, err := o.Hash(ht)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %v hash", ht)
+		}
+		if sum != "" {
+			return sum, nil
+		}
+	}
+	in, err := o.Open()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open for hashing")
+	}
+	sums, err := hash.StreamTypes(in, hash.NewHashSet(ht))
+	closeErr := in.Close()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to calculate %v hash", ht)
+	}
+	if closeErr != nil {
+		return "", errors.Wrap(closeErr, "failed to close after hashing")
+	}
+	return sums[ht], nil
+}
+
+// verifyCopy re-reads the object at remote on f after it has been
+// copied from src and checks that its hash matches the source, for
+// --verify.
+//
+// A hash both remotes support is used if possible, otherwise the
+// objects are downloaded to calculate it.
+func verifyCopy(f fs.Fs, remote string, src fs.Object) error {
+	dst, err := f.NewObject(remote)
+	if err != nil {
+		return errors.Wrap(err, "failed to re-read destination")
+	}
+	if sizeDiffers(src, dst) {
+		return errors.Errorf("sizes differ %d vs %d", src.Size(), dst.Size())
+	}
+	ht := src.Fs().Hashes().Overlap(f.Hashes()).GetOne()
+	if ht == hash.None {
+		ht = src.Fs().Hashes().GetOne()
+	}
+	if ht == hash.None {
+		ht = f.Hashes().GetOne()
+	}
+	if ht == hash.None {
+		ht = hash.MD5
+	}
+	srcSum, err := objectHash(src, ht)
+	if err != nil {
+		return errors.Wrap(err, "source")
+	}
+	dstSum, err := objectHash(dst, ht)
+	if err != nil {
+		return errors.Wrap(err, "destination")
+	}
+	if srcSum != dstSum {
+		return errors.Errorf("%v hash differ %q vs %q", ht, srcSum, dstSum)
+	}
+	fs.Debugf(dst, "Verified %v hash %q", ht, dstSum)
+	return nil
+}
+
 // Move src object to dst or fdst if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
 // It returns the destination object if possible.  Note that this may
 // be nil.
 func Move(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
-	newDst = dst
-	if fs.Config.DryRun {
-		fs.Logf(src, "Not moving as --dry-run")
-		return newDst, nil
+	if SkipDestructive(src, "move") {
+		return dst, nil
 	}
+	return moveObject(fdst, dst, remote, src)
+}
+
+// moveObject does the work for Move without checking --dry-run or
+// --interactive
+func moveObject(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+	newDst = dst
 	// See if we have Move available
-	if doMove := fdst.Features().Move; doMove != nil && SameConfig(src.Fs(), fdst) {
+	if doMove := fdst.Features().Move; doMove != nil && canServerSide(src.Fs(), fdst) {
 		// Delete destination if it exists
 		if dst != nil {
-			err = DeleteFile(dst)
+			err = deleteFileWithBackupDir(dst, nil, false)
 			if err != nil {
 				return newDst, err
 			}
@@ -390,13 +554,13 @@ func Move(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Ob
 		}
 	}
 	// Move not found or didn't work so copy dst <- src
-	newDst, err = Copy(fdst, dst, remote, src)
+	newDst, err = copyObject(fdst, dst, remote, src)
 	if err != nil {
 		fs.Errorf(src, "Not deleting source as copy failed: %v", err)
 		return newDst, err
 	}
 	// Delete src if no error on copy
-	return newDst, DeleteFile(src)
+	return newDst, deleteFileWithBackupDir(src, nil, false)
 }
 
 // CanServerSideMove returns true if fdst support server side moves or
@@ -411,29 +575,40 @@ func CanServerSideMove(fdst fs.Fs) bool {
 }
 
 // DeleteFileWithBackupDir deletes a single file respecting --dry-run
-// and accumulating stats and errors.
+// and --interactive and accumulating stats and errors.
 //
 // If backupDir is set then it moves the file to there instead of
 // deleting
 func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
+	return deleteFileWithBackupDir(dst, backupDir, true)
+}
+
+// deleteFileWithBackupDir does the work for DeleteFileWithBackupDir
+//
+// If confirm is set it checks --dry-run and --interactive before
+// doing anything.  This is not needed when called from operations
+// which have already been confirmed.
+func deleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs, confirm bool) (err error) {
 	accounting.Stats.Checking(dst.Remote())
 	numDeletes := accounting.Stats.Deletes(1)
 	if fs.Config.MaxDelete != -1 && numDeletes > fs.Config.MaxDelete {
 		return fserrors.FatalError(errors.New("--max-delete threshold reached"))
 	}
-	action, actioned, actioning := "delete", "Deleted", "deleting"
+	action, actioned := "delete", "Deleted"
 	if backupDir != nil {
-		action, actioned, actioning = "move into backup dir", "Moved into backup dir", "moving into backup dir"
+		action, actioned = "move into backup dir", "Moved into backup dir"
 	}
-	if fs.Config.DryRun {
-		fs.Logf(dst, "Not %s as --dry-run", actioning)
-	} else if backupDir != nil {
+	if confirm && SkipDestructive(dst, action) {
+		accounting.Stats.DoneChecking(dst.Remote())
+		return nil
+	}
+	if backupDir != nil {
 		if !SameConfig(dst.Fs(), backupDir) {
 			err = errors.New("parameter to --backup-dir has to be on the same remote as destination")
 		} else {
 			remoteWithSuffix := dst.Remote() + fs.Config.Suffix
 			overwritten, _ := backupDir.NewObject(remoteWithSuffix)
-			_, err = Move(backupDir, overwritten, remoteWithSuffix, dst)
+			_, err = moveObject(backupDir, overwritten, remoteWithSuffix, dst)
 		}
 	} else {
 		err = dst.Remove()
@@ -441,7 +616,7 @@ func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
 	if err != nil {
 		fs.CountError(err)
 		fs.Errorf(dst, "Couldn't %s: %v", action, err)
-	} else if !fs.Config.DryRun {
+	} else {
 		fs.Infof(dst, actioned)
 	}
 	accounting.Stats.DoneChecking(dst.Remote())
@@ -506,6 +681,23 @@ func SameConfig(fdst, fsrc fs.Info) bool {
 	return fdst.Name() == fsrc.Name()
 }
 
+// SameRemoteType returns true if fdst and fsrc are the same type of
+// Fs, eg both are drive remotes
+func SameRemoteType(fdst, fsrc fs.Info) bool {
+	return fmt.Sprintf("%T", fdst) == fmt.Sprintf("%T", fsrc)
+}
+
+// canServerSide returns true if objects from fsrc can be server side
+// copied or moved to fdst.  This is possible if they use the same
+// config, or if they are the same type of remote and fdst supports
+// server side operations across configs.
+func canServerSide(fsrc, fdst fs.Info) bool {
+	if SameConfig(fdst, fsrc) {
+		return true
+	}
+	return fdst.Features().ServerSideAcrossConfigs && SameRemoteType(fdst, fsrc)
+}
+
 // Same returns true if fdst and fsrc point to the same underlying Fs
 func Same(fdst, fsrc fs.Info) bool {
 	return SameConfig(fdst, fsrc) && fdst.Root() == fsrc.Root()
@@ -766,6 +958,110 @@ func CheckDownload(fdst, fsrc fs.Fs) error {
 	return CheckFn(fdst, fsrc, check)
 }
 
+// recordHash records the hash of o in the --hash-db database
+//
+// sum is the hash of type ht of o if it has already been read.  If it
+// hasn't, eg because the ends have no hash in common or --size-only
+// is set, a hash the Fs of o supports is read instead.
+func recordHash(o fs.Object, ht hash.Type, sum string) {
+	if ht == hash.None || sum == "" {
+		ht = o.Fs().Hashes().GetOne()
+		if ht == hash.None {
+			return
+		}
+		var err error
+		sum, err = o.Hash(ht)
+		if err != nil {
+			fs.Errorf(o, "Failed to read hash for --hash-db: %v", err)
+			return
+		}
+	}
+	hashdb.Record(ht, sum, o)
+}
+
+// CheckAgainstDB checks the objects in f against the hashes recorded
+// in the --hash-db database when they were transferred, reporting any
+// which differ.  This detects objects which have been silently
+// corrupted since.
+//
+// Objects which aren't in the database are skipped, as are those
+// whose size or modification time have changed since they were
+// recorded as they have probably been updated on purpose.
+func CheckAgainstDB(f fs.Fs) error {
+	db, err := hashdb.Default()
+	if err != nil {
+		return err
+	}
+	var differences, missing, changed, noHashes int32
+	err = ListFn(f, func(o fs.Object) {
+		accounting.Stats.Checking(o.Remote())
+		defer accounting.Stats.DoneChecking(o.Remote())
+		entry, err := db.Get(o)
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(o, "%v", err)
+			return
+		}
+		if entry == nil {
+			atomic.AddInt32(&missing, 1)
+			fs.Debugf(o, "Not in the hash database")
+			return
+		}
+		if entry.Size != o.Size() || !entry.ModTime.Equal(o.ModTime()) {
+			atomic.AddInt32(&changed, 1)
+			fs.Debugf(o, "Size or modification time changed since recorded - not checking")
+			return
+		}
+		checked := false
+		for _, ht := range f.Hashes().Array() {
+			want := entry.Hash(ht)
+			if want == "" {
+				continue
+			}
+			got, err := o.Hash(ht)
+			if err != nil {
+				fs.CountError(err)
+				fs.Errorf(o, "Failed to read hash: %v", err)
+				return
+			}
+			if got == "" {
+				continue
+			}
+			checked = true
+			if !hash.Equals(want, got) {
+				err = fserrors.ClassError(errors.Errorf("%v hash differs from the one recorded at %s: %q vs %q", ht, entry.Recorded.Format(time.RFC3339), got, want), fserrors.ClassChecksum)
+				fs.Errorf(o, "%v", err)
+				fs.CountError(err)
+				atomic.AddInt32(&differences, 1)
+				return
+			}
+		}
+		if !checked {
+			atomic.AddInt32(&noHashes, 1)
+			fs.Debugf(o, "No hashes in common with the hash database")
+			return
+		}
+		fs.Debugf(o, "OK")
+	})
+	if err != nil {
+		return err
+	}
+	if missing > 0 {
+		fs.Logf(f, "%d files not in the hash database", missing)
+	}
+	if changed > 0 {
+		fs.Logf(f, "%d files changed since their hashes were recorded", changed)
+	}
+	if noHashes > 0 {
+		fs.Logf(f, "%d hashes could not be checked", noHashes)
+	}
+	fs.Logf(f, "%d differences found", differences)
+	if differences > 0 {
+		return errors.Errorf("%d differences found", differences)
+	}
+	return nil
+}
+
 // ListFn lists the Fs to the supplied function
 //
 // Lists in parallel which may get them out of order
@@ -906,8 +1202,7 @@ func ListDir(f fs.Fs, w io.Writer) error {
 
 // Mkdir makes a destination directory or container
 func Mkdir(f fs.Fs, dir string) error {
-	if fs.Config.DryRun {
-		fs.Logf(fs.LogDirName(f, dir), "Not making directory as dry run is set")
+	if SkipDestructive(fs.LogDirName(f, dir), "make directory") {
 		return nil
 	}
 	fs.Debugf(fs.LogDirName(f, dir), "Making directory")
@@ -922,8 +1217,7 @@ func Mkdir(f fs.Fs, dir string) error {
 // TryRmdir removes a container but not if not empty.  It doesn't
 // count errors but may return one.
 func TryRmdir(f fs.Fs, dir string) error {
-	if fs.Config.DryRun {
-		fs.Logf(fs.LogDirName(f, dir), "Not deleting as dry run is set")
+	if SkipDestructive(fs.LogDirName(f, dir), "remove directory") {
 		return nil
 	}
 	fs.Debugf(fs.LogDirName(f, dir), "Removing directory")
@@ -948,9 +1242,7 @@ func Purge(f fs.Fs, dir string) error {
 		// FIXME change the Purge interface so it takes a dir - see #1891
 		if doPurge := f.Features().Purge; doPurge != nil {
 			doFallbackPurge = false
-			if fs.Config.DryRun {
-				fs.Logf(f, "Not purging as --dry-run set")
-			} else {
+			if !SkipDestructive(f, "purge") {
 				err = doPurge()
 				if err == fs.ErrorCantPurge {
 					doFallbackPurge = true
@@ -1027,13 +1319,41 @@ func CleanUp(f fs.Fs) error {
 	if doCleanUp == nil {
 		return errors.Errorf("%v doesn't support cleanup", f)
 	}
-	if fs.Config.DryRun {
-		fs.Logf(f, "Not running cleanup as --dry-run set")
+	if SkipDestructive(f, "clean up") {
 		return nil
 	}
 	return doCleanUp()
 }
 
+// SetTier changes the storage class or tier of all the objects in f
+func SetTier(f fs.Fs, tier string) error {
+	if !f.Features().SetTier {
+		return errors.Errorf("%v doesn't support settier", f)
+	}
+	return ListFn(f, func(o fs.Object) {
+		do, ok := o.(fs.SetTierer)
+		if !ok {
+			fs.CountError(fs.ErrorNotImplemented)
+			fs.Errorf(o, "Object doesn't support SetTier")
+			return
+		}
+		if getTier, ok := o.(fs.GetTierer); ok && strings.EqualFold(getTier.GetTier(), tier) {
+			fs.Debugf(o, "Already has tier %q", tier)
+			return
+		}
+		if SkipDestructive(o, fmt.Sprintf("set tier to %q", tier)) {
+			return
+		}
+		err := do.SetTier(tier)
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(o, "Failed to set tier: %v", err)
+			return
+		}
+		fs.Infof(o, "Set tier to %q", tier)
+	})
+}
+
 // wrap a Reader and a Closer together into a ReadCloser
 type readCloser struct {
 	io.Reader
@@ -1068,6 +1388,7 @@ func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
 		if opt.Start > 0 || opt.End >= 0 {
 			options = append(options, &opt)
 		}
+		options = addHeaderOptions(options, fs.Config.DownloadHeaders)
 		in, err := o.Open(options...)
 		if err != nil {
 			fs.CountError(err)
@@ -1101,6 +1422,9 @@ func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
 }
 
 // Rcat reads data from the Reader until EOF and uploads it to a file on remote
+//
+// This doesn't ask for confirmation with --interactive since the data
+// being uploaded is often read from stdin.
 func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (dst fs.Object, err error) {
 	accounting.Stats.Transferring(dstFileName)
 	in = accounting.NewAccountSizeName(in, -1, dstFileName).WithBuffer()
@@ -1122,7 +1446,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	compare := func(dst fs.Object) error {
 		src := object.NewStaticObjectInfo(dstFileName, modTime, int64(readCounter.BytesRead()), false, hash.Sums(), fdst)
 		if !Equal(src, dst) {
-			err = errors.Errorf("corrupted on transfer")
+			err = fserrors.ClassError(errors.New("corrupted on transfer"), fserrors.ClassChecksum)
 			fs.CountError(err)
 			fs.Errorf(dst, "%v", err)
 			return err
@@ -1130,12 +1454,19 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 		return nil
 	}
 
+	if fs.Config.DryRun {
+		fs.Logf("stdin", "Not uploading as --dry-run")
+		// prevents "broken pipe" errors
+		_, err = io.Copy(ioutil.Discard, in)
+		return nil, err
+	}
+
 	// check if file small enough for direct upload
 	buf := make([]byte, fs.Config.StreamingUploadCutoff)
 	if n, err := io.ReadFull(trackingIn, buf); err == io.EOF || err == io.ErrUnexpectedEOF {
 		fs.Debugf(fdst, "File to upload is small (%d bytes), uploading instead of streaming", n)
 		src := object.NewMemoryObject(dstFileName, modTime, buf[:n])
-		return Copy(fdst, nil, dstFileName, src)
+		return copyObject(fdst, nil, dstFileName, src)
 	}
 
 	// Make a new ReadCloser with the bits we've already read
@@ -1153,7 +1484,8 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 			return nil, errors.Wrap(err, "Failed to create temporary local FS to spool file")
 		}
 		defer func() {
-			err := Purge(tmpLocalFs, "")
+			// the local backend can always purge
+			err := tmpLocalFs.Features().Purge()
 			if err != nil {
 				fs.Infof(tmpLocalFs, "Failed to cleanup temporary FS: %v", err)
 			}
@@ -1161,15 +1493,9 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 		fStreamTo = tmpLocalFs
 	}
 
-	if fs.Config.DryRun {
-		fs.Logf("stdin", "Not uploading as --dry-run")
-		// prevents "broken pipe" errors
-		_, err = io.Copy(ioutil.Discard, in)
-		return nil, err
-	}
-
 	objInfo := object.NewStaticObjectInfo(dstFileName, modTime, -1, false, nil, nil)
-	if dst, err = fStreamTo.Features().PutStream(in, objInfo, hashOption); err != nil {
+	options := addHeaderOptions([]fs.OpenOption{hashOption}, fs.Config.UploadHeaders)
+	if dst, err = fStreamTo.Features().PutStream(in, objInfo, options...); err != nil {
 		return dst, err
 	}
 	if err = compare(dst); err != nil {
@@ -1177,55 +1503,109 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	}
 	if !canStream {
 		// copy dst (which is the local object we have just streamed to) to the remote
-		return Copy(fdst, nil, dstFileName, dst)
+		return copyObject(fdst, nil, dstFileName, dst)
 	}
 	return dst, nil
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func PublicLink(f fs.Fs, remote string) (string, error) {
+//
+// If expire is set then the link will stop working after that
+// duration if the remote supports it.
+func PublicLink(f fs.Fs, remote string, expire fs.Duration) (string, error) {
 	doPublicLink := f.Features().PublicLink
 	if doPublicLink == nil {
 		return "", errors.Errorf("%v doesn't support public links", f)
 	}
-	return doPublicLink(remote)
+	return doPublicLink(remote, expire)
+}
+
+// ListPublicLinks returns the public links to the given file or folder
+func ListPublicLinks(f fs.Fs, remote string) ([]string, error) {
+	doListPublicLinks := f.Features().ListPublicLinks
+	if doListPublicLinks == nil {
+		return nil, errors.Errorf("%v doesn't support listing public links", f)
+	}
+	return doListPublicLinks(remote)
+}
+
+// RemovePublicLinks removes the public links to the given file or
+// folder so it is no longer shared
+func RemovePublicLinks(f fs.Fs, remote string) error {
+	doRemovePublicLinks := f.Features().RemovePublicLinks
+	if doRemovePublicLinks == nil {
+		return errors.Errorf("%v doesn't support removing public links", f)
+	}
+	if SkipDestructive(remote, "remove public links") {
+		return nil
+	}
+	return doRemovePublicLinks(remote)
 }
 
 // Rmdirs removes any empty directories (or directories only
-// containing empty directories) under f, including f.
+// containing empty directories) under dir in f, including dir unless
+// leaveRoot is set.
+//
+// Directories below --max-depth aren't listed so they and their
+// parents are assumed not to be empty.
 func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
 	dirEmpty := make(map[string]bool)
-	dirEmpty[""] = !leaveRoot
+	dirEmpty[dir] = !leaveRoot
+	// markNonEmpty marks dirPath and its parents up to dir as non-empty
+	markNonEmpty := func(dirPath string) {
+		for {
+			empty, found := dirEmpty[dirPath]
+			// End if we reach a directory which is non-empty
+			if found && !empty {
+				break
+			}
+			dirEmpty[dirPath] = false
+			if dirPath == dir || dirPath == "" {
+				break
+			}
+			dirPath = path.Dir(dirPath)
+			if dirPath == "." || dirPath == "/" {
+				dirPath = ""
+			}
+		}
+	}
+	// depth returns how many levels below dir remote is
+	depth := func(remote string) int {
+		remote = strings.TrimPrefix(strings.TrimPrefix(remote, dir), "/")
+		if remote == "" {
+			return 0
+		}
+		return strings.Count(remote, "/") + 1
+	}
 	err := walk.Walk(f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(f, "Failed to list %q: %v", dirPath, err)
+			// we don't know what is in it so don't delete it
+			markNonEmpty(dirPath)
 			return nil
 		}
 		for _, entry := range entries {
 			switch x := entry.(type) {
 			case fs.Directory:
-				// add a new directory as empty
 				dir := x.Remote()
+				if fs.Config.MaxDepth >= 0 && depth(dir) >= fs.Config.MaxDepth {
+					// this won't be listed so could have anything in
+					markNonEmpty(dir)
+					break
+				}
+				// add a new directory as empty
 				_, found := dirEmpty[dir]
 				if !found {
 					dirEmpty[dir] = true
 				}
 			case fs.Object:
 				// mark the parents of the file as being non-empty
-				dir := x.Remote()
-				for dir != "" {
-					dir = path.Dir(dir)
-					if dir == "." || dir == "/" {
-						dir = ""
-					}
-					empty, found := dirEmpty[dir]
-					// End if we reach a directory which is non-empty
-					if found && !empty {
-						break
-					}
-					dirEmpty[dir] = false
+				parent := path.Dir(x.Remote())
+				if parent == "." || parent == "/" {
+					parent = ""
 				}
+				markNonEmpty(parent)
 			}
 		}
 		return nil
@@ -1331,11 +1711,14 @@ func moveOrCopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName stri
 	}
 
 	// Find dst object if it exists
-	dstObj, err := fdst.NewObject(dstFileName)
-	if err == fs.ErrorObjectNotFound {
-		dstObj = nil
-	} else if err != nil {
-		return err
+	var dstObj fs.Object
+	if !fs.Config.NoCheckDest {
+		dstObj, err = fdst.NewObject(dstFileName)
+		if err == fs.ErrorObjectNotFound {
+			dstObj = nil
+		} else if err != nil {
+			return err
+		}
 	}
 
 	if NeedTransfer(dstObj, srcObj) {
@@ -1420,6 +1803,17 @@ func (l *ListFormat) AddHash(ht hash.Type) {
 	})
 }
 
+// AddTier adds file's storage tier to output
+func (l *ListFormat) AddTier() {
+	l.AppendOutput(func() string {
+		do, ok := l.entry.(fs.GetTierer)
+		if !ok {
+			return ""
+		}
+		return do.GetTier()
+	})
+}
+
 // AppendOutput adds string generated by specific function to printed output
 func (l *ListFormat) AppendOutput(functionToAppend func() string) {
 	if len(l.output) > 0 {
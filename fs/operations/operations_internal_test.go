@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/object"
 	"github.com/stretchr/testify/assert"
 )
@@ -38,3 +39,36 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+func TestSkipDestructive(t *testing.T) {
+	oldDryRun, oldInteractive, oldReadLine := fs.Config.DryRun, fs.Config.Interactive, config.ReadLine
+	defer func() {
+		fs.Config.DryRun, fs.Config.Interactive, config.ReadLine = oldDryRun, oldInteractive, oldReadLine
+		interactiveSkip = map[string]bool{}
+	}()
+	var answers []string
+	config.ReadLine = func() string {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer
+	}
+
+	fs.Config.DryRun, fs.Config.Interactive = false, false
+	assert.False(t, SkipDestructive("file", "delete"))
+
+	fs.Config.DryRun = true
+	assert.True(t, SkipDestructive("file", "delete"))
+
+	fs.Config.DryRun, fs.Config.Interactive = false, true
+	answers = []string{"y", "n", "x", "s"}
+	assert.False(t, SkipDestructive("file", "delete"))
+	assert.True(t, SkipDestructive("file", "delete"))
+	assert.True(t, SkipDestructive("file", "delete")) // x is invalid so s is used
+	assert.Len(t, answers, 0)
+
+	// doesn't ask again for delete, but does for copy
+	assert.True(t, SkipDestructive("file2", "delete"))
+	answers = []string{"Y"}
+	assert.False(t, SkipDestructive("file2", "copy"))
+	assert.Len(t, answers, 0)
+}
@@ -25,6 +25,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -33,11 +35,14 @@ import (
 	_ "github.com/ncw/rclone/backend/all" // import all backends
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/filter"
 	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/hashdb"
 	"github.com/ncw/rclone/fs/list"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fstest"
+	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -300,6 +305,89 @@ func TestCheckSizeOnly(t *testing.T) {
 	TestCheck(t)
 }
 
+func TestCheckAgainstDB(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ht := r.Fremote.Hashes().GetOne()
+	if ht == hash.None {
+		t.Skip("remote doesn't support hashes")
+	}
+
+	cacheDir, err := ioutil.TempDir("", "rclone-hashdb-test")
+	require.NoError(t, err)
+	oldCacheDir, oldHashDB := config.CacheDir, fs.Config.HashDB
+	config.CacheDir, fs.Config.HashDB = cacheDir, true
+	defer func() {
+		config.CacheDir, fs.Config.HashDB = oldCacheDir, oldHashDB
+		require.NoError(t, hashdb.CloseDefault())
+		require.NoError(t, os.RemoveAll(cacheDir))
+	}()
+
+	file1 := r.WriteObject("potato", "hello world", t1)
+	file2 := r.WriteObject("carrot", "goodbye world", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	// Not in the database so nothing to check
+	require.NoError(t, operations.CheckAgainstDB(r.Fremote))
+
+	record := func(remote, sum string) {
+		o, err := r.Fremote.NewObject(remote)
+		require.NoError(t, err)
+		if sum == "" {
+			sum, err = o.Hash(ht)
+			require.NoError(t, err)
+		}
+		hashdb.Record(ht, sum, o)
+	}
+
+	record(file1.Path, "")
+	record(file2.Path, "")
+	require.NoError(t, operations.CheckAgainstDB(r.Fremote))
+
+	// Pretend potato has been corrupted since
+	record(file1.Path, "0123456789abcdef")
+	oldErrors := accounting.Stats.GetErrors()
+	err = operations.CheckAgainstDB(r.Fremote)
+	require.Error(t, err)
+	assert.Equal(t, "1 differences found", err.Error())
+	assert.Equal(t, int64(1), accounting.Stats.GetErrors()-oldErrors)
+}
+
+func TestCopyFileRecordsHashSizeOnly(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ht := r.Fremote.Hashes().GetOne()
+	if ht == hash.None {
+		t.Skip("remote doesn't support hashes")
+	}
+
+	// Use a cache directory which doesn't exist yet
+	cacheDir, err := ioutil.TempDir("", "rclone-hashdb-test")
+	require.NoError(t, err)
+	oldCacheDir, oldHashDB, oldSizeOnly := config.CacheDir, fs.Config.HashDB, fs.Config.SizeOnly
+	config.CacheDir, fs.Config.HashDB, fs.Config.SizeOnly = filepath.Join(cacheDir, "sub"), true, true
+	defer func() {
+		config.CacheDir, fs.Config.HashDB, fs.Config.SizeOnly = oldCacheDir, oldHashDB, oldSizeOnly
+		require.NoError(t, hashdb.CloseDefault())
+		require.NoError(t, os.RemoveAll(cacheDir))
+	}()
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	err = operations.CopyFile(r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+
+	o, err := r.Fremote.NewObject(file1.Path)
+	require.NoError(t, err)
+	want, err := o.Hash(ht)
+	require.NoError(t, err)
+	db, err := hashdb.Default()
+	require.NoError(t, err)
+	entry, err := db.Get(o)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, want, entry.Hash(ht))
+}
+
 func TestCat(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
@@ -463,6 +551,63 @@ func TestRmdirsLeaveRoot(t *testing.T) {
 	)
 }
 
+func TestRmdirsSubdirNoLeaveRoot(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.Mkdir(r.Fremote)
+
+	r.ForceMkdir(r.Fremote)
+
+	require.NoError(t, operations.Mkdir(r.Fremote, "A1"))
+	require.NoError(t, operations.Mkdir(r.Fremote, "A1/B1"))
+	require.NoError(t, operations.Mkdir(r.Fremote, "A2"))
+
+	require.NoError(t, operations.Rmdirs(r.Fremote, "A1", false))
+
+	fstest.CheckListingWithPrecision(
+		t,
+		r.Fremote,
+		[]fstest.Item{},
+		[]string{
+			"A2",
+		},
+		fs.Config.ModifyWindow,
+	)
+}
+
+func TestRmdirsMaxDepth(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.Mkdir(r.Fremote)
+
+	r.ForceMkdir(r.Fremote)
+	file1 := r.WriteObject("A1/B1/C1/one", "aaa", t1)
+	require.NoError(t, operations.Mkdir(r.Fremote, "A2"))
+	require.NoError(t, operations.Mkdir(r.Fremote, "A3/B3"))
+
+	fs.Config.MaxDepth = 2
+	defer func() { fs.Config.MaxDepth = -1 }()
+
+	// A2 is known to be empty but A1/B1 and A3/B3 aren't listed
+	require.NoError(t, operations.Rmdirs(r.Fremote, "", true))
+
+	fstest.CheckListingWithPrecision(
+		t,
+		r.Fremote,
+		[]fstest.Item{
+			file1,
+		},
+		[]string{
+			"A1",
+			"A1/B1",
+			"A1/B1/C1",
+			"A3",
+			"A3/B3",
+		},
+		fs.Config.ModifyWindow,
+	)
+}
+
 func TestMoveFile(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
@@ -518,6 +663,86 @@ func TestCopyFile(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 }
 
+func TestCopyFileVerify(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	fs.Config.Verify = true
+	defer func() { fs.Config.Verify = false }()
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+
+	err := operations.CopyFile(r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+// corruptFs is an fs.Fs whose objects read back differently to the
+// way they were written
+type corruptFs struct {
+	fs.Fs
+	size bool // corrupt the size rather than the hash
+}
+
+// NewObject finds the Object at remote
+func (f *corruptFs) NewObject(remote string) (fs.Object, error) {
+	o, err := f.Fs.NewObject(remote)
+	if err != nil {
+		return nil, err
+	}
+	return corruptObject{Object: o, size: f.size}, nil
+}
+
+// corruptObject is an fs.Object with the wrong size or hash
+type corruptObject struct {
+	fs.Object
+	size bool
+}
+
+// Size returns the wrong size if required
+func (o corruptObject) Size() int64 {
+	if o.size {
+		return o.Object.Size() + 1
+	}
+	return o.Object.Size()
+}
+
+// Hash returns the wrong hash if required
+func (o corruptObject) Hash(ht hash.Type) (string, error) {
+	if o.size {
+		return o.Object.Hash(ht)
+	}
+	return "corrupt", nil
+}
+
+func TestCopyFileVerifyFails(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	fs.Config.Verify = true
+	defer func() { fs.Config.Verify = false }()
+	defer accounting.Stats.ResetCounters()
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+
+	for _, test := range []struct {
+		size bool
+		want string
+	}{
+		{size: false, want: "hash differ"},
+		{size: true, want: "sizes differ"},
+	} {
+		fdst := &corruptFs{Fs: r.Fremote, size: test.size}
+		err := operations.CopyFile(fdst, r.Flocal, file1.Path, file1.Path)
+		require.Error(t, err, "%+v", test)
+		assert.Contains(t, err.Error(), "failed to verify copy", "%+v", test)
+		assert.Contains(t, err.Error(), test.want, "%+v", test)
+		fstest.CheckItems(t, r.Flocal, file1)
+		fstest.CheckItems(t, r.Fremote)
+	}
+}
+
 // testFsInfo is for unit testing fs.Info
 type testFsInfo struct {
 	name      string
@@ -566,6 +791,17 @@ func TestSameConfig(t *testing.T) {
 	}
 }
 
+func TestSameRemoteType(t *testing.T) {
+	a := &testFsInfo{name: "name", root: "root"}
+	b := &testFsInfo{name: "namey", root: "root"}
+	type otherFsInfo struct {
+		testFsInfo
+	}
+	c := &otherFsInfo{testFsInfo{name: "name", root: "root"}}
+	assert.True(t, operations.SameRemoteType(a, b))
+	assert.False(t, operations.SameRemoteType(a, c))
+}
+
 func TestSame(t *testing.T) {
 	a := &testFsInfo{name: "name", root: "root"}
 	for _, test := range []struct {
@@ -738,4 +974,20 @@ func TestListFormat(t *testing.T) {
 			assert.Equal(t, test.want, got)
 		}
 	}
+
+	list.SetOutput(nil)
+	list.AddTier()
+	assert.Equal(t, "", operations.ListFormatted(&items[1], &list))
+	var tiered fs.DirEntry = tierObject{mockobject.New("potato")}
+	assert.Equal(t, "COLD", operations.ListFormatted(&tiered, &list))
+}
+
+// tierObject is a mock object with a fixed storage tier
+type tierObject struct {
+	mockobject.Object
+}
+
+// GetTier returns the storage tier of the object
+func (o tierObject) GetTier() string {
+	return "COLD"
 }
@@ -3,17 +3,22 @@ package sync
 
 import (
 	"context"
+	"crypto/sha1"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"sync"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/filter"
 	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/march"
 	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/lib/atexit"
+	"github.com/ncw/rclone/lib/lockfile"
 	"github.com/pkg/errors"
 )
 
@@ -31,6 +36,8 @@ type syncCopyMove struct {
 	deletersWg     sync.WaitGroup         // for delete before go routine
 	deleteFilesCh  chan fs.Object         // channel to receive deletes if delete before
 	trackRenames   bool                   // set if we should do server side renames
+	noTraverse     bool                   // set if we should look up dst files rather than list them
+	noCheckDest    bool                   // set if we shouldn't look at the dst at all
 	dstFilesMu     sync.Mutex             // protect dstFiles
 	dstFiles       map[string]fs.Object   // dst files, always filled
 	srcFiles       map[string]fs.Object   // src files, only used if deleteBefore
@@ -76,11 +83,28 @@ func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		toBeUploaded:       make(fs.ObjectPairChan, fs.Config.Transfers),
 		deleteFilesCh:      make(chan fs.Object, fs.Config.Checkers),
 		trackRenames:       fs.Config.TrackRenames,
+		noTraverse:         fs.Config.NoTraverse,
+		noCheckDest:        fs.Config.NoCheckDest,
 		commonHash:         fsrc.Hashes().Overlap(fdst.Hashes()).GetOne(),
 		toBeRenamed:        make(fs.ObjectPairChan, fs.Config.Transfers),
 		trackRenamesCh:     make(chan fs.Object, fs.Config.Checkers),
 	}
 	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if s.noTraverse && s.deleteMode != fs.DeleteModeOff {
+		fs.Errorf(nil, "Ignoring --no-traverse with sync")
+		s.noTraverse = false
+	}
+	if s.noCheckDest {
+		if s.deleteMode != fs.DeleteModeOff {
+			return nil, fserrors.FatalError(errors.New("can't use --no-check-dest with sync: use copy instead"))
+		}
+		if fs.Config.Immutable {
+			return nil, fserrors.FatalError(errors.New("can't use --no-check-dest with --immutable"))
+		}
+		if fs.Config.BackupDir != "" {
+			return nil, fserrors.FatalError(errors.New("can't use --no-check-dest with --backup-dir"))
+		}
+	}
 	if s.trackRenames {
 		// Don't track renames for remotes without server-side move support.
 		if !operations.CanServerSideMove(fdst) {
@@ -611,6 +635,17 @@ func (s *syncCopyMove) run() error {
 		return nil
 	}
 
+	// Stop starting new transfers if asked to stop gracefully
+	stopping, doneStopping := atexit.Stopping()
+	defer doneStopping()
+	go func() {
+		select {
+		case <-stopping:
+			s.processError(atexit.ErrorStopped)
+		case <-s.ctx.Done():
+		}
+	}()
+
 	// Start background checking and transferring pipeline
 	s.startCheckers()
 	s.startRenamers()
@@ -622,6 +657,8 @@ func (s *syncCopyMove) run() error {
 
 	// set up a march over fdst and fsrc
 	m := march.New(s.ctx, s.fdst, s.fsrc, s.dir, s)
+	m.NoTraverse = s.noTraverse
+	m.NoCheckDest = s.noCheckDest
 	m.Run()
 
 	s.stopTrackRenames()
@@ -822,13 +859,50 @@ func runSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 	return do.run()
 }
 
+// lockDestination takes the --dest-lock for fdst if required,
+// returning a function to release it.
+//
+// The lock is a file in the cache directory named after the hash of
+// the destination's remote and path.
+func lockDestination(fdst fs.Fs) (unlock func(), err error) {
+	if !fs.Config.DestLock {
+		return func() {}, nil
+	}
+	dest := fdst.Name() + ":" + fdst.Root()
+	path := filepath.Join(config.CacheDir, "lock", fmt.Sprintf("%x.lock", sha1.Sum([]byte(dest))))
+	fs.Debugf(fdst, "Taking --dest-lock %q", path)
+	lock, err := lockfile.New(path, fmt.Sprintf("rclone %q", dest), fs.Config.DestLockWait)
+	if err != nil {
+		if errors.Cause(err) == lockfile.ErrorLocked {
+			err = errors.Wrap(err, "another sync, copy or move is using the destination")
+		}
+		return nil, fserrors.FatalError(errors.Wrap(err, "--dest-lock"))
+	}
+	return func() {
+		err := lock.Unlock()
+		if err != nil {
+			fs.Errorf(fdst, "Failed to release --dest-lock: %v", err)
+		}
+	}, nil
+}
+
 // Sync fsrc into fdst
 func Sync(fdst, fsrc fs.Fs) error {
+	unlock, err := lockDestination(fdst)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	return runSyncCopyMove(fdst, fsrc, fs.Config.DeleteMode, false, false)
 }
 
 // CopyDir copies fsrc into fdst
 func CopyDir(fdst, fsrc fs.Fs) error {
+	unlock, err := lockDestination(fdst)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	return runSyncCopyMove(fdst, fsrc, fs.DeleteModeOff, false, false)
 }
 
@@ -843,11 +917,15 @@ func MoveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
 		fs.Errorf(fdst, "Nothing to do as source and destination are the same")
 		return nil
 	}
+	unlock, err := lockDestination(fdst)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// First attempt to use DirMover if exists, same Fs and no filters are active
 	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && filter.Active.InActive() {
-		if fs.Config.DryRun {
-			fs.Logf(fdst, "Not doing server side directory move as --dry-run")
+		if operations.SkipDestructive(fdst, "server side directory move") {
 			return nil
 		}
 		fs.Debugf(fdst, "Using server side directory move")
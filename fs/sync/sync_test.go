@@ -3,6 +3,9 @@
 package sync
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -10,10 +13,13 @@ import (
 	_ "github.com/ncw/rclone/backend/all" // import all backends
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fstest"
+	"github.com/ncw/rclone/lib/lockfile"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/unicode/norm"
@@ -61,6 +67,93 @@ func TestCopy(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 }
 
+// Test copy with --no-traverse
+func TestCopyNoTraverse(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	file2 := r.WriteFile("sub dir/potato", "hello potato", t2)
+	file3 := r.WriteObject("sub dir/potato", "the old potato", t1)
+	file4 := r.WriteObject("dst only", "only in the destination", t1)
+	fstest.CheckItems(t, r.Fremote, file3, file4)
+
+	fs.Config.NoTraverse = true
+	defer func() { fs.Config.NoTraverse = false }()
+
+	err := CopyDir(r.Fremote, r.Flocal)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file4)
+}
+
+// Test copy with --no-check-dest
+func TestCopyNoCheckDest(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	r.Mkdir(r.Fremote)
+
+	fs.Config.NoCheckDest = true
+	defer func() { fs.Config.NoCheckDest = false }()
+
+	err := CopyDir(r.Fremote, r.Flocal)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// sync can't be done without looking at the destination
+	err = Sync(r.Fremote, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+}
+
+// Test copy with --dest-lock
+func TestCopyWithDestLock(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	r.Mkdir(r.Fremote)
+
+	oldCacheDir := config.CacheDir
+	var err error
+	config.CacheDir, err = ioutil.TempDir("", "rclone-sync-test")
+	require.NoError(t, err)
+	fs.Config.DestLock = true
+	defer func() {
+		_ = os.RemoveAll(config.CacheDir)
+		config.CacheDir = oldCacheDir
+		fs.Config.DestLock = false
+	}()
+
+	// Hold the lock so the copy can't run
+	unlock, err := lockDestination(r.Fremote)
+	require.NoError(t, err)
+	err = CopyDir(r.Fremote, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), lockfile.ErrorLocked.Error())
+	assert.Contains(t, err.Error(), "another sync, copy or move is using the destination")
+	fstest.CheckItems(t, r.Fremote)
+
+	// Release the lock so it can
+	unlock()
+	err = CopyDir(r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// Other errors taking the lock aren't blamed on another sync
+	lockDir := filepath.Join(config.CacheDir, "lock")
+	require.NoError(t, os.RemoveAll(lockDir))
+	require.NoError(t, ioutil.WriteFile(lockDir, []byte("not a directory"), 0600))
+	_, err = lockDestination(r.Fremote)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "--dest-lock")
+	assert.NotContains(t, err.Error(), "another sync")
+}
+
 // Test copy with depth
 func TestCopyWithDepth(t *testing.T) {
 	r := fstest.NewRun(t)
@@ -998,35 +998,43 @@ func Run(t *testing.T, opt *Opt) {
 			t.Skip("FS has no PublicLinker interface")
 		}
 
+		expiry := fs.Duration(60 * time.Second)
+
 		// if object not found
-		link, err := doPublicLink(file1.Path + "_does_not_exist")
+		link, err := doPublicLink(file1.Path+"_does_not_exist", expiry)
 		require.Error(t, err, "Expected to get error when file doesn't exist")
 		require.Equal(t, "", link, "Expected link to be empty on error")
 
 		// sharing file for the first time
-		link1, err := doPublicLink(file1.Path)
+		link1, err := doPublicLink(file1.Path, expiry)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link1, "Link should not be empty")
 
-		link2, err := doPublicLink(file2.Path)
+		link2, err := doPublicLink(file2.Path, expiry)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link2, "Link should not be empty")
 
 		require.NotEqual(t, link1, link2, "Links to different files should differ")
 
 		// sharing file for the 2nd time
-		link1, err = doPublicLink(file1.Path)
+		link1, err = doPublicLink(file1.Path, expiry)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link1, "Link should not be empty")
 
 		// sharing directory for the first time
 		path := path.Dir(file2.Path)
-		link3, err := doPublicLink(path)
+		link3, err := doPublicLink(path, expiry)
+		if errors.Cause(err) == fs.ErrorCantShareDirectories {
+			t.Log("skipping directory tests as not supported on this backend")
+			_, err = remote.Features().PublicLink("", expiry)
+			assert.Equal(t, fs.ErrorCantShareDirectories, errors.Cause(err), "root should be refused like other directories")
+			return
+		}
 		require.NoError(t, err)
 		require.NotEqual(t, "", link3, "Link should not be empty")
 
 		// sharing directory for the second time
-		link3, err = doPublicLink(path)
+		link3, err = doPublicLink(path, expiry)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link3, "Link should not be empty")
 
@@ -1040,11 +1048,33 @@ func Run(t *testing.T, opt *Opt) {
 		_, err = subRemote.Put(buf, obji)
 		require.NoError(t, err)
 
-		link4, err := subRemote.Features().PublicLink("")
+		link4, err := subRemote.Features().PublicLink("", expiry)
 		require.NoError(t, err, "Sharing root in a sub-remote should work")
 		require.NotEqual(t, "", link4, "Link should not be empty")
 	})
 
+	// TestPublicLinkManager tests listing and removing public links
+	t.Run("TestPublicLinkManager", func(t *testing.T) {
+		skipIfNotOk(t)
+
+		features := remote.Features()
+		if features.ListPublicLinks == nil || features.RemovePublicLinks == nil {
+			t.Skip("FS has no PublicLinkManager interface")
+		}
+
+		// file1 was shared by TestPublicLink
+		links, err := features.ListPublicLinks(file1.Path)
+		require.NoError(t, err)
+		require.NotEqual(t, 0, len(links), "Expected file1 to be shared")
+
+		err = features.RemovePublicLinks(file1.Path)
+		require.NoError(t, err)
+
+		links, err = features.ListPublicLinks(file1.Path)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(links), "Expected file1 not to be shared")
+	})
+
 	// TestObjectRemove tests Remove
 	t.Run("TestObjectRemove", func(t *testing.T) {
 		skipIfNotOk(t)
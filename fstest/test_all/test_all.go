@@ -122,6 +122,11 @@ var (
 			SubDir:   false,
 			FastList: false,
 		},
+		{
+			Name:     "TestSeafile:",
+			SubDir:   false,
+			FastList: false,
+		},
 		{
 			Name:     "TestWebdav:",
 			SubDir:   false,
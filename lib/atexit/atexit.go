@@ -2,36 +2,119 @@
 // the program exits unexpectedly due to a signal.
 //
 // You should also make sure you call Run in the normal exit path.
+//
+// It also provides a graceful stop - while anything is using
+// Stopping the first signal asks it to stop rather than exiting.
 package atexit
 
 import (
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/pkg/errors"
 )
 
+// ErrorStopped should be returned by anything which stops early
+// because a graceful stop was asked for
+var ErrorStopped = fserrors.FatalError(errors.New("stopped by interrupt"))
+
+// StopExitCode is the exit code used if a graceful stop is abandoned
+// because of a second signal or because it took too long
+var StopExitCode = 1
+
 var (
 	fns          []func()
 	exitOnce     sync.Once
 	registerOnce sync.Once
+	stopMu       sync.Mutex      // protects the below
+	stopping     *stopGeneration // current users of Stopping - nil if none yet
 )
 
+// stopGeneration is a stopping channel and the number of callers of
+// Stopping watching it.  A new one is made after each graceful stop
+// so callers finishing with an old one don't change the count of the
+// new one.
+type stopGeneration struct {
+	ch       chan struct{} // closed when a graceful stop is asked for
+	stoppers int           // number of users of ch
+}
+
 // Register a function to be called on exit
 func Register(fn func()) {
 	fns = append(fns, fn)
-	// Run AtExit handlers on SIGINT or SIGTERM so everything gets
-	// tidied up properly
+	startSignalHandler()
+}
+
+// Stopping returns a channel which is closed when a graceful stop
+// has been asked for and a function which must be called when the
+// caller is no longer watching the channel.
+//
+// While there are callers watching the channel the first SIGINT or
+// SIGTERM closes it instead of exiting.  The caller should then
+// stop starting new work, finish what it is doing and return
+// ErrorStopped.  A second signal, or --stop-timeout expiring, runs
+// the exit functions and exits immediately.
+func Stopping() (<-chan struct{}, func()) {
+	startSignalHandler()
+	stopMu.Lock()
+	defer stopMu.Unlock()
+	if stopping == nil {
+		stopping = &stopGeneration{ch: make(chan struct{})}
+	}
+	gen := stopping
+	gen.stoppers++
+	return gen.ch, func() {
+		stopMu.Lock()
+		gen.stoppers--
+		stopMu.Unlock()
+	}
+}
+
+// startGracefulStop closes the stopping channel if anything is
+// watching it and returns true if it did
+func startGracefulStop() bool {
+	stopMu.Lock()
+	defer stopMu.Unlock()
+	if stopping == nil || stopping.stoppers <= 0 {
+		return false
+	}
+	close(stopping.ch)
+	stopping = nil
+	return true
+}
+
+// startSignalHandler runs the AtExit handlers on SIGINT or SIGTERM
+// so everything gets tidied up properly
+func startSignalHandler() {
 	registerOnce.Do(func() {
 		go func() {
 			ch := make(chan os.Signal, 1)
-			signal.Notify(ch, os.Interrupt) // syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT
+			signal.Notify(ch, os.Interrupt, syscall.SIGTERM) // syscall.SIGQUIT
 			sig := <-ch
 			fs.Infof(nil, "Signal received: %s", sig)
+			exitCode := 0
+			if startGracefulStop() {
+				exitCode = StopExitCode
+				fs.Logf(nil, "Stopping after the transfers in progress have finished - interrupt again to stop immediately")
+				var timeout <-chan time.Time
+				if fs.Config.StopTimeout > 0 {
+					timeout = time.After(fs.Config.StopTimeout)
+				}
+				select {
+				case sig = <-ch:
+					fs.Logf(nil, "Signal received: %s - stopping immediately", sig)
+				case <-timeout:
+					fs.Logf(nil, "Transfers didn't finish within --stop-timeout %v - stopping immediately", fs.Config.StopTimeout)
+				}
+			}
 			Run()
 			fs.Infof(nil, "Exiting...")
-			os.Exit(0)
+			os.Exit(exitCode)
 		}()
 	})
 }
@@ -0,0 +1,46 @@
+package atexit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+	}
+	return false
+}
+
+func TestGracefulStop(t *testing.T) {
+	// Nothing watching so the signal should exit
+	assert.False(t, startGracefulStop())
+
+	// Finished watching so the signal should exit
+	_, done := Stopping()
+	done()
+	assert.False(t, startGracefulStop())
+
+	// Watching so the channels should be closed
+	stop1, done1 := Stopping()
+	stop2, done2 := Stopping()
+	assert.False(t, isClosed(stop1))
+	assert.True(t, startGracefulStop())
+	assert.True(t, isClosed(stop1))
+	assert.True(t, isClosed(stop2))
+
+	// A new watcher gets a new channel
+	stop3, done3 := Stopping()
+	defer done3()
+	assert.False(t, isClosed(stop3))
+
+	// The old watchers finishing doesn't stop the new one being
+	// counted
+	done1()
+	done2()
+	assert.True(t, startGracefulStop())
+	assert.True(t, isClosed(stop3))
+}
@@ -0,0 +1,89 @@
+// Package lockfile provides lock files which stop more than one
+// process working on the same thing at once.
+package lockfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorLocked is returned if the lock is held by another process
+var ErrorLocked = errors.New("locked by another process")
+
+// pollInterval is how often to retry a lock while waiting for it
+var pollInterval = time.Second
+
+// Lock is a lock file held by this process
+type Lock struct {
+	path string
+	f    *os.File
+}
+
+// New takes the lock at path, waiting for up to wait for another
+// process to release it.
+//
+// info is written into the lock file to identify the holder and is
+// returned in the error if the lock is held by someone else.
+func New(path string, info string, wait time.Duration) (*Lock, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make lock directory")
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		f, err := openLocked(path)
+		if err == nil {
+			l := &Lock{path: path, f: f}
+			err = l.write(info)
+			if err != nil {
+				_ = l.Unlock()
+				return nil, err
+			}
+			return l, nil
+		}
+		if err != ErrorLocked {
+			return nil, errors.Wrap(err, "failed to take lock")
+		}
+		if !time.Now().Before(deadline) {
+			return nil, errors.Wrapf(ErrorLocked, "%s: held by %s", path, holder(path))
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// write info into the lock file
+func (l *Lock) write(info string) error {
+	err := l.f.Truncate(0)
+	if err == nil {
+		_, err = l.f.WriteAt([]byte(fmt.Sprintf("%s, pid %d, since %s\n", info, os.Getpid(), time.Now().Format(time.RFC3339))), 0)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write lock file")
+	}
+	return nil
+}
+
+// holder returns the description of who has the lock at path
+func holder(path string) string {
+	info, err := ioutil.ReadFile(path)
+	if err != nil || len(info) == 0 {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(info))
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	if l.f == nil {
+		return nil
+	}
+	err := closeLocked(l.f, l.path)
+	l.f = nil
+	return err
+}
@@ -0,0 +1,46 @@
+// Lock files using fcntl on the unix systems without flock
+//
+// fcntl locks are released if the process dies but they belong to
+// the process rather than the open file so they don't stop the same
+// process taking the lock twice.
+
+// +build solaris
+
+package lockfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// openLocked opens path and locks it, returning ErrorLocked if
+// another process has it locked
+func openLocked(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	lock := syscall.Flock_t{
+		Type:   syscall.F_WRLCK,
+		Whence: 0, // Start and Len are from the start of the file
+		Start:  0,
+		Len:    0, // lock the whole file
+	}
+	err = syscall.FcntlFlock(f.Fd(), syscall.F_SETLK, &lock)
+	if err != nil {
+		_ = f.Close()
+		if err == syscall.EAGAIN || err == syscall.EACCES {
+			return nil, ErrorLocked
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// closeLocked releases the lock on f
+//
+// The file is left behind since removing it would race with another
+// process which has opened it but not locked it yet.
+func closeLocked(f *os.File, path string) error {
+	return f.Close()
+}
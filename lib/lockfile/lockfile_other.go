@@ -0,0 +1,22 @@
+// Lock files aren't supported on the remaining systems
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package lockfile
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// openLocked returns an error as there is no way of locking path
+func openLocked(path string) (*os.File, error) {
+	return nil, errors.Errorf("lock files aren't supported on %s", runtime.GOOS)
+}
+
+// closeLocked closes f
+func closeLocked(f *os.File, path string) error {
+	return f.Close()
+}
@@ -0,0 +1,48 @@
+package lockfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	if runtime.GOOS == "solaris" {
+		t.Skip("fcntl locks don't stop the same process taking the lock twice")
+	}
+	dir, err := ioutil.TempDir("", "rclone-lockfile-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	oldPollInterval := pollInterval
+	pollInterval = 10 * time.Millisecond
+	defer func() { pollInterval = oldPollInterval }()
+	path := filepath.Join(dir, "sub", "test.lock")
+
+	l1, err := New(path, "first", 0)
+	require.NoError(t, err)
+
+	// flock locks are per open file so this works in one process
+	_, err = New(path, "second", 0)
+	require.Error(t, err)
+	assert.Equal(t, ErrorLocked, errors.Cause(err))
+	assert.Contains(t, err.Error(), "held by first, pid")
+
+	// wait for the lock to be released
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		assert.NoError(t, l1.Unlock())
+	}()
+	l2, err := New(path, "third", time.Minute)
+	require.NoError(t, err)
+	assert.NoError(t, l2.Unlock())
+	assert.NoError(t, l2.Unlock())
+}
@@ -0,0 +1,36 @@
+// Lock files using flock which is released if the process dies
+
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package lockfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// openLocked opens path and locks it, returning ErrorLocked if
+// another process has it locked
+func openLocked(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		_ = f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, ErrorLocked
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// closeLocked releases the lock on f
+//
+// The file is left behind since removing it would race with another
+// process which has opened it but not locked it yet.
+func closeLocked(f *os.File, path string) error {
+	return f.Close()
+}
@@ -0,0 +1,34 @@
+// Lock files using exclusive creation
+//
+// On Windows an open file can't be removed, so if the lock file can
+// be removed the process which held it has gone away.  This doesn't
+// hold on other systems which can remove open files.
+
+package lockfile
+
+import (
+	"os"
+)
+
+// openLocked creates path exclusively, returning ErrorLocked if
+// another process has it
+func openLocked(path string) (*os.File, error) {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, ErrorLocked
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return nil, ErrorLocked
+	}
+	return f, err
+}
+
+// closeLocked releases the lock on f by removing it
+func closeLocked(f *os.File, path string) error {
+	err := f.Close()
+	if removeErr := os.Remove(path); err == nil {
+		err = removeErr
+	}
+	return err
+}
@@ -121,6 +121,33 @@ type TokenSource struct {
 	expiryTimer *time.Timer // signals whenever the token expires
 }
 
+// reReadToken reads the token from the config file on disk if it has
+// been changed by somebody else, eg another Fs or another rclone
+// using the same remote which has already refreshed it.
+//
+// It returns true if a new valid token was found.
+//
+// Call with the lock held
+func (ts *TokenSource) reReadToken() bool {
+	tokenString := config.FileGetFresh(ts.name, config.ConfigToken)
+	if tokenString == "" {
+		return false
+	}
+	newToken := new(oauth2.Token)
+	err := json.Unmarshal([]byte(tokenString), newToken)
+	if err != nil {
+		fs.Debugf(ts.name, "Failed to re-read token from config file: %v", err)
+		return false
+	}
+	if !newToken.Valid() || *newToken == *ts.token {
+		return false
+	}
+	fs.Debugf(ts.name, "Loaded fresh token from config file")
+	ts.token = newToken
+	ts.tokenSource = nil // invalidate since we changed the token
+	return true
+}
+
 // Token returns a token or an error.
 // Token must be safe for concurrent use by multiple goroutines.
 // The returned Token must not be modified.
@@ -130,6 +157,12 @@ func (ts *TokenSource) Token() (*oauth2.Token, error) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
+	// If the token has expired check to see whether it has
+	// already been refreshed before refreshing it ourselves
+	if !ts.token.Valid() && ts.reReadToken() && ts.expiryTimer != nil {
+		ts.expiryTimer.Reset(ts.timeToExpiry())
+	}
+
 	// Make a new token source if required
 	if ts.tokenSource == nil {
 		ts.tokenSource = ts.config.TokenSource(ts.ctx, ts.token)
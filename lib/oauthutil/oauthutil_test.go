@@ -0,0 +1,75 @@
+package oauthutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// writeTokenConfig writes a config file to path with a remote called
+// remote using token
+func writeTokenConfig(t *testing.T, path string, token *oauth2.Token) {
+	tokenBytes, err := json.Marshal(token)
+	require.NoError(t, err)
+	data := "[remote]\ntype = local\ntoken = " + string(tokenBytes) + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), 0600))
+}
+
+func TestReReadToken(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "rclone-oauthutil.conf")
+	require.NoError(t, err)
+	path := tempFile.Name()
+	require.NoError(t, tempFile.Close())
+	oldConfigPath := config.ConfigPath
+	config.ConfigPath = path
+	defer func() {
+		config.ConfigPath = oldConfigPath
+		require.NoError(t, os.Remove(path))
+		_ = os.Remove(path + ".lock")
+	}()
+
+	expired := &oauth2.Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Hour),
+	}
+	writeTokenConfig(t, path, expired)
+	config.LoadConfig()
+	ts := &TokenSource{name: "remote", token: expired}
+
+	// Token unchanged in the config file
+	assert.False(t, ts.reReadToken())
+
+	// Another rclone refreshes the token and saves it
+	fresh := &oauth2.Token{
+		AccessToken:  "fresh",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(time.Hour).Round(time.Second),
+	}
+	writeTokenConfig(t, path, fresh)
+	ts.tokenSource = oauth2.StaticTokenSource(expired)
+	assert.True(t, ts.reReadToken())
+	assert.Equal(t, "fresh", ts.token.AccessToken)
+	assert.True(t, fresh.Expiry.Equal(ts.token.Expiry))
+	assert.Nil(t, ts.tokenSource)
+
+	// Already have it
+	assert.False(t, ts.reReadToken())
+
+	// An expired token in the config file isn't used
+	ts.token = expired
+	writeTokenConfig(t, path, &oauth2.Token{AccessToken: "other", Expiry: time.Now().Add(-time.Minute)})
+	assert.False(t, ts.reReadToken())
+	assert.Equal(t, "expired", ts.token.AccessToken)
+
+	// A corrupt token is ignored
+	require.NoError(t, ioutil.WriteFile(path, []byte("[remote]\ntype = local\ntoken = potato\n"), 0600))
+	assert.False(t, ts.reReadToken())
+}
@@ -3,6 +3,7 @@ package pacer
 
 import (
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -24,6 +25,13 @@ type Pacer struct {
 	connTokens         chan struct{} // Connection tokens
 	calculatePace      func(bool)    // switchable pacing algorithm - call with mu held
 	consecutiveRetries int           // number of consecutive retries
+	burst              int           // number of calls allowed without pacing
+	retryAfter         time.Time     // don't make any calls before this time
+	name               string        // name used in logs and stats
+	retried            int64         // number of low level retries made
+	rateLimits         int64         // number of those retries which were rate limited
+	wasRateLimited     bool          // set if the last call was rate limited
+	shared             *Stats        // totals for all the pacers with this name - nil if not named
 }
 
 // Type is for selecting different pacing algorithms
@@ -59,8 +67,29 @@ const (
 	//
 	// See https://developers.google.com/drive/v2/web/handle-errors#exponential-backoff
 	GoogleDrivePacer
+
+	// AdaptivePacer adapts the pace of the calls to the rate
+	// limiting and errors the remote reports.
+	//
+	// When a call is retried, whether because the remote is rate
+	// limiting (the error satisfies fserrors.RateLimiter) or for
+	// any other reason such as a server error, the sleep time is
+	// doubled, never going above that set with SetMaxSleep.  This
+	// works even if the minimum sleep time is 0 so calls to the
+	// remote needn't be paced until it starts failing.  On success
+	// the sleep time decays according to the decay constant set
+	// with SetDecayConstant, never going below that set with
+	// SetMinSleep.
+	//
+	// Backends should mark their rate limiting errors with
+	// fserrors.RateLimitError so they are counted in the stats.
+	AdaptivePacer
 )
 
+// adaptiveStartSleep is the sleep time the AdaptivePacer starts
+// from when retrying if the minimum sleep time is 0
+const adaptiveStartSleep = 10 * time.Millisecond
+
 // Paced is a function which is called by the Call and CallNoRetry
 // methods.  It should return a boolean, true if it would like to be
 // retried, and an error.  This error may be returned or returned
@@ -76,6 +105,8 @@ func New() *Pacer {
 		attackConstant: 1,
 		retries:        fs.Config.LowLevelRetries,
 		pacer:          make(chan struct{}, 1),
+		burst:          1,
+		name:           "pacer",
 	}
 	p.sleepTime = p.minSleep
 	p.SetPacer(DefaultPacer)
@@ -139,6 +170,27 @@ func (p *Pacer) SetMaxConnections(n int) *Pacer {
 	return p
 }
 
+// SetBurst sets the number of calls which can be made in a burst
+// without waiting for the pacer.
+//
+// The average rate of calls is still limited by the sleep time, but
+// after a quiet period up to n calls can be made at once.
+//
+// Should not be changed once you have started calling the pacer.
+func (p *Pacer) SetBurst(n int) *Pacer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	p.burst = n
+	p.pacer = make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		p.pacer <- struct{}{}
+	}
+	return p
+}
+
 // SetDecayConstant sets the decay constant for the pacer
 //
 // This is the speed the time falls back to the minimum after errors
@@ -173,6 +225,76 @@ func (p *Pacer) SetRetries(retries int) *Pacer {
 	return p
 }
 
+// SetName sets the name of the pacer to that of the remote using it.
+//
+// This is used in the log messages and pacers with names have their
+// state shown in the stats.
+//
+// Should not be changed once you have started calling the pacer.
+func (p *Pacer) SetName(name string) *Pacer {
+	registryMu.Lock()
+	shared, ok := registry[name]
+	if !ok {
+		shared = &Stats{Name: name}
+		registry[name] = shared
+	}
+	registryMu.Unlock()
+	p.mu.Lock()
+	p.name = name
+	p.shared = shared
+	p.mu.Unlock()
+	return p
+}
+
+// Stats describes the state of a pacer
+type Stats struct {
+	Name        string        // name of the remote
+	SleepTime   time.Duration // current time to sleep between calls
+	Retries     int64         // number of low level retries made
+	RateLimited int64         // number of those retries which were rate limited
+}
+
+// Stats returns the current state of the pacer
+func (p *Pacer) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{
+		Name:        p.name,
+		SleepTime:   p.sleepTime,
+		Retries:     p.retried,
+		RateLimited: p.rateLimits,
+	}
+}
+
+// The registry holds the totals for each name rather than the pacers
+// themselves so pacers can be garbage collected when the Fs using
+// them is dropped.
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Stats{} // totals for the named pacers by name
+)
+
+// AllStats returns the Stats of the named pacers which have had to
+// retry calls, sorted by name.
+//
+// Pacers with the same name are combined, adding their retries
+// together and showing the sleep time of the last one to make a
+// call.
+func AllStats() (stats []Stats) {
+	registryMu.Lock()
+	for _, st := range registry {
+		if st.Retries == 0 {
+			continue
+		}
+		stats = append(stats, *st)
+	}
+	registryMu.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
 // SetPacer sets the pacing algorithm
 //
 // It will choose the default algorithm if an incorrect value is
@@ -185,6 +307,8 @@ func (p *Pacer) SetPacer(t Type) *Pacer {
 		p.calculatePace = p.acdPacer
 	case GoogleDrivePacer:
 		p.calculatePace = p.drivePacer
+	case AdaptivePacer:
+		p.calculatePace = p.adaptivePacer
 	default:
 		p.calculatePace = p.defaultPacer
 	}
@@ -207,13 +331,28 @@ func (p *Pacer) beginCall() {
 	}
 
 	p.mu.Lock()
-	// Restart the timer
+	// Restart the timer - with a burst each token is returned
+	// burst times slower to keep the average rate the same
+	sleepTime := p.sleepTime * time.Duration(p.burst)
+	// While retrying add some jitter so the callers which failed
+	// together don't all retry together - this keeps the average
+	// sleep the same
+	if p.consecutiveRetries > 0 && sleepTime > 1 {
+		sleepTime = sleepTime/2 + time.Duration(rand.Int63n(int64(sleepTime)))
+	}
 	go func(t time.Duration) {
 		// fs.Debugf(f, "New sleep for %v at %v", t, time.Now())
 		time.Sleep(t)
 		p.pacer <- struct{}{}
-	}(p.sleepTime)
+	}(sleepTime)
+	wait := p.retryAfter.Sub(time.Now())
 	p.mu.Unlock()
+
+	// Wait if the server asked us to with Retry-After
+	if wait > 0 {
+		fs.Debugf(p.name, "Waiting %v before retrying as requested by server", wait)
+		time.Sleep(wait)
+	}
 }
 
 // exponentialImplementation implements a exponentialImplementation up
@@ -237,7 +376,7 @@ func (p *Pacer) defaultPacer(retry bool) {
 			p.sleepTime = p.maxSleep
 		}
 		if p.sleepTime != oldSleepTime {
-			fs.Debugf("pacer", "Rate limited, increasing sleep to %v", p.sleepTime)
+			fs.Debugf(p.name, "Rate limited, increasing sleep to %v", p.sleepTime)
 		}
 	} else {
 		p.sleepTime = (p.sleepTime<<p.decayConstant - p.sleepTime) >> p.decayConstant
@@ -245,7 +384,7 @@ func (p *Pacer) defaultPacer(retry bool) {
 			p.sleepTime = p.minSleep
 		}
 		if p.sleepTime != oldSleepTime {
-			fs.Debugf("pacer", "Reducing sleep to %v", p.sleepTime)
+			fs.Debugf(p.name, "Reducing sleep to %v", p.sleepTime)
 		}
 	}
 }
@@ -264,7 +403,7 @@ func (p *Pacer) acdPacer(retry bool) {
 	if consecutiveRetries == 0 {
 		if p.sleepTime != p.minSleep {
 			p.sleepTime = p.minSleep
-			fs.Debugf("pacer", "Resetting sleep to minimum %v on success", p.sleepTime)
+			fs.Debugf(p.name, "Resetting sleep to minimum %v on success", p.sleepTime)
 		}
 	} else {
 		if consecutiveRetries > 9 {
@@ -278,7 +417,7 @@ func (p *Pacer) acdPacer(retry bool) {
 		if p.sleepTime < p.minSleep {
 			p.sleepTime = p.minSleep
 		}
-		fs.Debugf("pacer", "Rate limited, sleeping for %v (%d consecutive low level retries)", p.sleepTime, p.consecutiveRetries)
+		fs.Debugf(p.name, "Rate limited, sleeping for %v (%d consecutive low level retries)", p.sleepTime, p.consecutiveRetries)
 	}
 }
 
@@ -296,7 +435,7 @@ func (p *Pacer) drivePacer(retry bool) {
 	if consecutiveRetries == 0 {
 		if p.sleepTime != p.minSleep {
 			p.sleepTime = p.minSleep
-			fs.Debugf("pacer", "Resetting sleep to minimum %v on success", p.sleepTime)
+			fs.Debugf(p.name, "Resetting sleep to minimum %v on success", p.sleepTime)
 		}
 	} else {
 		if consecutiveRetries > 5 {
@@ -305,25 +444,83 @@ func (p *Pacer) drivePacer(retry bool) {
 		// consecutiveRetries starts at 1 so go from 1,2,3,4,5,5 => 1,2,4,8,16,16
 		// maxSleep is 2**(consecutiveRetries-1) seconds + random milliseconds
 		p.sleepTime = time.Second<<uint(consecutiveRetries-1) + time.Duration(rand.Int63n(int64(time.Second)))
-		fs.Debugf("pacer", "Rate limited, sleeping for %v (%d consecutive low level retries)", p.sleepTime, p.consecutiveRetries)
+		fs.Debugf(p.name, "Rate limited, sleeping for %v (%d consecutive low level retries)", p.sleepTime, p.consecutiveRetries)
 	}
 }
 
-// endCall implements the pacing algorithm
+// adaptivePacer implements a pacer which backs off exponentially on
+// retries even when the minimum sleep is 0
+//
+// See the description for AdaptivePacer
 //
 // This should calculate a new sleepTime.  It takes a boolean as to
 // whether the operation should be retried or not.
-func (p *Pacer) endCall(retry bool) {
+//
+// Call with p.mu held
+func (p *Pacer) adaptivePacer(retry bool) {
+	oldSleepTime := p.sleepTime
+	switch {
+	case retry:
+		p.sleepTime *= 2
+		if p.sleepTime < adaptiveStartSleep {
+			p.sleepTime = adaptiveStartSleep
+		}
+		if p.sleepTime > p.maxSleep {
+			p.sleepTime = p.maxSleep
+		}
+		if p.sleepTime != oldSleepTime {
+			if p.wasRateLimited {
+				fs.Debugf(p.name, "Rate limited, increasing sleep to %v", p.sleepTime)
+			} else {
+				fs.Debugf(p.name, "Retrying, increasing sleep to %v", p.sleepTime)
+			}
+		}
+	default:
+		p.sleepTime = (p.sleepTime<<p.decayConstant - p.sleepTime) >> p.decayConstant
+		if p.sleepTime < p.minSleep {
+			p.sleepTime = p.minSleep
+		}
+		if p.sleepTime != oldSleepTime {
+			fs.Debugf(p.name, "Reducing sleep to %v", p.sleepTime)
+		}
+	}
+}
+
+// endCall implements the pacing algorithm
+//
+// This should calculate a new sleepTime.  It takes a boolean as to
+// whether the operation should be retried or not and the error
+// returned which may say when it should be retried.
+func (p *Pacer) endCall(retry bool, err error) {
 	if p.maxConnections > 0 {
 		p.connTokens <- struct{}{}
 	}
 	p.mu.Lock()
+	p.wasRateLimited = retry && fserrors.IsRateLimitError(err)
 	if retry {
 		p.consecutiveRetries++
+		p.retried++
+		if p.wasRateLimited {
+			p.rateLimits++
+		}
+		if retryAfter := fserrors.RetryAfterErrorTime(err); retryAfter.After(p.retryAfter) {
+			p.retryAfter = retryAfter
+		}
 	} else {
 		p.consecutiveRetries = 0
 	}
 	p.calculatePace(retry)
+	if p.shared != nil {
+		registryMu.Lock()
+		if retry {
+			p.shared.Retries++
+			if p.wasRateLimited {
+				p.shared.RateLimited++
+			}
+		}
+		p.shared.SleepTime = p.sleepTime
+		registryMu.Unlock()
+	}
 	p.mu.Unlock()
 }
 
@@ -333,11 +530,11 @@ func (p *Pacer) call(fn Paced, retries int) (err error) {
 	for i := 1; i <= retries; i++ {
 		p.beginCall()
 		retry, err = fn()
-		p.endCall(retry)
+		p.endCall(retry, err)
 		if !retry {
 			break
 		}
-		fs.Debugf("pacer", "low level retry %d/%d (error %v)", i, retries, err)
+		fs.Debugf(p.name, "low level retry %d/%d (error %v)", i, retries, err)
 	}
 	if retry {
 		err = fserrors.RetryError(err)
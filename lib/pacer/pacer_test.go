@@ -2,6 +2,7 @@ package pacer
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -172,6 +173,10 @@ func TestSetPacer(t *testing.T) {
 	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.drivePacer) {
 		t.Errorf("calculatePace is not drivePacer")
 	}
+	p.SetPacer(AdaptivePacer)
+	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.adaptivePacer) {
+		t.Errorf("calculatePace is not adaptivePacer")
+	}
 	p.SetPacer(DefaultPacer)
 	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.defaultPacer) {
 		t.Errorf("calculatePace is not defaultPacer")
@@ -340,11 +345,81 @@ func TestGoogleDrivePacer(t *testing.T) {
 	}
 }
 
+func TestAdaptivePacer(t *testing.T) {
+	p := New().SetMinSleep(0).SetPacer(AdaptivePacer).SetMaxSleep(time.Second).SetDecayConstant(2)
+	for _, test := range []struct {
+		in          time.Duration
+		retry       bool
+		rateLimited bool
+		want        time.Duration
+	}{
+		{0, true, true, adaptiveStartSleep},
+		{time.Millisecond, true, true, adaptiveStartSleep},
+		{100 * time.Millisecond, true, true, 200 * time.Millisecond},
+		{(3 * time.Second) / 4, true, true, time.Second},
+		{0, true, false, adaptiveStartSleep},
+		{100 * time.Millisecond, true, false, 200 * time.Millisecond},
+		{(3 * time.Second) / 4, true, false, time.Second},
+		{time.Second, false, false, 750 * time.Millisecond},
+		{time.Nanosecond, false, false, 0},
+	} {
+		p.sleepTime = test.in
+		p.wasRateLimited = test.rateLimited
+		p.adaptivePacer(test.retry)
+		got := p.sleepTime
+		if got != test.want {
+			t.Errorf("%+v: bad sleep want %v got %v", test, test.want, got)
+		}
+	}
+}
+
+func TestEndCallRateLimited(t *testing.T) {
+	p := New().SetMaxConnections(0).SetMinSleep(0).SetPacer(AdaptivePacer)
+	p.endCall(true, errors.New("potato"))
+	p.endCall(true, fserrors.RateLimitError(errors.New("potato")))
+	p.endCall(false, nil)
+	stats := p.Stats()
+	if stats.Retries != 2 {
+		t.Errorf("want 2 retries got %d", stats.Retries)
+	}
+	if stats.RateLimited != 1 {
+		t.Errorf("want 1 rate limited got %d", stats.RateLimited)
+	}
+	if p.wasRateLimited {
+		t.Errorf("wasRateLimited not reset")
+	}
+}
+
+func TestAllStats(t *testing.T) {
+	p1 := New().SetMaxConnections(0).SetName("TestAllStats1")
+	p2 := New().SetMaxConnections(0).SetName("TestAllStats2")
+	p3 := New().SetMaxConnections(0).SetName("TestAllStats2")
+	New().SetMaxConnections(0).SetName("TestAllStats3") // no retries so not shown
+	p1.endCall(true, nil)
+	p2.endCall(true, fserrors.RateLimitError(nil))
+	p3.SetMaxSleep(time.Minute).SetAttackConstant(0)
+	p3.endCall(true, nil)
+
+	var got []Stats
+	for _, st := range AllStats() {
+		if strings.HasPrefix(st.Name, "TestAllStats") {
+			got = append(got, st)
+		}
+	}
+	want := []Stats{
+		{Name: "TestAllStats1", SleepTime: p1.sleepTime, Retries: 1},
+		{Name: "TestAllStats2", SleepTime: time.Minute, Retries: 2, RateLimited: 1},
+	}
+	if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", want) {
+		t.Errorf("AllStats want %+v got %+v", want, got)
+	}
+}
+
 func TestEndCall(t *testing.T) {
 	p := New().SetMaxConnections(5)
 	emptyTokens(p)
 	p.consecutiveRetries = 1
-	p.endCall(true)
+	p.endCall(true, nil)
 	if len(p.connTokens) != 1 {
 		t.Errorf("Expecting 1 token")
 	}
@@ -353,11 +428,58 @@ func TestEndCall(t *testing.T) {
 	}
 }
 
+func TestEndCallRetryAfter(t *testing.T) {
+	p := New().SetMaxConnections(0)
+	retryAfter := time.Now().Add(time.Minute)
+	err := fserrors.RetryAfterError(errors.New("potato"), time.Minute)
+	p.endCall(true, err)
+	if p.retryAfter.Before(retryAfter) {
+		t.Errorf("retryAfter not set: %v", p.retryAfter)
+	}
+	// an earlier time shouldn't override it
+	p.endCall(true, fserrors.RetryAfterError(errors.New("potato"), time.Second))
+	if p.retryAfter.Before(retryAfter) {
+		t.Errorf("retryAfter reduced: %v", p.retryAfter)
+	}
+}
+
+func TestBeginCallRetryAfter(t *testing.T) {
+	p := New().SetMinSleep(time.Millisecond)
+	p.retryAfter = time.Now().Add(50 * time.Millisecond)
+	start := time.Now()
+	p.beginCall()
+	if dt := time.Since(start); dt < 40*time.Millisecond {
+		t.Errorf("beginCall didn't wait for retryAfter: %v", dt)
+	}
+	p.endCall(false, nil)
+}
+
+func TestSetBurst(t *testing.T) {
+	p := New().SetMaxConnections(0).SetMinSleep(time.Second).SetBurst(3)
+	if p.burst != 3 {
+		t.Errorf("burst want 3 got %d", p.burst)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		p.beginCall()
+		p.endCall(false, nil)
+	}
+	if dt := time.Since(start); dt > 500*time.Millisecond {
+		t.Errorf("burst calls were paced: %v", dt)
+	}
+	if len(p.pacer) != 0 {
+		t.Errorf("expecting no pacer tokens left, got %d", len(p.pacer))
+	}
+	if New().SetBurst(0).burst != 1 {
+		t.Errorf("burst should be at least 1")
+	}
+}
+
 func TestEndCallZeroConnections(t *testing.T) {
 	p := New().SetMaxConnections(0)
 	emptyTokens(p)
 	p.consecutiveRetries = 1
-	p.endCall(false)
+	p.endCall(false, nil)
 	if len(p.connTokens) != 0 {
 		t.Errorf("Expecting 0 token")
 	}
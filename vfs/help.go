@@ -11,6 +11,13 @@ backend. Changes made locally in the mount may appear immediately or
 invalidate the cache. However, changes done on the remote will only
 be picked up once the cache expires.
 
+If the backend supports it (currently Amazon Drive, Dropbox and
+Google Drive) then rclone also polls the remote for changes every
+` + "`--poll-interval`" + ` and invalidates the cache for any directories
+which have changed, so remote changes are picked up without waiting
+for the cache to expire.  Set ` + "`--poll-interval 0`" + ` to disable
+this.
+
 Alternatively, you can send a ` + "`SIGHUP`" + ` signal to rclone for
 it to flush all directory caches, regardless of how old they are.
 Assuming only one rclone instance is running, you can reset the cache